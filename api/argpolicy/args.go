@@ -0,0 +1,304 @@
+package argpolicy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// option is an ffmpeg option and its value, or an output file (name "")
+type option struct {
+	index    int    // Position in the argument list
+	name     string // Without the dash, "/" prefix or stream specifier, e.g. "c" for -c:v
+	value    string
+	hasValue bool
+	fromFile bool // -/name: the value is a file holding the actual value
+}
+
+// scan splits an argument list into options and output files. Options take
+// one value unless ffmpeg knows them as flags; arguments that aren't options
+// or values are output files.
+func (p *Policy) scan(args []string) []option {
+	var opts []option
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) < 2 || arg[0] != '-' {
+			opts = append(opts, option{index: i, value: arg, hasValue: true})
+			continue
+		}
+		opt := option{index: i, name: arg[1:]}
+		if strings.HasPrefix(opt.name, "/") {
+			opt.name, opt.fromFile = opt.name[1:], true
+		}
+		opt.name, _, _ = strings.Cut(opt.name, ":")
+		if p.takesValue(opt.name) && i+1 < len(args) {
+			i++
+			opt.value, opt.hasValue = args[i], true
+		}
+		opts = append(opts, opt)
+	}
+	return opts
+}
+
+func (p *Policy) takesValue(name string) bool {
+	if p.isFlag(name) {
+		return false
+	}
+	// Any boolean option can be turned off with a "no" prefix
+	return !(strings.HasPrefix(name, "no") && p.isFlag(name[2:]))
+}
+
+func (p *Policy) isFlag(name string) bool {
+	takes, ok := p.Options[name]
+	return flags[name] || (ok && !takes)
+}
+
+// known reports whether ffmpeg has an option
+func (p *Policy) known(name string) bool {
+	if p.Options == nil || name == "i" || name == "f" || flags[name] {
+		return true
+	}
+	if _, ok := p.Options[name]; ok {
+		return true
+	}
+	return strings.HasPrefix(name, "no") && p.isFlag(name[2:])
+}
+
+// ParseHelp reads the options ffmpeg knows from the output of ffmpeg -h full:
+// its own options, which take a value if one is shown after the name, and
+// the AVOptions of codecs, formats and libraries, which always take one.
+func ParseHelp(help string) map[string]bool {
+	options := make(map[string]bool)
+	for _, line := range strings.Split(help, "\n") {
+		switch {
+		case strings.HasPrefix(line, "-"):
+			// e.g. "-y       overwrite output files", "-c[:<stream_spec>] <codec>  select encoder"
+			field, rest, _ := strings.Cut(line, " ")
+			name, _, _ := strings.Cut(field[1:], "[")
+			if name != "" {
+				options[name] = rest != "" && rest[0] != ' '
+			}
+		case strings.HasPrefix(line, "  -"):
+			// e.g. "  -crf     <float>   E..V....... Select the quality"
+			name, _, _ := strings.Cut(strings.TrimSpace(line)[1:], " ")
+			if _, ok := options[name]; !ok && name != "" {
+				options[name] = true
+			}
+		}
+	}
+	return options
+}
+
+// flags are ffmpeg's options that take no value
+var flags = setOf(
+	// Boolean options
+	"y", "n", "stdin", "hide_banner", "stats", "report", "benchmark", "benchmark_all", "debug_ts", "xerror",
+	"copyts", "start_at_zero", "shortest", "accurate_seek", "re", "dump", "hex", "ignore_unknown", "copy_unknown",
+	"recast_media", "vn", "an", "sn", "dn", "autorotate", "autoscale", "fix_sub_duration",
+	"fix_sub_duration_heartbeat", "find_stream_info", "bitexact", "vstats", "qphist", "psnr", "ignore_chapters",
+	"print_graphs", "seek_timestamp", "intra", "copyinkf",
+	// Information options, after which ffmpeg exits
+	"L", "h", "?", "help", "-help", "version", "buildconf", "formats", "muxers", "demuxers", "devices", "codecs",
+	"decoders", "encoders", "bsfs", "protocols", "filters", "pix_fmts", "layouts", "sample_fmts", "dispositions",
+	"colors", "hwaccels",
+)
+
+// graphOptions take a filtergraph
+var graphOptions = setOf("vf", "af", "filter", "filter_complex", "lavfi")
+
+// scriptOptions take a file holding a filtergraph
+var scriptOptions = setOf("filter_script", "filter_complex_script")
+
+// pathOptions take a file that ffmpeg reads or writes
+var pathOptions = setOf(
+	"attach", "passlogfile", "vstats_file", "sdp_file", "stats_enc_pre", "stats_enc_post", "stats_mux_pre",
+	"print_graphs_file", "hls_segment_filename", "hls_fmp4_init_filename", "hls_key_info_file", "master_pl_name",
+	"segment_list", "init_seg_name", "media_seg_name",
+)
+
+// deviceOptions take a device path, which isn't a file in the job directory
+var deviceOptions = setOf("hwaccel_device", "init_hw_device", "filter_hw_device", "qsv_device", "vaapi_device")
+
+// knownProtocols are ffmpeg's protocol names, used to recognize URLs given to
+// options the policy doesn't know
+var knownProtocols = setOf(
+	"android_content", "async", "bluray", "cache", "concat", "concatf", "crypto", "data", "dtls", "fd",
+	"ffrtmpcrypt", "ffrtmphttp", "file", "ftp", "gopher", "gophers", "hls", "http", "httpproxy", "https",
+	"icecast", "ipfs", "ipns", "librist", "libsmbclient", "libsrt", "libssh", "md5", "mmsh", "mmst", "pipe",
+	"prompeg", "rist", "rtmp", "rtmpe", "rtmps", "rtmpt", "rtmpte", "rtmpts", "rtp", "sctp", "sftp", "smb", "srt",
+	"srtp", "subfile", "tcp", "tee", "tls", "udp", "udplite", "unix", "zmq",
+)
+
+// fileFilterOptions lists, by filter, the options that name a file the filter
+// opens; "" stands for the first unnamed option
+var fileFilterOptions = map[string][]string{
+	"subtitles":        {"", "filename", "f", "fontsdir"},
+	"ass":              {"", "filename", "f", "fontsdir"},
+	"drawtext":         {"fontfile", "textfile"},
+	"lut1d":            {"", "file"},
+	"lut3d":            {"", "file"},
+	"curves":           {"psfile", "plot"},
+	"psnr":             {"", "stats_file", "f"},
+	"ssim":             {"", "stats_file", "f"},
+	"vmafmotion":       {"stats_file"},
+	"libvmaf":          {"model_path", "log_path"},
+	"metadata":         {"file"},
+	"ametadata":        {"file"},
+	"signature":        {"filename"},
+	"vidstabdetect":    {"result"},
+	"vidstabtransform": {"input"},
+	"arnndn":           {"", "model", "m"},
+	"sr":               {"model"},
+	"dnn_processing":   {"model"},
+	"dnn_classify":     {"model", "labels"},
+	"dnn_detect":       {"model", "labels"},
+	"whisper":          {"model", "vad_model", "destination"},
+	"sofalizer":        {"sofa"},
+	"libplacebo":       {"custom_shader_path"},
+}
+
+// checkGraph checks the filters in a filtergraph: denied filters are
+// rejected, and files other filters open must be in the scope
+func (p *Policy) checkGraph(graph string, scope Scope) error {
+	for _, f := range parseGraph(graph) {
+		if slices.Contains(p.DeniedFilters, f.name) {
+			return fmt.Errorf("%w: filter %s", ErrDenied, f.name)
+		}
+		fileOpts := fileFilterOptions[f.name]
+		for i, arg := range f.args {
+			for _, opt := range fileOpts {
+				if arg.key == opt && (opt != "" || i == 0) {
+					if err := scope.checkPath(arg.value); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type filter struct {
+	name string
+	args []filterArg
+}
+
+type filterArg struct {
+	key   string // "" for unnamed options
+	value string
+}
+
+// parseGraph splits a filtergraph into filters, unescaping names and options
+// like ffmpeg does. Link labels are skipped.
+func parseGraph(graph string) []filter {
+	var filters []filter
+	s := graph
+	for s != "" {
+		s = skipLabels(s)
+		var name, args string
+		name, s = token(s, "=,;[")
+		if strings.HasPrefix(s, "=") {
+			args, s = token(s[1:], "[],;")
+		}
+		s = skipLabels(s)
+		if s != "" {
+			s = s[1:] // The , or ; before the next filter, or a stray character
+		}
+
+		name, _, _ = strings.Cut(name, "@") // Instance names, e.g. scale@main
+		if name != "" {
+			filters = append(filters, filter{name: name, args: parseFilterArgs(args)})
+		}
+	}
+	return filters
+}
+
+// parseFilterArgs splits a filter's options the way av_opt_set_from_string does
+func parseFilterArgs(s string) []filterArg {
+	var args []filterArg
+	for s != "" {
+		var arg filterArg
+		if key, rest, ok := filterKey(s); ok {
+			arg.key = key
+			s = rest
+		}
+		arg.value, s = token(s, ":")
+		args = append(args, arg)
+		if s != "" {
+			s = s[1:]
+		}
+	}
+	return args
+}
+
+// filterKey returns the option name at the start of s and what follows its "="
+func filterKey(s string) (string, string, bool) {
+	s = strings.TrimLeft(s, " \n\t\r")
+	n := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_/.", r))
+	})
+	if n <= 0 {
+		return "", "", false
+	}
+	rest := strings.TrimLeft(s[n:], " \n\t\r")
+	if !strings.HasPrefix(rest, "=") {
+		return "", "", false
+	}
+	return s[:n], rest[1:], true
+}
+
+// token reads up to the first unquoted, unescaped character in term, like
+// av_get_token: backslashes escape the next character, single quotes quote
+// everything up to the next one, and surrounding whitespace is dropped.
+func token(s, term string) (string, string) {
+	s = strings.TrimLeft(s, " \n\t\r")
+	var b strings.Builder
+	end := 0 // Length of b without trailing unquoted whitespace
+	i := 0
+chars:
+	for ; i < len(s) && !strings.ContainsRune(term, rune(s[i])); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+			end = b.Len()
+		case c == '\'':
+			for i++; i < len(s) && s[i] != '\''; i++ {
+				b.WriteByte(s[i])
+			}
+			end = b.Len()
+			if i == len(s) {
+				break chars // Unterminated quote
+			}
+		default:
+			b.WriteByte(c)
+			if !strings.ContainsRune(" \n\t\r", rune(c)) {
+				end = b.Len()
+			}
+		}
+	}
+	return b.String()[:end], s[i:]
+}
+
+// skipLabels drops link labels such as [0:v] from the start of s
+func skipLabels(s string) string {
+	for {
+		s = strings.TrimLeft(s, " \n\t\r")
+		if !strings.HasPrefix(s, "[") {
+			return s
+		}
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return ""
+		}
+		s = s[end+1:]
+	}
+}
+
+func setOf(names ...string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
@@ -0,0 +1,165 @@
+package argpolicy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// help is an excerpt of ffmpeg -h full in the formats of older and newer releases
+const help = `Main options:
+-y                  overwrite output files
+-copyinkf           copy initial non-keyframes
+-c codec            codec name
+-ss time_off        set the start time offset
+-map[:<stream_spec>] <map>  set input stream mapping
+-frames[:<stream_spec>] <count>  set the number of frames to output
+-vframes number     set the number of video frames to output
+-vf filter_graph    set video filters
+-filter_complex graph_description  create a complex filtergraph
+-filter_script filename  deprecated, use -/filter
+-filter_threads     number of non-complex filter threads
+-threads <count>    set the number of threads
+-headers <string>   set custom HTTP headers
+-attach filename    add an attachment to the output file
+-stats              print progress report during encoding
+
+AVCodecContext AVOptions:
+  -b                 <int64>      E..VA...... set bitrate (in bits/s)
+  -flags             <flags>      ED.VAS..... (default 0)
+     unaligned                    .D.V....... allow decoders to produce unaligned output
+  -crf               <float>      E..V....... Select the quality for constant quality mode
+  -stats             <string>     E..V....... an option of the same name as a main option
+`
+
+func newPolicy(t *testing.T, options map[string]bool) *Policy {
+	t.Helper()
+	p, err := New(DefaultDeniedOptions, DefaultDeniedFilters, DefaultDeniedFormats, DefaultProtocols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Options = options
+	return p
+}
+
+func TestParseHelp(t *testing.T) {
+	options := ParseHelp(help)
+	want := map[string]bool{
+		"y": false, "copyinkf": false, "c": true, "ss": true, "map": true, "frames": true, "vframes": true,
+		"vf": true, "filter_complex": true, "filter_script": true, "filter_threads": false, "threads": true,
+		"headers": true, "attach": true, "stats": false, "b": true, "flags": true, "crf": true,
+	}
+	for name, takesValue := range want {
+		got, ok := options[name]
+		if !ok {
+			t.Errorf("option -%s missing", name)
+		} else if got != takesValue {
+			t.Errorf("option -%s takes value = %v, want %v", name, got, takesValue)
+		}
+	}
+	if _, ok := options["unaligned"]; ok {
+		t.Error("constant unaligned parsed as an option")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "graph.txt"), []byte("movie=/etc/passwd[v];[0][v]overlay"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "safe.txt"), []byte("scale=640:-1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	in := filepath.Join(dir, "in.mp4")
+	out := filepath.Join(dir, "out.mp4")
+	stream := "http://127.0.0.1:4000/abc/in_1"
+
+	tests := []struct {
+		name    string
+		args    []string
+		dir     string // Scope.Dir; "-" for none
+		options map[string]bool
+		denied  bool
+	}{
+		{"thumbnail", []string{"-ss", "00:00:05", "-i", in, "-vframes", "1", out}, dir, nil, false},
+		{"relative files", []string{"-i", "in.mp4", "-c:v", "libx264", "sub/out.mp4"}, dir, nil, false},
+		{"codec option with colon", []string{"-i", in, "-headers", "Authorization: x", out}, dir, nil, false},
+		{"streamed input", []string{"-i", stream, out}, dir, nil, false},
+		{"placeholders before expansion", []string{"-i", "{{in_1}}", "{{out_1}}"}, "-", nil, false},
+		{"filter with file in job dir", []string{"-i", in, "-vf", "subtitles=subs.srt", out}, dir, nil, false},
+		{"filter script in job dir", []string{"-i", in, "-filter_script", "safe.txt", out}, dir, nil, false},
+		{"known options", []string{"-y", "-i", in, "-crf", "23", "-copyinkf", out}, dir, ParseHelp(help), false},
+		{"no prefix flag", []string{"-nostats", "-i", in, out}, dir, ParseHelp(help), false},
+
+		// Values ffmpeg reads as outputs when the policy takes them for option values
+		{"flag before parent path", []string{"-i", in, "-c", "copy", "-copyinkf", "../../../etc/cron.d/x"}, dir, nil, true},
+		{"flag before parent path with help", []string{"-i", in, "-copyinkf", "../../../etc/cron.d/x"}, dir, ParseHelp(help), true},
+		{"unknown flag before parent path", []string{"-i", in, "-someflag", "../x"}, dir, nil, true},
+		{"unknown flag before absolute path", []string{"-i", in, "-someflag", "/etc/cron.d/x"}, dir, nil, true},
+		{"unknown option", []string{"-i", in, "-someflag", "x", out}, dir, ParseHelp(help), true},
+
+		{"output outside job dir", []string{"-i", in, "/tmp/out.mp4"}, dir, nil, true},
+		{"output in parent", []string{"-i", in, "../out.mp4"}, dir, nil, true},
+		{"input outside job dir", []string{"-i", "/etc/passwd", out}, dir, nil, true},
+		{"file protocol outside", []string{"-i", "file:/etc/passwd", out}, dir, nil, true},
+		{"nested protocol", []string{"-i", "crypto+file:../in.mp4", out}, dir, nil, true},
+		{"denied protocol", []string{"-i", "http://169.254.169.254/latest", out}, dir, nil, true},
+		{"concat part outside", []string{"-i", "concat:in.mp4|/etc/passwd", out}, dir, nil, true},
+		{"subfile outside", []string{"-i", "subfile,,start,0,end,0,,:/etc/passwd", out}, dir, nil, true},
+		{"denied option", []string{"-dump_attachment:t", "x", "-i", in}, dir, nil, true},
+		{"denied format", []string{"-i", in, "-f", "tee", "[f=mp4]a.mp4|[f=mp4]b.mp4"}, dir, nil, true},
+		{"denied filter", []string{"-i", in, "-vf", "movie=/etc/passwd", out}, dir, nil, true},
+		{"denied filter with instance name", []string{"-i", in, "-filter_complex", "[0]null[a];sendcmd@x=f=c.txt", out}, dir, nil, true},
+		{"lavfi input", []string{"-f", "lavfi", "-i", "amovie=/etc/passwd", out}, dir, nil, true},
+		{"filter file outside", []string{"-i", in, "-vf", "subtitles=/etc/passwd", out}, dir, nil, true},
+		{"quoted filter file outside", []string{"-i", in, "-vf", "drawtext=fontfile='/etc/pass'wd:text=x", out}, dir, nil, true},
+		{"filter script content", []string{"-i", in, "-filter_script", "graph.txt", out}, dir, nil, true},
+		{"option from file content", []string{"-i", in, "-/filter_complex", "graph.txt", out}, dir, nil, true},
+		{"option from file outside", []string{"-i", in, "-/vf", "/etc/passwd", out}, dir, nil, true},
+		{"path option outside", []string{"-i", in, "-attach", "/etc/passwd", out}, dir, nil, true},
+		{"absolute path before expansion", []string{"-i", "/etc/passwd", "{{out_1}}"}, "-", nil, true},
+		{"placeholder parent before expansion", []string{"-i", "{{in_1}}", "{{out_1}}/../../x"}, "-", nil, true},
+		{"stream url as output", []string{"-i", in, "-f", "mp4", stream}, dir, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPolicy(t, tt.options)
+			scope := Scope{Dir: tt.dir, Streams: []string{stream}}
+			if tt.dir == "-" {
+				scope.Dir = ""
+			}
+			err := p.Check(tt.args, scope)
+			if tt.denied && !errors.Is(err, ErrDenied) {
+				t.Errorf("Check(%q) = %v, want denied", tt.args, err)
+			}
+			if !tt.denied && err != nil {
+				t.Errorf("Check(%q) = %v, want nil", tt.args, err)
+			}
+		})
+	}
+}
+
+func TestRestrict(t *testing.T) {
+	p := newPolicy(t, nil)
+	stream := "http://127.0.0.1:4000/abc/in_1"
+	got := p.Restrict([]string{"-i", "in.mp4", "-i", stream, "-/i", "list.txt", "out.mp4"}, Scope{Streams: []string{stream}})
+	want := []string{
+		"-protocol_whitelist", "file,crypto,data", "-i", "in.mp4", "-i", stream,
+		"-protocol_whitelist", "file,crypto,data", "-/i", "list.txt", "out.mp4",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Restrict = %q, want %q", got, want)
+	}
+}
+
+func TestLimitThreads(t *testing.T) {
+	p := newPolicy(t, nil)
+	got := strings.Join(p.LimitThreads([]string{"-threads", "16", "-i", "in.mp4", "-threads", "8", "out.mp4"}, 2), " ")
+	want := "-threads 16 -threads 2 -i in.mp4 -threads 8 -threads 2 -filter_threads 2 -filter_complex_threads 2 out.mp4"
+	if got != want {
+		t.Errorf("LimitThreads = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"ffmpeg-api/oas"
+	"ffmpeg-api/urlmatch"
+
+	"github.com/go-faster/jx"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	TypeWebhookDeliver = "webhook:deliver"
+	webhookQueue       = "webhooks"
+)
+
+// errDeadLetterGone is returned when another request replayed or deleted a dead letter first
+var errDeadLetterGone = errors.New("dead letter was replayed or deleted by another request")
+
+// deadLetterFilter selects archived webhook deliveries
+type deadLetterFilter struct {
+	CommandID string
+	Endpoint  *url.URL // Deliveries to this URL or below it (see urlmatch.Under)
+	Since     time.Time
+	Until     time.Time
+}
+
+// newDeadLetterFilter creates a filter, checking the endpoint is a valid base URL
+func newDeadLetterFilter(commandID, endpoint string, since, until time.Time) (deadLetterFilter, error) {
+	f := deadLetterFilter{CommandID: commandID, Since: since, Until: until}
+	if endpoint != "" {
+		u, err := urlmatch.Parse(endpoint)
+		if err != nil {
+			return f, fmt.Errorf("endpoint: %w", err)
+		}
+		f.Endpoint = u
+	}
+	return f, nil
+}
+
+// webhookTaskPayload holds the fields of a webhook task the API filters on.
+// The full payload is kept as raw JSON so replays don't drop anything.
+type webhookTaskPayload struct {
+	URL       string         `json:"url"`
+	CommandID string         `json:"command_id"`
+	Status    string         `json:"status"`
+	Body      map[string]any `json:"body"`
+}
+
+func (f deadLetterFilter) empty() bool {
+	return f.CommandID == "" && f.Endpoint == nil && f.Since.IsZero() && f.Until.IsZero()
+}
+
+func (f deadLetterFilter) matches(t *asynq.TaskInfo, p *webhookTaskPayload) bool {
+	if f.CommandID != "" && p.CommandID != f.CommandID {
+		return false
+	}
+	if f.Endpoint != nil {
+		u, err := url.Parse(p.URL)
+		if err != nil || !urlmatch.Under(f.Endpoint, u) {
+			return false
+		}
+	}
+	if !f.Since.IsZero() && t.LastFailedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !t.LastFailedAt.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// ListDeadLetters lists archived webhook deliveries matching the query filters
+func (h *Handler) ListDeadLetters(ctx context.Context, params oas.ListDeadLettersParams) (oas.ListDeadLettersRes, error) {
+	filter, err := newDeadLetterFilter(params.CommandID.Or(""), params.Endpoint.Or(""), params.Since.Or(time.Time{}), params.Until.Or(time.Time{}))
+	if err != nil {
+		return &oas.ListDeadLettersBadRequest{Error: err.Error()}, nil
+	}
+
+	tasks, err := findDeadLetters(filter, params.Limit.Or(100))
+	if err != nil {
+		return &oas.ListDeadLettersInternalServerError{Error: err.Error()}, nil
+	}
+
+	resp := &oas.DeadLetterListResponse{
+		DeadLetters: make([]oas.DeadLetter, 0, len(tasks)),
+		Total:       len(tasks),
+	}
+	for _, t := range tasks {
+		resp.DeadLetters = append(resp.DeadLetters, taskToDeadLetter(t, false))
+	}
+	return resp, nil
+}
+
+// GetDeadLetter returns a single archived webhook delivery with its payload
+func (h *Handler) GetDeadLetter(ctx context.Context, params oas.GetDeadLetterParams) (oas.GetDeadLetterRes, error) {
+	info, err := getDeadLetterTask(params.TaskID)
+	if err != nil {
+		return &oas.ErrorResponse{Error: err.Error()}, nil
+	}
+
+	dl := taskToDeadLetter(info, true)
+	return &dl, nil
+}
+
+// DeleteDeadLetter permanently removes an archived webhook delivery
+func (h *Handler) DeleteDeadLetter(ctx context.Context, params oas.DeleteDeadLetterParams) (oas.DeleteDeadLetterRes, error) {
+	if _, err := getDeadLetterTask(params.TaskID); err != nil {
+		return &oas.ErrorResponse{Error: err.Error()}, nil
+	}
+	if err := asynqInspector.DeleteTask(webhookQueue, params.TaskID); err != nil {
+		return &oas.ErrorResponse{Error: err.Error()}, nil
+	}
+	return &oas.DeleteDeadLetterNoContent{}, nil
+}
+
+// ReplayDeadLetter re-enqueues an archived webhook delivery
+func (h *Handler) ReplayDeadLetter(ctx context.Context, req oas.OptDeadLetterReplayRequest, params oas.ReplayDeadLetterParams) (oas.ReplayDeadLetterRes, error) {
+	info, err := getDeadLetterTask(params.TaskID)
+	if err != nil {
+		return &oas.ReplayDeadLetterNotFound{Error: err.Error()}, nil
+	}
+
+	var urlOverride string
+	if req.Set && req.Value.URL.Set {
+		urlOverride = req.Value.URL.Value.String()
+	}
+
+	taskID, err := replayDeadLetter(info, urlOverride)
+	if errors.Is(err, errDeadLetterGone) {
+		return &oas.ReplayDeadLetterNotFound{Error: err.Error()}, nil
+	}
+	if err != nil {
+		return &oas.ReplayDeadLetterInternalServerError{Error: err.Error()}, nil
+	}
+
+	return &oas.DeadLetterActionResponse{Count: 1, TaskIds: []string{taskID}}, nil
+}
+
+// ReplayDeadLetters re-enqueues every archived webhook delivery matching the
+// filter. Deliveries replayed or deleted by another request meanwhile are skipped.
+func (h *Handler) ReplayDeadLetters(ctx context.Context, req *oas.DeadLetterBulkRequest) (oas.ReplayDeadLettersRes, error) {
+	filter, err := bulkRequestFilter(req)
+	if err != nil {
+		return &oas.ReplayDeadLettersBadRequest{Error: err.Error()}, nil
+	}
+	if filter.empty() {
+		return &oas.ReplayDeadLettersBadRequest{Error: "at least one of command_id, endpoint, since or until required"}, nil
+	}
+
+	tasks, err := findDeadLetters(filter, 0)
+	if err != nil {
+		return &oas.ReplayDeadLettersInternalServerError{Error: err.Error()}, nil
+	}
+
+	var urlOverride string
+	if req.URL.Set {
+		urlOverride = req.URL.Value.String()
+	}
+
+	resp := &oas.DeadLetterActionResponse{TaskIds: []string{}}
+	for _, t := range tasks {
+		taskID, err := replayDeadLetter(t, urlOverride)
+		if errors.Is(err, errDeadLetterGone) {
+			continue
+		}
+		if err != nil {
+			return &oas.ReplayDeadLettersInternalServerError{
+				Error: fmt.Sprintf("replayed %d of %d, then failed on %s: %v", resp.Count, len(tasks), t.ID, err),
+			}, nil
+		}
+		resp.Count++
+		resp.TaskIds = append(resp.TaskIds, taskID)
+	}
+	return resp, nil
+}
+
+// DeleteDeadLetters permanently removes every archived webhook delivery
+// matching the filter. Deliveries replayed or deleted by another request
+// meanwhile are skipped.
+func (h *Handler) DeleteDeadLetters(ctx context.Context, req *oas.DeadLetterBulkRequest) (oas.DeleteDeadLettersRes, error) {
+	filter, err := bulkRequestFilter(req)
+	if err != nil {
+		return &oas.DeleteDeadLettersBadRequest{Error: err.Error()}, nil
+	}
+	if filter.empty() {
+		return &oas.DeleteDeadLettersBadRequest{Error: "at least one of command_id, endpoint, since or until required"}, nil
+	}
+
+	tasks, err := findDeadLetters(filter, 0)
+	if err != nil {
+		return &oas.DeleteDeadLettersInternalServerError{Error: err.Error()}, nil
+	}
+
+	resp := &oas.DeadLetterActionResponse{TaskIds: []string{}}
+	for _, t := range tasks {
+		err := asynqInspector.DeleteTask(webhookQueue, t.ID)
+		if errors.Is(err, asynq.ErrTaskNotFound) {
+			continue
+		}
+		if err != nil {
+			return &oas.DeleteDeadLettersInternalServerError{
+				Error: fmt.Sprintf("deleted %d of %d, then failed on %s: %v", resp.Count, len(tasks), t.ID, err),
+			}, nil
+		}
+		resp.Count++
+		resp.TaskIds = append(resp.TaskIds, t.ID)
+	}
+	return resp, nil
+}
+
+func bulkRequestFilter(req *oas.DeadLetterBulkRequest) (deadLetterFilter, error) {
+	return newDeadLetterFilter(req.CommandID.Or(""), req.Endpoint.Or(""), req.Since.Or(time.Time{}), req.Until.Or(time.Time{}))
+}
+
+// findDeadLetters pages through the archived webhook tasks and returns those
+// matching the filter. A limit of 0 returns every match.
+func findDeadLetters(filter deadLetterFilter, limit int) ([]*asynq.TaskInfo, error) {
+	const pageSize = 100
+
+	var matches []*asynq.TaskInfo
+	for page := 1; ; page++ {
+		tasks, err := asynqInspector.ListArchivedTasks(webhookQueue, asynq.PageSize(pageSize), asynq.Page(page))
+		if err != nil {
+			// The queue only exists once a webhook has been enqueued
+			if errors.Is(err, asynq.ErrQueueNotFound) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("list archived webhooks: %w", err)
+		}
+
+		for _, t := range tasks {
+			var p webhookTaskPayload
+			if err := json.Unmarshal(t.Payload, &p); err != nil {
+				continue
+			}
+			if !filter.matches(t, &p) {
+				continue
+			}
+			matches = append(matches, t)
+			if limit > 0 && len(matches) >= limit {
+				return matches, nil
+			}
+		}
+
+		if len(tasks) < pageSize {
+			return matches, nil
+		}
+	}
+}
+
+func getDeadLetterTask(taskID string) (*asynq.TaskInfo, error) {
+	info, err := asynqInspector.GetTaskInfo(webhookQueue, taskID)
+	if err != nil || info.Type != TypeWebhookDeliver {
+		return nil, fmt.Errorf("dead letter not found")
+	}
+	if info.State != asynq.TaskStateArchived {
+		return nil, fmt.Errorf("delivery %s is %s, not dead-lettered", taskID, info.State)
+	}
+	return info, nil
+}
+
+// replayDeadLetter enqueues a copy of an archived delivery, optionally with a
+// new URL, deletes the original and returns the new task's ID. The copy gets
+// the full retry budget again: its retry count starts at zero, and its replay
+// time restarts the webhooks service's maximum age. It keeps the original
+// task's ID as its event ID, so receivers can still deduplicate it.
+//
+// The copy's task ID is derived from the original's, so concurrent replays
+// enqueue it once. If the original is deleted before the replay removes it,
+// the copy is deleted too, unless a webhooks worker has already picked it up.
+// Either way errDeadLetterGone is returned.
+func replayDeadLetter(t *asynq.TaskInfo, urlOverride string) (string, error) {
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(t.Payload, &payload); err != nil {
+		return "", fmt.Errorf("unmarshal payload: %w", err)
+	}
+	if urlOverride != "" {
+		payload["url"], _ = json.Marshal(urlOverride)
+	}
+	if _, ok := payload["event_id"]; !ok {
+		payload["event_id"], _ = json.Marshal(t.ID)
+	}
+	payload["replayed_at"], _ = json.Marshal(time.Now())
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("marshal payload: %w", err)
+	}
+
+	opts := []asynq.Option{
+		asynq.TaskID(replayTaskID(t.ID)),
+		asynq.MaxRetry(t.MaxRetry),
+		asynq.Queue(webhookQueue),
+	}
+	if t.Retention > 0 {
+		opts = append(opts, asynq.Retention(t.Retention))
+	}
+
+	info, err := asynqClient.Enqueue(asynq.NewTask(TypeWebhookDeliver, payloadBytes), opts...)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		// Already replayed; finish removing the original if that failed
+		if err := asynqInspector.DeleteTask(webhookQueue, t.ID); err != nil && !errors.Is(err, asynq.ErrTaskNotFound) {
+			return "", fmt.Errorf("delete replayed task: %w", err)
+		}
+		return "", errDeadLetterGone
+	}
+	if err != nil {
+		return "", fmt.Errorf("enqueue replay: %w", err)
+	}
+	if err := asynqInspector.DeleteTask(webhookQueue, t.ID); err != nil {
+		if errors.Is(err, asynq.ErrTaskNotFound) {
+			asynqInspector.DeleteTask(webhookQueue, info.ID)
+			return "", errDeadLetterGone
+		}
+		return "", fmt.Errorf("delete replayed task: %w", err)
+	}
+	return info.ID, nil
+}
+
+// replayTaskID returns the task ID for a replay of the task with the given ID
+func replayTaskID(taskID string) string {
+	sum := sha256.Sum256([]byte("replay:" + taskID))
+	return hex.EncodeToString(sum[:16])
+}
+
+func taskToDeadLetter(t *asynq.TaskInfo, withPayload bool) oas.DeadLetter {
+	var p webhookTaskPayload
+	json.Unmarshal(t.Payload, &p)
+
+	dl := oas.DeadLetter{
+		TaskID:    t.ID,
+		CommandID: p.CommandID,
+		URL:       p.URL,
+		Event:     p.Status,
+		Retried:   t.Retried,
+	}
+	if t.LastErr != "" {
+		dl.LastError.SetTo(t.LastErr)
+	}
+	if !t.LastFailedAt.IsZero() {
+		dl.LastFailedAt.SetTo(t.LastFailedAt)
+	}
+
+	if withPayload && p.Body != nil {
+		body := make(oas.DeadLetterPayload, len(p.Body))
+		for k, v := range p.Body {
+			raw, err := json.Marshal(v)
+			if err != nil {
+				continue
+			}
+			body[k] = jx.Raw(raw)
+		}
+		dl.Payload.SetTo(body)
+	}
+
+	return dl
+}
@@ -4,30 +4,176 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
+	"ffmpeg-api/argpolicy"
 	"ffmpeg-api/oas"
+	"ffmpeg-api/redact"
+	"ffmpeg-api/shellwords"
 
 	"github.com/ghodss/yaml"
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
 )
 
 const TypeFFmpegCommand = "ffmpeg:command"
 
 // WorkerCommandRequest matches the worker's expected format
 type WorkerCommandRequest struct {
-	InputFiles     map[string]string `json:"input_files"`
-	OutputFiles    map[string]string `json:"output_files"`
-	FFmpegCommand  string            `json:"ffmpeg_command,omitempty"`
-	FFmpegCommands []string          `json:"ffmpeg_commands,omitempty"`
-	Webhook        string            `json:"webhook,omitempty"`
-	ReferenceID    string            `json:"reference_id,omitempty"`
+	InputFiles     map[string]WorkerInputFile `json:"input_files"`
+	OutputFiles    map[string]string          `json:"output_files"`
+	FFmpegCommand  string                     `json:"ffmpeg_command,omitempty"`
+	FFmpegCommands []string                   `json:"ffmpeg_commands,omitempty"`
+	FFmpegArgs     [][]string                 `json:"ffmpeg_args,omitempty"`
+	Webhook        string                     `json:"webhook,omitempty"`
+	ReferenceID    string                     `json:"reference_id,omitempty"`
+	ResourceClass  string                     `json:"resource_class,omitempty"`
+}
+
+// WorkerInputFile matches the worker's input format: a URL string, or an object with checks
+type WorkerInputFile struct {
+	URL      string `json:"url,omitempty"`
+	SHA256   string `json:"sha256,omitempty"`
+	MaxBytes int64  `json:"max_bytes,omitempty"`
+	Expect   string `json:"expect,omitempty"`
+
+	Headers map[string]string `json:"headers,omitempty"`
+	Auth    string            `json:"auth,omitempty"`
+	Mode    string            `json:"mode,omitempty"`
+
+	Extension string `json:"extension,omitempty"`
+
+	Content  string `json:"content,omitempty"`
+	Filename string `json:"filename,omitempty"`
+
+	Extract bool `json:"extract,omitempty"`
+}
+
+// UnmarshalJSON accepts a URL string or an object
+func (f *WorkerInputFile) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*f = WorkerInputFile{URL: s}
+		return nil
+	}
+	type plain WorkerInputFile
+	return json.Unmarshal(data, (*plain)(f))
+}
+
+// MarshalJSON writes inputs without checks as plain URL strings
+func (f WorkerInputFile) MarshalJSON() ([]byte, error) {
+	if f.isPlain() {
+		return json.Marshal(f.URL)
+	}
+	type plain WorkerInputFile
+	return json.Marshal(plain(f))
+}
+
+// newWorkerInputFile converts an API input to the worker's format
+func newWorkerInputFile(in oas.InputFile) WorkerInputFile {
+	spec, ok := in.GetInputFileSpec()
+	if !ok {
+		return WorkerInputFile{URL: in.String}
+	}
+	return WorkerInputFile{
+		URL:      spec.URL.Or(""),
+		SHA256:   spec.SHA256.Or(""),
+		MaxBytes: spec.MaxBytes.Or(0),
+		Expect:   string(spec.Expect.Or("")),
+		Headers:  spec.Headers.Or(nil),
+		Auth:     spec.Auth.Or(""),
+		Mode:     string(spec.Mode.Or("")),
+
+		Extension: spec.Extension.Or(""),
+
+		Content:  spec.Content.Or(""),
+		Filename: spec.Filename.Or(""),
+
+		Extract: spec.Extract.Or(false),
+	}
+}
+
+// inputKeyPattern matches input_files keys, which the worker uses as file
+// names. The schema's propertyNames says the same, but isn't enforced by the
+// generated code.
+var inputKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// validate checks what the schema can't: that the input has either a URL or
+// inline content, and that inline files are small enough to queue
+func (f WorkerInputFile) validate() error {
+	switch {
+	case f.URL == "" && f.Content == "":
+		return errors.New("url or content is required")
+	case f.URL != "" && f.Content != "":
+		return errors.New("url and content can't both be given")
+	}
+	size := len(f.Content)
+	if len(f.URL) > 5 && strings.EqualFold(f.URL[:5], "data:") {
+		size = len(f.URL)
+	}
+	if size > inlineInputMaxBytes {
+		return fmt.Errorf("inline content is over %d bytes, host the file instead", inlineInputMaxBytes)
+	}
+	return nil
+}
+
+// isPlain reports whether the input is just a URL
+func (f WorkerInputFile) isPlain() bool {
+	return f.SHA256 == "" && f.MaxBytes == 0 && f.Expect == "" && len(f.Headers) == 0 && f.Auth == "" && f.Mode == "" && f.Extension == "" &&
+		f.Content == "" && f.Filename == "" && !f.Extract
+}
+
+// oasInputFile converts a worker input back to the form it was sent in
+func (f WorkerInputFile) oasInputFile() oas.InputFile {
+	if f.isPlain() {
+		return oas.NewStringInputFile(f.URL)
+	}
+	var spec oas.InputFileSpec
+	if f.URL != "" {
+		spec.URL.SetTo(f.URL)
+	}
+	if f.SHA256 != "" {
+		spec.SHA256.SetTo(f.SHA256)
+	}
+	if f.MaxBytes > 0 {
+		spec.MaxBytes.SetTo(f.MaxBytes)
+	}
+	if f.Expect != "" {
+		spec.Expect.SetTo(oas.InputFileSpecExpect(f.Expect))
+	}
+	if len(f.Headers) > 0 {
+		spec.Headers.SetTo(f.Headers)
+	}
+	if f.Auth != "" {
+		spec.Auth.SetTo(f.Auth)
+	}
+	if f.Mode != "" {
+		spec.Mode.SetTo(oas.InputFileSpecMode(f.Mode))
+	}
+	if f.Extension != "" {
+		spec.Extension.SetTo(f.Extension)
+	}
+	if f.Content != "" {
+		spec.Content.SetTo(f.Content)
+	}
+	if f.Filename != "" {
+		spec.Filename.SetTo(f.Filename)
+	}
+	if f.Extract {
+		spec.Extract.SetTo(true)
+	}
+	return oas.NewInputFileSpecInputFile(spec)
 }
 
 // WorkerCommandResult matches the worker's result format
@@ -35,6 +181,9 @@ type WorkerCommandResult struct {
 	OutputFiles             map[string]WorkerOutputFileInfo `json:"output_files"`
 	FFmpegCommandRunSeconds float64                         `json:"ffmpeg_command_run_seconds"`
 	TotalProcessingSeconds  float64                         `json:"total_processing_seconds"`
+	DownloadSeconds         float64                         `json:"download_seconds"`
+	DownloadBytes           int64                           `json:"download_bytes"`
+	DownloadMBytesPerSecond float64                         `json:"download_mbytes_per_second"`
 	CompletedAt             time.Time                       `json:"completed_at"`
 }
 
@@ -52,9 +201,15 @@ type WorkerOutputFileInfo struct {
 var (
 	asynqClient    *asynq.Client
 	asynqInspector *asynq.Inspector
+	redisClient    *redis.Client
 	taskMaxRetry   int
 	taskTimeoutMin int
 	taskRetentionH int
+	adminAPIKey    string
+	redactor       *redact.Redactor
+	argPolicy      *argpolicy.Policy
+
+	inlineInputMaxBytes int
 )
 
 // Handler implements the oas.Handler interface
@@ -68,10 +223,47 @@ func main() {
 	taskMaxRetry = getEnvInt("TASK_MAX_RETRY", 2)
 	taskTimeoutMin = getEnvInt("TASK_TIMEOUT_MINUTES", 30)
 	taskRetentionH = getEnvInt("TASK_RETENTION_HOURS", 24)
+	inlineInputMaxBytes = getEnvInt("INLINE_INPUT_MAX_BYTES", 1<<20)
+	adminAPIKey = os.Getenv("ADMIN_API_KEY")
+
+	// The original request is redacted before it's returned in command status
+	var err error
+	redactor, err = redact.New(
+		getEnv("REDACT_STRIP_QUERY", "input_files.*,input_files.*.url"),
+		getEnv("REDACT_DROP_FIELDS", ""),
+		getEnv("REDACT_MASK_PATTERNS", ""),
+	)
+	if err != nil {
+		log.Fatalf("Invalid redaction settings: %v", err)
+	}
+	// Forwarded input headers may carry credentials
+	redactor.MaskFields("input_files.*.headers.*")
+
+	// Commands are checked against the same policy as in the worker
+	argPolicy, err = argpolicy.New(
+		getEnv("FFMPEG_DENIED_OPTIONS", argpolicy.DefaultDeniedOptions),
+		getEnv("FFMPEG_DENIED_FILTERS", argpolicy.DefaultDeniedFilters),
+		getEnv("FFMPEG_DENIED_FORMATS", argpolicy.DefaultDeniedFormats),
+		getEnv("FFMPEG_ALLOWED_PROTOCOLS", argpolicy.DefaultProtocols),
+	)
+	if err != nil {
+		log.Fatalf("Invalid ffmpeg argument policy: %v", err)
+	}
+	// The API has no ffmpeg of its own; with the worker's ffmpeg -h full
+	// output it rejects unknown options too, otherwise only the worker does
+	if path := getEnv("FFMPEG_OPTIONS_FILE", ""); path != "" {
+		help, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read ffmpeg options: %v", err)
+		}
+		argPolicy.Options = argpolicy.ParseHelp(string(help))
+	}
 
 	asynqClient = asynq.NewClient(asynq.RedisClientOpt{Addr: redisAddr})
 	asynqInspector = asynq.NewInspector(asynq.RedisClientOpt{Addr: redisAddr})
+	redisClient = redis.NewClient(&redis.Options{Addr: redisAddr})
 	defer asynqClient.Close()
+	defer redisClient.Close()
 
 	handler := &Handler{}
 	srv, err := oas.NewServer(handler)
@@ -84,8 +276,12 @@ func main() {
 	mux.HandleFunc("/openapi.json", serveOpenAPISpec)
 	mux.Handle("/", srv)
 
-	// Wrap with CORS middleware
-	corsHandler := corsMiddleware(mux)
+	// Wrap with admin auth and CORS middleware
+	corsHandler := corsMiddleware(adminAuthMiddleware(mux))
+
+	if adminAPIKey == "" {
+		log.Printf("ADMIN_API_KEY not set, admin endpoints are disabled")
+	}
 
 	log.Printf("FFmpeg Command API listening on :%s", port)
 	log.Printf("OpenAPI spec available at http://localhost:%s/openapi.json", port)
@@ -111,7 +307,7 @@ func getEnvInt(key string, defaultVal int) int {
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-API-Key")
 
 		if r.Method == http.MethodOptions {
@@ -123,33 +319,59 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// adminAuthMiddleware requires the X-API-Key header to match ADMIN_API_KEY on /v1/admin/ routes
+func adminAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/v1/admin/") || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if adminAPIKey == "" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":"admin endpoints are disabled"}`))
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-API-Key")), []byte(adminAPIKey)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid API key"}`))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 // HealthCheck implements the health check endpoint
 func (h *Handler) HealthCheck(ctx context.Context) (*oas.HealthResponse, error) {
 	return &oas.HealthResponse{Status: "ok"}, nil
 }
 
 // ListCommands returns all commands
-func (h *Handler) ListCommands(ctx context.Context) (*oas.CommandListResponse, error) {
-	var commands []oas.CommandStatus
-
+func (h *Handler) ListCommands(ctx context.Context) (oas.ListCommandsRes, error) {
 	active, _ := asynqInspector.ListActiveTasks("ffmpeg", asynq.PageSize(100))
-	for _, t := range active {
-		commands = append(commands, taskToStatus(t, oas.CommandStatusStatusPROCESSING))
-	}
-
 	pending, _ := asynqInspector.ListPendingTasks("ffmpeg", asynq.PageSize(100))
-	for _, t := range pending {
-		commands = append(commands, taskToStatus(t, oas.CommandStatusStatusPENDING))
-	}
-
 	completed, _ := asynqInspector.ListCompletedTasks("ffmpeg", asynq.PageSize(100))
-	for _, t := range completed {
-		commands = append(commands, taskToStatus(t, oas.CommandStatusStatusSUCCESS))
-	}
-
 	archived, _ := asynqInspector.ListArchivedTasks("ffmpeg", asynq.PageSize(100))
-	for _, t := range archived {
-		commands = append(commands, taskToStatus(t, oas.CommandStatusStatusFAILED))
+
+	var commands []oas.CommandStatus
+	for _, group := range []struct {
+		tasks  []*asynq.TaskInfo
+		status oas.CommandStatusStatus
+	}{
+		{active, oas.CommandStatusStatusPROCESSING},
+		{pending, oas.CommandStatusStatusPENDING},
+		{completed, oas.CommandStatusStatusSUCCESS},
+		{archived, oas.CommandStatusStatusFAILED},
+	} {
+		for _, t := range group.tasks {
+			cs, err := taskToStatus(ctx, t, group.status)
+			if err != nil {
+				return &oas.ErrorResponse{Error: err.Error()}, nil
+			}
+			commands = append(commands, cs)
+		}
 	}
 
 	return &oas.CommandListResponse{
@@ -158,9 +380,14 @@ func (h *Handler) ListCommands(ctx context.Context) (*oas.CommandListResponse, e
 	}, nil
 }
 
-func taskToStatus(t *asynq.TaskInfo, status oas.CommandStatusStatus) oas.CommandStatus {
-	var req WorkerCommandRequest
+// taskToStatus describes a command task. It fails if the webhook delivery log can't be read.
+func taskToStatus(ctx context.Context, t *asynq.TaskInfo, status oas.CommandStatusStatus) (oas.CommandStatus, error) {
+	// Unredacted fields are still used below to decide whether a webhook was requested
+	var req, shown WorkerCommandRequest
 	json.Unmarshal(t.Payload, &req)
+	if redacted, err := redactor.JSON(t.Payload); err == nil {
+		json.Unmarshal(redacted, &shown)
+	}
 
 	cs := oas.CommandStatus{
 		CommandID: t.ID,
@@ -170,24 +397,34 @@ func taskToStatus(t *asynq.TaskInfo, status oas.CommandStatusStatus) oas.Command
 
 	// Convert original request
 	origReq := oas.CommandRequest{
-		OutputFiles: oas.CommandRequestOutputFiles(req.OutputFiles),
+		OutputFiles: oas.CommandRequestOutputFiles(shown.OutputFiles),
 	}
-	if req.InputFiles != nil {
-		origReq.InputFiles.SetTo(oas.CommandRequestInputFiles(req.InputFiles))
+	if shown.InputFiles != nil {
+		inputFiles := make(oas.CommandRequestInputFiles, len(shown.InputFiles))
+		for k, v := range shown.InputFiles {
+			inputFiles[k] = v.oasInputFile()
+		}
+		origReq.InputFiles.SetTo(inputFiles)
 	}
-	if req.FFmpegCommand != "" {
-		origReq.FfmpegCommand.SetTo(req.FFmpegCommand)
+	if shown.FFmpegCommand != "" {
+		origReq.FfmpegCommand.SetTo(shown.FFmpegCommand)
 	}
-	if len(req.FFmpegCommands) > 0 {
-		origReq.FfmpegCommands = req.FFmpegCommands
+	if len(shown.FFmpegCommands) > 0 {
+		origReq.FfmpegCommands = shown.FFmpegCommands
 	}
-	if req.Webhook != "" {
-		if u, err := url.Parse(req.Webhook); err == nil {
+	if len(shown.FFmpegArgs) > 0 {
+		origReq.FfmpegArgs = shown.FFmpegArgs
+	}
+	if shown.Webhook != "" {
+		if u, err := url.Parse(shown.Webhook); err == nil {
 			origReq.Webhook.SetTo(*u)
 		}
 	}
-	if req.ReferenceID != "" {
-		origReq.ReferenceID.SetTo(req.ReferenceID)
+	if shown.ReferenceID != "" {
+		origReq.ReferenceID.SetTo(shown.ReferenceID)
+	}
+	if shown.ResourceClass != "" {
+		origReq.ResourceClass.SetTo(oas.CommandRequestResourceClass(shown.ResourceClass))
 	}
 	cs.OriginalRequest.SetTo(origReq)
 
@@ -214,6 +451,9 @@ func taskToStatus(t *asynq.TaskInfo, status oas.CommandStatusStatus) oas.Command
 			cs.OutputFiles.SetTo(outputFiles)
 			cs.FfmpegCommandRunSeconds.SetTo(result.FFmpegCommandRunSeconds)
 			cs.TotalProcessingSeconds.SetTo(result.TotalProcessingSeconds)
+			cs.DownloadSeconds.SetTo(result.DownloadSeconds)
+			cs.DownloadBytes.SetTo(result.DownloadBytes)
+			cs.DownloadMbytesPerSecond.SetTo(result.DownloadMBytesPerSecond)
 			if !result.CompletedAt.IsZero() {
 				cs.CompletedAt.SetTo(result.CompletedAt)
 			}
@@ -225,14 +465,28 @@ func taskToStatus(t *asynq.TaskInfo, status oas.CommandStatusStatus) oas.Command
 		cs.Status = oas.CommandStatusStatusFAILED
 	}
 
-	return cs
+	if req.Webhook != "" {
+		ws, err := webhookStatus(ctx, t.ID)
+		if err != nil {
+			return cs, err
+		}
+		cs.WebhookStatus.SetTo(ws)
+	}
+
+	return cs, nil
 }
 
 // CreateCommand creates a new FFmpeg command
 func (h *Handler) CreateCommand(ctx context.Context, req *oas.CommandRequest) (oas.CreateCommandRes, error) {
 	// Validate
-	if !req.FfmpegCommand.Set && len(req.FfmpegCommands) == 0 {
-		return &oas.CreateCommandBadRequest{Error: "ffmpeg_command or ffmpeg_commands required"}, nil
+	if !req.FfmpegCommand.Set && len(req.FfmpegCommands) == 0 && len(req.FfmpegArgs) == 0 {
+		return &oas.CreateCommandBadRequest{Error: "ffmpeg_command, ffmpeg_commands or ffmpeg_args required"}, nil
+	}
+	if len(req.FfmpegArgs) > 0 && (req.FfmpegCommand.Set || len(req.FfmpegCommands) > 0) {
+		return &oas.CreateCommandBadRequest{Error: "ffmpeg_args can't be combined with ffmpeg_command or ffmpeg_commands"}, nil
+	}
+	if err := validateCommands(req); err != nil {
+		return &oas.CreateCommandBadRequest{Error: err.Error()}, nil
 	}
 
 	if len(req.OutputFiles) == 0 {
@@ -244,7 +498,17 @@ func (h *Handler) CreateCommand(ctx context.Context, req *oas.CommandRequest) (o
 		OutputFiles: map[string]string(req.OutputFiles),
 	}
 	if req.InputFiles.Set {
-		workerReq.InputFiles = map[string]string(req.InputFiles.Value)
+		workerReq.InputFiles = make(map[string]WorkerInputFile, len(req.InputFiles.Value))
+		for k, v := range req.InputFiles.Value {
+			if !inputKeyPattern.MatchString(k) {
+				return &oas.CreateCommandBadRequest{Error: fmt.Sprintf("input_files: invalid key %q: only letters, digits, _ and - are allowed", k)}, nil
+			}
+			in := newWorkerInputFile(v)
+			if err := in.validate(); err != nil {
+				return &oas.CreateCommandBadRequest{Error: fmt.Sprintf("input_files.%s: %v", k, err)}, nil
+			}
+			workerReq.InputFiles[k] = in
+		}
 	}
 	if req.FfmpegCommand.Set {
 		workerReq.FFmpegCommand = req.FfmpegCommand.Value
@@ -252,12 +516,18 @@ func (h *Handler) CreateCommand(ctx context.Context, req *oas.CommandRequest) (o
 	if len(req.FfmpegCommands) > 0 {
 		workerReq.FFmpegCommands = req.FfmpegCommands
 	}
+	if len(req.FfmpegArgs) > 0 {
+		workerReq.FFmpegArgs = req.FfmpegArgs
+	}
 	if req.Webhook.Set {
 		workerReq.Webhook = req.Webhook.Value.String()
 	}
 	if req.ReferenceID.Set {
 		workerReq.ReferenceID = req.ReferenceID.Value
 	}
+	if req.ResourceClass.Set {
+		workerReq.ResourceClass = string(req.ResourceClass.Value)
+	}
 
 	payload, _ := json.Marshal(workerReq)
 	task := asynq.NewTask(TypeFFmpegCommand, payload)
@@ -283,6 +553,40 @@ func (h *Handler) CreateCommand(ctx context.Context, req *oas.CommandRequest) (o
 	return resp, nil
 }
 
+// validateCommands checks that command strings can be split into arguments
+// the way the worker will, and that the arguments are allowed by the policy
+// before placeholders are expanded: files must be placeholders or relative
+// paths, which the worker resolves in the job directory.
+func validateCommands(req *oas.CommandRequest) error {
+	if req.FfmpegCommand.Set {
+		if err := validateCommand(req.FfmpegCommand.Value); err != nil {
+			return fmt.Errorf("ffmpeg_command: %w", err)
+		}
+	}
+	for i, cmd := range req.FfmpegCommands {
+		if err := validateCommand(cmd); err != nil {
+			return fmt.Errorf("ffmpeg_commands.%d: %w", i, err)
+		}
+	}
+	for i, args := range req.FfmpegArgs {
+		if len(args) == 0 {
+			return fmt.Errorf("ffmpeg_args.%d: no arguments", i)
+		}
+		if err := argPolicy.Check(args, argpolicy.Scope{}); err != nil {
+			return fmt.Errorf("ffmpeg_args.%d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func validateCommand(cmd string) error {
+	args, err := shellwords.Split(cmd)
+	if err != nil {
+		return err
+	}
+	return argPolicy.Check(args, argpolicy.Scope{})
+}
+
 // GetCommand returns a command by ID
 func (h *Handler) GetCommand(ctx context.Context, params oas.GetCommandParams) (oas.GetCommandRes, error) {
 	if params.ID == "" {
@@ -295,7 +599,10 @@ func (h *Handler) GetCommand(ctx context.Context, params oas.GetCommandParams) (
 	}
 
 	status := stateToStatus(info.State)
-	cs := taskToStatus(info, status)
+	cs, err := taskToStatus(ctx, info, status)
+	if err != nil {
+		return &oas.GetCommandInternalServerError{Error: err.Error()}, nil
+	}
 
 	return &cs, nil
 }
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInputFileValidate(t *testing.T) {
+	defer func(n int) { inlineInputMaxBytes = n }(inlineInputMaxBytes)
+	inlineInputMaxBytes = 16
+
+	tests := []struct {
+		name  string
+		file  WorkerInputFile
+		valid bool
+	}{
+		{"url", WorkerInputFile{URL: "https://example.com/" + strings.Repeat("a", 32) + ".mp4"}, true},
+		{"content", WorkerInputFile{Content: "file 'in_1'"}, true},
+		{"neither", WorkerInputFile{}, false},
+		{"both", WorkerInputFile{URL: "https://example.com/a.mp4", Content: "x"}, false},
+		{"content at limit", WorkerInputFile{Content: strings.Repeat("a", 16)}, true},
+		{"content over limit", WorkerInputFile{Content: strings.Repeat("a", 17)}, false},
+		{"data uri at limit", WorkerInputFile{URL: "data:," + strings.Repeat("a", 10)}, true},
+		{"data uri over limit", WorkerInputFile{URL: "DATA:," + strings.Repeat("a", 11)}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.file.validate(); (err == nil) != tt.valid {
+			t.Errorf("%s: validate() = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
@@ -12,9 +12,15 @@ import (
 	ht "github.com/ogen-go/ogen/http"
 	"github.com/ogen-go/ogen/middleware"
 	"github.com/ogen-go/ogen/ogenerrors"
+	"github.com/ogen-go/ogen/ogenregex"
 	"github.com/ogen-go/ogen/otelogen"
 )
 
+var regexMap = map[string]ogenregex.Regexp{
+	"^[0-9a-fA-F]{64}$":                   ogenregex.MustCompile("^[0-9a-fA-F]{64}$"),
+	"^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$": ogenregex.MustCompile("^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$"),
+	"^\\.?[A-Za-z0-9]{1,10}$":             ogenregex.MustCompile("^\\.?[A-Za-z0-9]{1,10}$"),
+}
 var (
 	// Allocate option closure once.
 	clientSpanKind = trace.WithSpanKind(trace.SpanKindClient)
@@ -29,12 +29,30 @@ type Invoker interface {
 	//
 	// POST /v1/commands
 	CreateCommand(ctx context.Context, request *CommandRequest) (CreateCommandRes, error)
+	// DeleteDeadLetter invokes deleteDeadLetter operation.
+	//
+	// Permanently delete a dead-lettered delivery.
+	//
+	// DELETE /v1/admin/webhooks/dead-letters/{task_id}
+	DeleteDeadLetter(ctx context.Context, params DeleteDeadLetterParams) (DeleteDeadLetterRes, error)
+	// DeleteDeadLetters invokes deleteDeadLetters operation.
+	//
+	// Permanently delete every dead-lettered delivery matching the filter.
+	//
+	// POST /v1/admin/webhooks/dead-letters/delete
+	DeleteDeadLetters(ctx context.Context, request *DeadLetterBulkRequest) (DeleteDeadLettersRes, error)
 	// GetCommand invokes getCommand operation.
 	//
 	// Get the status and results of a specific command.
 	//
 	// GET /v1/commands/{id}
 	GetCommand(ctx context.Context, params GetCommandParams) (GetCommandRes, error)
+	// GetDeadLetter invokes getDeadLetter operation.
+	//
+	// Get a dead-lettered delivery including its payload.
+	//
+	// GET /v1/admin/webhooks/dead-letters/{task_id}
+	GetDeadLetter(ctx context.Context, params GetDeadLetterParams) (GetDeadLetterRes, error)
 	// GetOpenAPI invokes getOpenAPI operation.
 	//
 	// Returns the OpenAPI 3.1 specification for this API.
@@ -47,12 +65,36 @@ type Invoker interface {
 	//
 	// GET /health
 	HealthCheck(ctx context.Context) (*HealthResponse, error)
+	// ListCommandWebhooks invokes listCommandWebhooks operation.
+	//
+	// Get every webhook delivery attempt recorded for a command.
+	//
+	// GET /v1/commands/{id}/webhooks
+	ListCommandWebhooks(ctx context.Context, params ListCommandWebhooksParams) (ListCommandWebhooksRes, error)
 	// ListCommands invokes listCommands operation.
 	//
 	// Get a list of all FFmpeg commands with their current status.
 	//
 	// GET /v1/commands
-	ListCommands(ctx context.Context) (*CommandListResponse, error)
+	ListCommands(ctx context.Context) (ListCommandsRes, error)
+	// ListDeadLetters invokes listDeadLetters operation.
+	//
+	// List webhook deliveries that exhausted their retries, newest failures first.
+	//
+	// GET /v1/admin/webhooks/dead-letters
+	ListDeadLetters(ctx context.Context, params ListDeadLettersParams) (ListDeadLettersRes, error)
+	// ReplayDeadLetter invokes replayDeadLetter operation.
+	//
+	// Re-enqueue a dead-lettered delivery, optionally to a different URL.
+	//
+	// POST /v1/admin/webhooks/dead-letters/{task_id}/replay
+	ReplayDeadLetter(ctx context.Context, request OptDeadLetterReplayRequest, params ReplayDeadLetterParams) (ReplayDeadLetterRes, error)
+	// ReplayDeadLetters invokes replayDeadLetters operation.
+	//
+	// Re-enqueue every dead-lettered delivery matching the filter.
+	//
+	// POST /v1/admin/webhooks/dead-letters/replay
+	ReplayDeadLetters(ctx context.Context, request *DeadLetterBulkRequest) (ReplayDeadLettersRes, error)
 }
 
 // Client implements OAS client.
@@ -178,6 +220,171 @@ func (c *Client) sendCreateCommand(ctx context.Context, request *CommandRequest)
 	return result, nil
 }
 
+// DeleteDeadLetter invokes deleteDeadLetter operation.
+//
+// Permanently delete a dead-lettered delivery.
+//
+// DELETE /v1/admin/webhooks/dead-letters/{task_id}
+func (c *Client) DeleteDeadLetter(ctx context.Context, params DeleteDeadLetterParams) (DeleteDeadLetterRes, error) {
+	res, err := c.sendDeleteDeadLetter(ctx, params)
+	return res, err
+}
+
+func (c *Client) sendDeleteDeadLetter(ctx context.Context, params DeleteDeadLetterParams) (res DeleteDeadLetterRes, err error) {
+	otelAttrs := []attribute.KeyValue{
+		otelogen.OperationID("deleteDeadLetter"),
+		semconv.HTTPRequestMethodKey.String("DELETE"),
+		semconv.HTTPRouteKey.String("/v1/admin/webhooks/dead-letters/{task_id}"),
+	}
+
+	// Run stopwatch.
+	startTime := time.Now()
+	defer func() {
+		// Use floating point division here for higher precision (instead of Millisecond method).
+		elapsedDuration := time.Since(startTime)
+		c.duration.Record(ctx, float64(elapsedDuration)/float64(time.Millisecond), metric.WithAttributes(otelAttrs...))
+	}()
+
+	// Increment request counter.
+	c.requests.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+
+	// Start a span for this request.
+	ctx, span := c.cfg.Tracer.Start(ctx, DeleteDeadLetterOperation,
+		trace.WithAttributes(otelAttrs...),
+		clientSpanKind,
+	)
+	// Track stage for error reporting.
+	var stage string
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, stage)
+			c.errors.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+		}
+		span.End()
+	}()
+
+	stage = "BuildURL"
+	u := uri.Clone(c.requestURL(ctx))
+	var pathParts [2]string
+	pathParts[0] = "/v1/admin/webhooks/dead-letters/"
+	{
+		// Encode "task_id" parameter.
+		e := uri.NewPathEncoder(uri.PathEncoderConfig{
+			Param:   "task_id",
+			Style:   uri.PathStyleSimple,
+			Explode: false,
+		})
+		if err := func() error {
+			return e.EncodeValue(conv.StringToString(params.TaskID))
+		}(); err != nil {
+			return res, errors.Wrap(err, "encode path")
+		}
+		encoded, err := e.Result()
+		if err != nil {
+			return res, errors.Wrap(err, "encode path")
+		}
+		pathParts[1] = encoded
+	}
+	uri.AddPathParts(u, pathParts[:]...)
+
+	stage = "EncodeRequest"
+	r, err := ht.NewRequest(ctx, "DELETE", u)
+	if err != nil {
+		return res, errors.Wrap(err, "create request")
+	}
+
+	stage = "SendRequest"
+	resp, err := c.cfg.Client.Do(r)
+	if err != nil {
+		return res, errors.Wrap(err, "do request")
+	}
+	defer resp.Body.Close()
+
+	stage = "DecodeResponse"
+	result, err := decodeDeleteDeadLetterResponse(resp)
+	if err != nil {
+		return res, errors.Wrap(err, "decode response")
+	}
+
+	return result, nil
+}
+
+// DeleteDeadLetters invokes deleteDeadLetters operation.
+//
+// Permanently delete every dead-lettered delivery matching the filter.
+//
+// POST /v1/admin/webhooks/dead-letters/delete
+func (c *Client) DeleteDeadLetters(ctx context.Context, request *DeadLetterBulkRequest) (DeleteDeadLettersRes, error) {
+	res, err := c.sendDeleteDeadLetters(ctx, request)
+	return res, err
+}
+
+func (c *Client) sendDeleteDeadLetters(ctx context.Context, request *DeadLetterBulkRequest) (res DeleteDeadLettersRes, err error) {
+	otelAttrs := []attribute.KeyValue{
+		otelogen.OperationID("deleteDeadLetters"),
+		semconv.HTTPRequestMethodKey.String("POST"),
+		semconv.HTTPRouteKey.String("/v1/admin/webhooks/dead-letters/delete"),
+	}
+
+	// Run stopwatch.
+	startTime := time.Now()
+	defer func() {
+		// Use floating point division here for higher precision (instead of Millisecond method).
+		elapsedDuration := time.Since(startTime)
+		c.duration.Record(ctx, float64(elapsedDuration)/float64(time.Millisecond), metric.WithAttributes(otelAttrs...))
+	}()
+
+	// Increment request counter.
+	c.requests.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+
+	// Start a span for this request.
+	ctx, span := c.cfg.Tracer.Start(ctx, DeleteDeadLettersOperation,
+		trace.WithAttributes(otelAttrs...),
+		clientSpanKind,
+	)
+	// Track stage for error reporting.
+	var stage string
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, stage)
+			c.errors.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+		}
+		span.End()
+	}()
+
+	stage = "BuildURL"
+	u := uri.Clone(c.requestURL(ctx))
+	var pathParts [1]string
+	pathParts[0] = "/v1/admin/webhooks/dead-letters/delete"
+	uri.AddPathParts(u, pathParts[:]...)
+
+	stage = "EncodeRequest"
+	r, err := ht.NewRequest(ctx, "POST", u)
+	if err != nil {
+		return res, errors.Wrap(err, "create request")
+	}
+	if err := encodeDeleteDeadLettersRequest(request, r); err != nil {
+		return res, errors.Wrap(err, "encode request")
+	}
+
+	stage = "SendRequest"
+	resp, err := c.cfg.Client.Do(r)
+	if err != nil {
+		return res, errors.Wrap(err, "do request")
+	}
+	defer resp.Body.Close()
+
+	stage = "DecodeResponse"
+	result, err := decodeDeleteDeadLettersResponse(resp)
+	if err != nil {
+		return res, errors.Wrap(err, "decode response")
+	}
+
+	return result, nil
+}
+
 // GetCommand invokes getCommand operation.
 //
 // Get the status and results of a specific command.
@@ -268,6 +475,96 @@ func (c *Client) sendGetCommand(ctx context.Context, params GetCommandParams) (r
 	return result, nil
 }
 
+// GetDeadLetter invokes getDeadLetter operation.
+//
+// Get a dead-lettered delivery including its payload.
+//
+// GET /v1/admin/webhooks/dead-letters/{task_id}
+func (c *Client) GetDeadLetter(ctx context.Context, params GetDeadLetterParams) (GetDeadLetterRes, error) {
+	res, err := c.sendGetDeadLetter(ctx, params)
+	return res, err
+}
+
+func (c *Client) sendGetDeadLetter(ctx context.Context, params GetDeadLetterParams) (res GetDeadLetterRes, err error) {
+	otelAttrs := []attribute.KeyValue{
+		otelogen.OperationID("getDeadLetter"),
+		semconv.HTTPRequestMethodKey.String("GET"),
+		semconv.HTTPRouteKey.String("/v1/admin/webhooks/dead-letters/{task_id}"),
+	}
+
+	// Run stopwatch.
+	startTime := time.Now()
+	defer func() {
+		// Use floating point division here for higher precision (instead of Millisecond method).
+		elapsedDuration := time.Since(startTime)
+		c.duration.Record(ctx, float64(elapsedDuration)/float64(time.Millisecond), metric.WithAttributes(otelAttrs...))
+	}()
+
+	// Increment request counter.
+	c.requests.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+
+	// Start a span for this request.
+	ctx, span := c.cfg.Tracer.Start(ctx, GetDeadLetterOperation,
+		trace.WithAttributes(otelAttrs...),
+		clientSpanKind,
+	)
+	// Track stage for error reporting.
+	var stage string
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, stage)
+			c.errors.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+		}
+		span.End()
+	}()
+
+	stage = "BuildURL"
+	u := uri.Clone(c.requestURL(ctx))
+	var pathParts [2]string
+	pathParts[0] = "/v1/admin/webhooks/dead-letters/"
+	{
+		// Encode "task_id" parameter.
+		e := uri.NewPathEncoder(uri.PathEncoderConfig{
+			Param:   "task_id",
+			Style:   uri.PathStyleSimple,
+			Explode: false,
+		})
+		if err := func() error {
+			return e.EncodeValue(conv.StringToString(params.TaskID))
+		}(); err != nil {
+			return res, errors.Wrap(err, "encode path")
+		}
+		encoded, err := e.Result()
+		if err != nil {
+			return res, errors.Wrap(err, "encode path")
+		}
+		pathParts[1] = encoded
+	}
+	uri.AddPathParts(u, pathParts[:]...)
+
+	stage = "EncodeRequest"
+	r, err := ht.NewRequest(ctx, "GET", u)
+	if err != nil {
+		return res, errors.Wrap(err, "create request")
+	}
+
+	stage = "SendRequest"
+	resp, err := c.cfg.Client.Do(r)
+	if err != nil {
+		return res, errors.Wrap(err, "do request")
+	}
+	defer resp.Body.Close()
+
+	stage = "DecodeResponse"
+	result, err := decodeGetDeadLetterResponse(resp)
+	if err != nil {
+		return res, errors.Wrap(err, "decode response")
+	}
+
+	return result, nil
+}
+
 // GetOpenAPI invokes getOpenAPI operation.
 //
 // Returns the OpenAPI 3.1 specification for this API.
@@ -412,21 +709,21 @@ func (c *Client) sendHealthCheck(ctx context.Context) (res *HealthResponse, err
 	return result, nil
 }
 
-// ListCommands invokes listCommands operation.
+// ListCommandWebhooks invokes listCommandWebhooks operation.
 //
-// Get a list of all FFmpeg commands with their current status.
+// Get every webhook delivery attempt recorded for a command.
 //
-// GET /v1/commands
-func (c *Client) ListCommands(ctx context.Context) (*CommandListResponse, error) {
-	res, err := c.sendListCommands(ctx)
+// GET /v1/commands/{id}/webhooks
+func (c *Client) ListCommandWebhooks(ctx context.Context, params ListCommandWebhooksParams) (ListCommandWebhooksRes, error) {
+	res, err := c.sendListCommandWebhooks(ctx, params)
 	return res, err
 }
 
-func (c *Client) sendListCommands(ctx context.Context) (res *CommandListResponse, err error) {
+func (c *Client) sendListCommandWebhooks(ctx context.Context, params ListCommandWebhooksParams) (res ListCommandWebhooksRes, err error) {
 	otelAttrs := []attribute.KeyValue{
-		otelogen.OperationID("listCommands"),
+		otelogen.OperationID("listCommandWebhooks"),
 		semconv.HTTPRequestMethodKey.String("GET"),
-		semconv.HTTPRouteKey.String("/v1/commands"),
+		semconv.HTTPRouteKey.String("/v1/commands/{id}/webhooks"),
 	}
 
 	// Run stopwatch.
@@ -441,7 +738,7 @@ func (c *Client) sendListCommands(ctx context.Context) (res *CommandListResponse
 	c.requests.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
 
 	// Start a span for this request.
-	ctx, span := c.cfg.Tracer.Start(ctx, ListCommandsOperation,
+	ctx, span := c.cfg.Tracer.Start(ctx, ListCommandWebhooksOperation,
 		trace.WithAttributes(otelAttrs...),
 		clientSpanKind,
 	)
@@ -458,8 +755,27 @@ func (c *Client) sendListCommands(ctx context.Context) (res *CommandListResponse
 
 	stage = "BuildURL"
 	u := uri.Clone(c.requestURL(ctx))
-	var pathParts [1]string
-	pathParts[0] = "/v1/commands"
+	var pathParts [3]string
+	pathParts[0] = "/v1/commands/"
+	{
+		// Encode "id" parameter.
+		e := uri.NewPathEncoder(uri.PathEncoderConfig{
+			Param:   "id",
+			Style:   uri.PathStyleSimple,
+			Explode: false,
+		})
+		if err := func() error {
+			return e.EncodeValue(conv.StringToString(params.ID))
+		}(); err != nil {
+			return res, errors.Wrap(err, "encode path")
+		}
+		encoded, err := e.Result()
+		if err != nil {
+			return res, errors.Wrap(err, "encode path")
+		}
+		pathParts[1] = encoded
+	}
+	pathParts[2] = "/webhooks"
 	uri.AddPathParts(u, pathParts[:]...)
 
 	stage = "EncodeRequest"
@@ -476,7 +792,409 @@ func (c *Client) sendListCommands(ctx context.Context) (res *CommandListResponse
 	defer resp.Body.Close()
 
 	stage = "DecodeResponse"
-	result, err := decodeListCommandsResponse(resp)
+	result, err := decodeListCommandWebhooksResponse(resp)
+	if err != nil {
+		return res, errors.Wrap(err, "decode response")
+	}
+
+	return result, nil
+}
+
+// ListCommands invokes listCommands operation.
+//
+// Get a list of all FFmpeg commands with their current status.
+//
+// GET /v1/commands
+func (c *Client) ListCommands(ctx context.Context) (ListCommandsRes, error) {
+	res, err := c.sendListCommands(ctx)
+	return res, err
+}
+
+func (c *Client) sendListCommands(ctx context.Context) (res ListCommandsRes, err error) {
+	otelAttrs := []attribute.KeyValue{
+		otelogen.OperationID("listCommands"),
+		semconv.HTTPRequestMethodKey.String("GET"),
+		semconv.HTTPRouteKey.String("/v1/commands"),
+	}
+
+	// Run stopwatch.
+	startTime := time.Now()
+	defer func() {
+		// Use floating point division here for higher precision (instead of Millisecond method).
+		elapsedDuration := time.Since(startTime)
+		c.duration.Record(ctx, float64(elapsedDuration)/float64(time.Millisecond), metric.WithAttributes(otelAttrs...))
+	}()
+
+	// Increment request counter.
+	c.requests.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+
+	// Start a span for this request.
+	ctx, span := c.cfg.Tracer.Start(ctx, ListCommandsOperation,
+		trace.WithAttributes(otelAttrs...),
+		clientSpanKind,
+	)
+	// Track stage for error reporting.
+	var stage string
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, stage)
+			c.errors.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+		}
+		span.End()
+	}()
+
+	stage = "BuildURL"
+	u := uri.Clone(c.requestURL(ctx))
+	var pathParts [1]string
+	pathParts[0] = "/v1/commands"
+	uri.AddPathParts(u, pathParts[:]...)
+
+	stage = "EncodeRequest"
+	r, err := ht.NewRequest(ctx, "GET", u)
+	if err != nil {
+		return res, errors.Wrap(err, "create request")
+	}
+
+	stage = "SendRequest"
+	resp, err := c.cfg.Client.Do(r)
+	if err != nil {
+		return res, errors.Wrap(err, "do request")
+	}
+	defer resp.Body.Close()
+
+	stage = "DecodeResponse"
+	result, err := decodeListCommandsResponse(resp)
+	if err != nil {
+		return res, errors.Wrap(err, "decode response")
+	}
+
+	return result, nil
+}
+
+// ListDeadLetters invokes listDeadLetters operation.
+//
+// List webhook deliveries that exhausted their retries, newest failures first.
+//
+// GET /v1/admin/webhooks/dead-letters
+func (c *Client) ListDeadLetters(ctx context.Context, params ListDeadLettersParams) (ListDeadLettersRes, error) {
+	res, err := c.sendListDeadLetters(ctx, params)
+	return res, err
+}
+
+func (c *Client) sendListDeadLetters(ctx context.Context, params ListDeadLettersParams) (res ListDeadLettersRes, err error) {
+	otelAttrs := []attribute.KeyValue{
+		otelogen.OperationID("listDeadLetters"),
+		semconv.HTTPRequestMethodKey.String("GET"),
+		semconv.HTTPRouteKey.String("/v1/admin/webhooks/dead-letters"),
+	}
+
+	// Run stopwatch.
+	startTime := time.Now()
+	defer func() {
+		// Use floating point division here for higher precision (instead of Millisecond method).
+		elapsedDuration := time.Since(startTime)
+		c.duration.Record(ctx, float64(elapsedDuration)/float64(time.Millisecond), metric.WithAttributes(otelAttrs...))
+	}()
+
+	// Increment request counter.
+	c.requests.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+
+	// Start a span for this request.
+	ctx, span := c.cfg.Tracer.Start(ctx, ListDeadLettersOperation,
+		trace.WithAttributes(otelAttrs...),
+		clientSpanKind,
+	)
+	// Track stage for error reporting.
+	var stage string
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, stage)
+			c.errors.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+		}
+		span.End()
+	}()
+
+	stage = "BuildURL"
+	u := uri.Clone(c.requestURL(ctx))
+	var pathParts [1]string
+	pathParts[0] = "/v1/admin/webhooks/dead-letters"
+	uri.AddPathParts(u, pathParts[:]...)
+
+	stage = "EncodeQueryParams"
+	q := uri.NewQueryEncoder()
+	{
+		// Encode "command_id" parameter.
+		cfg := uri.QueryParameterEncodingConfig{
+			Name:    "command_id",
+			Style:   uri.QueryStyleForm,
+			Explode: true,
+		}
+
+		if err := q.EncodeParam(cfg, func(e uri.Encoder) error {
+			if val, ok := params.CommandID.Get(); ok {
+				return e.EncodeValue(conv.StringToString(val))
+			}
+			return nil
+		}); err != nil {
+			return res, errors.Wrap(err, "encode query")
+		}
+	}
+	{
+		// Encode "endpoint" parameter.
+		cfg := uri.QueryParameterEncodingConfig{
+			Name:    "endpoint",
+			Style:   uri.QueryStyleForm,
+			Explode: true,
+		}
+
+		if err := q.EncodeParam(cfg, func(e uri.Encoder) error {
+			if val, ok := params.Endpoint.Get(); ok {
+				return e.EncodeValue(conv.StringToString(val))
+			}
+			return nil
+		}); err != nil {
+			return res, errors.Wrap(err, "encode query")
+		}
+	}
+	{
+		// Encode "since" parameter.
+		cfg := uri.QueryParameterEncodingConfig{
+			Name:    "since",
+			Style:   uri.QueryStyleForm,
+			Explode: true,
+		}
+
+		if err := q.EncodeParam(cfg, func(e uri.Encoder) error {
+			if val, ok := params.Since.Get(); ok {
+				return e.EncodeValue(conv.DateTimeToString(val))
+			}
+			return nil
+		}); err != nil {
+			return res, errors.Wrap(err, "encode query")
+		}
+	}
+	{
+		// Encode "until" parameter.
+		cfg := uri.QueryParameterEncodingConfig{
+			Name:    "until",
+			Style:   uri.QueryStyleForm,
+			Explode: true,
+		}
+
+		if err := q.EncodeParam(cfg, func(e uri.Encoder) error {
+			if val, ok := params.Until.Get(); ok {
+				return e.EncodeValue(conv.DateTimeToString(val))
+			}
+			return nil
+		}); err != nil {
+			return res, errors.Wrap(err, "encode query")
+		}
+	}
+	{
+		// Encode "limit" parameter.
+		cfg := uri.QueryParameterEncodingConfig{
+			Name:    "limit",
+			Style:   uri.QueryStyleForm,
+			Explode: true,
+		}
+
+		if err := q.EncodeParam(cfg, func(e uri.Encoder) error {
+			if val, ok := params.Limit.Get(); ok {
+				return e.EncodeValue(conv.IntToString(val))
+			}
+			return nil
+		}); err != nil {
+			return res, errors.Wrap(err, "encode query")
+		}
+	}
+	u.RawQuery = q.Values().Encode()
+
+	stage = "EncodeRequest"
+	r, err := ht.NewRequest(ctx, "GET", u)
+	if err != nil {
+		return res, errors.Wrap(err, "create request")
+	}
+
+	stage = "SendRequest"
+	resp, err := c.cfg.Client.Do(r)
+	if err != nil {
+		return res, errors.Wrap(err, "do request")
+	}
+	defer resp.Body.Close()
+
+	stage = "DecodeResponse"
+	result, err := decodeListDeadLettersResponse(resp)
+	if err != nil {
+		return res, errors.Wrap(err, "decode response")
+	}
+
+	return result, nil
+}
+
+// ReplayDeadLetter invokes replayDeadLetter operation.
+//
+// Re-enqueue a dead-lettered delivery, optionally to a different URL.
+//
+// POST /v1/admin/webhooks/dead-letters/{task_id}/replay
+func (c *Client) ReplayDeadLetter(ctx context.Context, request OptDeadLetterReplayRequest, params ReplayDeadLetterParams) (ReplayDeadLetterRes, error) {
+	res, err := c.sendReplayDeadLetter(ctx, request, params)
+	return res, err
+}
+
+func (c *Client) sendReplayDeadLetter(ctx context.Context, request OptDeadLetterReplayRequest, params ReplayDeadLetterParams) (res ReplayDeadLetterRes, err error) {
+	otelAttrs := []attribute.KeyValue{
+		otelogen.OperationID("replayDeadLetter"),
+		semconv.HTTPRequestMethodKey.String("POST"),
+		semconv.HTTPRouteKey.String("/v1/admin/webhooks/dead-letters/{task_id}/replay"),
+	}
+
+	// Run stopwatch.
+	startTime := time.Now()
+	defer func() {
+		// Use floating point division here for higher precision (instead of Millisecond method).
+		elapsedDuration := time.Since(startTime)
+		c.duration.Record(ctx, float64(elapsedDuration)/float64(time.Millisecond), metric.WithAttributes(otelAttrs...))
+	}()
+
+	// Increment request counter.
+	c.requests.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+
+	// Start a span for this request.
+	ctx, span := c.cfg.Tracer.Start(ctx, ReplayDeadLetterOperation,
+		trace.WithAttributes(otelAttrs...),
+		clientSpanKind,
+	)
+	// Track stage for error reporting.
+	var stage string
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, stage)
+			c.errors.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+		}
+		span.End()
+	}()
+
+	stage = "BuildURL"
+	u := uri.Clone(c.requestURL(ctx))
+	var pathParts [3]string
+	pathParts[0] = "/v1/admin/webhooks/dead-letters/"
+	{
+		// Encode "task_id" parameter.
+		e := uri.NewPathEncoder(uri.PathEncoderConfig{
+			Param:   "task_id",
+			Style:   uri.PathStyleSimple,
+			Explode: false,
+		})
+		if err := func() error {
+			return e.EncodeValue(conv.StringToString(params.TaskID))
+		}(); err != nil {
+			return res, errors.Wrap(err, "encode path")
+		}
+		encoded, err := e.Result()
+		if err != nil {
+			return res, errors.Wrap(err, "encode path")
+		}
+		pathParts[1] = encoded
+	}
+	pathParts[2] = "/replay"
+	uri.AddPathParts(u, pathParts[:]...)
+
+	stage = "EncodeRequest"
+	r, err := ht.NewRequest(ctx, "POST", u)
+	if err != nil {
+		return res, errors.Wrap(err, "create request")
+	}
+	if err := encodeReplayDeadLetterRequest(request, r); err != nil {
+		return res, errors.Wrap(err, "encode request")
+	}
+
+	stage = "SendRequest"
+	resp, err := c.cfg.Client.Do(r)
+	if err != nil {
+		return res, errors.Wrap(err, "do request")
+	}
+	defer resp.Body.Close()
+
+	stage = "DecodeResponse"
+	result, err := decodeReplayDeadLetterResponse(resp)
+	if err != nil {
+		return res, errors.Wrap(err, "decode response")
+	}
+
+	return result, nil
+}
+
+// ReplayDeadLetters invokes replayDeadLetters operation.
+//
+// Re-enqueue every dead-lettered delivery matching the filter.
+//
+// POST /v1/admin/webhooks/dead-letters/replay
+func (c *Client) ReplayDeadLetters(ctx context.Context, request *DeadLetterBulkRequest) (ReplayDeadLettersRes, error) {
+	res, err := c.sendReplayDeadLetters(ctx, request)
+	return res, err
+}
+
+func (c *Client) sendReplayDeadLetters(ctx context.Context, request *DeadLetterBulkRequest) (res ReplayDeadLettersRes, err error) {
+	otelAttrs := []attribute.KeyValue{
+		otelogen.OperationID("replayDeadLetters"),
+		semconv.HTTPRequestMethodKey.String("POST"),
+		semconv.HTTPRouteKey.String("/v1/admin/webhooks/dead-letters/replay"),
+	}
+
+	// Run stopwatch.
+	startTime := time.Now()
+	defer func() {
+		// Use floating point division here for higher precision (instead of Millisecond method).
+		elapsedDuration := time.Since(startTime)
+		c.duration.Record(ctx, float64(elapsedDuration)/float64(time.Millisecond), metric.WithAttributes(otelAttrs...))
+	}()
+
+	// Increment request counter.
+	c.requests.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+
+	// Start a span for this request.
+	ctx, span := c.cfg.Tracer.Start(ctx, ReplayDeadLettersOperation,
+		trace.WithAttributes(otelAttrs...),
+		clientSpanKind,
+	)
+	// Track stage for error reporting.
+	var stage string
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, stage)
+			c.errors.Add(ctx, 1, metric.WithAttributes(otelAttrs...))
+		}
+		span.End()
+	}()
+
+	stage = "BuildURL"
+	u := uri.Clone(c.requestURL(ctx))
+	var pathParts [1]string
+	pathParts[0] = "/v1/admin/webhooks/dead-letters/replay"
+	uri.AddPathParts(u, pathParts[:]...)
+
+	stage = "EncodeRequest"
+	r, err := ht.NewRequest(ctx, "POST", u)
+	if err != nil {
+		return res, errors.Wrap(err, "create request")
+	}
+	if err := encodeReplayDeadLettersRequest(request, r); err != nil {
+		return res, errors.Wrap(err, "encode request")
+	}
+
+	stage = "SendRequest"
+	resp, err := c.cfg.Client.Do(r)
+	if err != nil {
+		return res, errors.Wrap(err, "do request")
+	}
+	defer resp.Body.Close()
+
+	stage = "DecodeResponse"
+	result, err := decodeReplayDeadLettersResponse(resp)
 	if err != nil {
 		return res, errors.Wrap(err, "decode response")
 	}
@@ -0,0 +1,15 @@
+// Code generated by ogen, DO NOT EDIT.
+
+package oas
+
+// setDefaults set default value of fields.
+func (s *InputFileSpec) setDefaults() {
+	{
+		val := InputFileSpecMode("download")
+		s.Mode.SetTo(val)
+	}
+	{
+		val := bool(false)
+		s.Extract.SetTo(val)
+	}
+}
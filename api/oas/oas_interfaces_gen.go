@@ -5,6 +5,38 @@ type CreateCommandRes interface {
 	createCommandRes()
 }
 
+type DeleteDeadLetterRes interface {
+	deleteDeadLetterRes()
+}
+
+type DeleteDeadLettersRes interface {
+	deleteDeadLettersRes()
+}
+
 type GetCommandRes interface {
 	getCommandRes()
 }
+
+type GetDeadLetterRes interface {
+	getDeadLetterRes()
+}
+
+type ListCommandWebhooksRes interface {
+	listCommandWebhooksRes()
+}
+
+type ListCommandsRes interface {
+	listCommandsRes()
+}
+
+type ListDeadLettersRes interface {
+	listDeadLettersRes()
+}
+
+type ReplayDeadLetterRes interface {
+	replayDeadLetterRes()
+}
+
+type ReplayDeadLettersRes interface {
+	replayDeadLettersRes()
+}
@@ -6,9 +6,16 @@ package oas
 type OperationName = string
 
 const (
-	CreateCommandOperation OperationName = "CreateCommand"
-	GetCommandOperation    OperationName = "GetCommand"
-	GetOpenAPIOperation    OperationName = "GetOpenAPI"
-	HealthCheckOperation   OperationName = "HealthCheck"
-	ListCommandsOperation  OperationName = "ListCommands"
+	CreateCommandOperation       OperationName = "CreateCommand"
+	DeleteDeadLetterOperation    OperationName = "DeleteDeadLetter"
+	DeleteDeadLettersOperation   OperationName = "DeleteDeadLetters"
+	GetCommandOperation          OperationName = "GetCommand"
+	GetDeadLetterOperation       OperationName = "GetDeadLetter"
+	GetOpenAPIOperation          OperationName = "GetOpenAPI"
+	HealthCheckOperation         OperationName = "HealthCheck"
+	ListCommandWebhooksOperation OperationName = "ListCommandWebhooks"
+	ListCommandsOperation        OperationName = "ListCommands"
+	ListDeadLettersOperation     OperationName = "ListDeadLetters"
+	ReplayDeadLetterOperation    OperationName = "ReplayDeadLetter"
+	ReplayDeadLettersOperation   OperationName = "ReplayDeadLetters"
 )
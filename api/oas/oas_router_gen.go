@@ -103,53 +103,225 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				}
 
 				elem = origElem
-			case 'v': // Prefix: "v1/commands"
+			case 'v': // Prefix: "v1/"
 				origElem := elem
-				if l := len("v1/commands"); len(elem) >= l && elem[0:l] == "v1/commands" {
+				if l := len("v1/"); len(elem) >= l && elem[0:l] == "v1/" {
 					elem = elem[l:]
 				} else {
 					break
 				}
 
 				if len(elem) == 0 {
-					switch r.Method {
-					case "GET":
-						s.handleListCommandsRequest([0]string{}, elemIsEscaped, w, r)
-					case "POST":
-						s.handleCreateCommandRequest([0]string{}, elemIsEscaped, w, r)
-					default:
-						s.notAllowed(w, r, "GET,POST")
-					}
-
-					return
+					break
 				}
 				switch elem[0] {
-				case '/': // Prefix: "/"
+				case 'a': // Prefix: "admin/webhooks/dead-letters"
 					origElem := elem
-					if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+					if l := len("admin/webhooks/dead-letters"); len(elem) >= l && elem[0:l] == "admin/webhooks/dead-letters" {
 						elem = elem[l:]
 					} else {
 						break
 					}
 
-					// Param: "id"
-					// Leaf parameter
-					args[0] = elem
-					elem = ""
-
 					if len(elem) == 0 {
-						// Leaf node.
 						switch r.Method {
 						case "GET":
-							s.handleGetCommandRequest([1]string{
-								args[0],
-							}, elemIsEscaped, w, r)
+							s.handleListDeadLettersRequest([0]string{}, elemIsEscaped, w, r)
 						default:
 							s.notAllowed(w, r, "GET")
 						}
 
 						return
 					}
+					switch elem[0] {
+					case '/': // Prefix: "/"
+						origElem := elem
+						if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							break
+						}
+						switch elem[0] {
+						case 'd': // Prefix: "delete"
+							origElem := elem
+							if l := len("delete"); len(elem) >= l && elem[0:l] == "delete" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch r.Method {
+								case "POST":
+									s.handleDeleteDeadLettersRequest([0]string{}, elemIsEscaped, w, r)
+								default:
+									s.notAllowed(w, r, "POST")
+								}
+
+								return
+							}
+
+							elem = origElem
+						case 'r': // Prefix: "replay"
+							origElem := elem
+							if l := len("replay"); len(elem) >= l && elem[0:l] == "replay" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch r.Method {
+								case "POST":
+									s.handleReplayDeadLettersRequest([0]string{}, elemIsEscaped, w, r)
+								default:
+									s.notAllowed(w, r, "POST")
+								}
+
+								return
+							}
+
+							elem = origElem
+						}
+						// Param: "task_id"
+						// Match until "/"
+						idx := strings.IndexByte(elem, '/')
+						if idx < 0 {
+							idx = len(elem)
+						}
+						args[0] = elem[:idx]
+						elem = elem[idx:]
+
+						if len(elem) == 0 {
+							switch r.Method {
+							case "DELETE":
+								s.handleDeleteDeadLetterRequest([1]string{
+									args[0],
+								}, elemIsEscaped, w, r)
+							case "GET":
+								s.handleGetDeadLetterRequest([1]string{
+									args[0],
+								}, elemIsEscaped, w, r)
+							default:
+								s.notAllowed(w, r, "DELETE,GET")
+							}
+
+							return
+						}
+						switch elem[0] {
+						case '/': // Prefix: "/replay"
+							origElem := elem
+							if l := len("/replay"); len(elem) >= l && elem[0:l] == "/replay" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch r.Method {
+								case "POST":
+									s.handleReplayDeadLetterRequest([1]string{
+										args[0],
+									}, elemIsEscaped, w, r)
+								default:
+									s.notAllowed(w, r, "POST")
+								}
+
+								return
+							}
+
+							elem = origElem
+						}
+
+						elem = origElem
+					}
+
+					elem = origElem
+				case 'c': // Prefix: "commands"
+					origElem := elem
+					if l := len("commands"); len(elem) >= l && elem[0:l] == "commands" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						switch r.Method {
+						case "GET":
+							s.handleListCommandsRequest([0]string{}, elemIsEscaped, w, r)
+						case "POST":
+							s.handleCreateCommandRequest([0]string{}, elemIsEscaped, w, r)
+						default:
+							s.notAllowed(w, r, "GET,POST")
+						}
+
+						return
+					}
+					switch elem[0] {
+					case '/': // Prefix: "/"
+						origElem := elem
+						if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						// Param: "id"
+						// Match until "/"
+						idx := strings.IndexByte(elem, '/')
+						if idx < 0 {
+							idx = len(elem)
+						}
+						args[0] = elem[:idx]
+						elem = elem[idx:]
+
+						if len(elem) == 0 {
+							switch r.Method {
+							case "GET":
+								s.handleGetCommandRequest([1]string{
+									args[0],
+								}, elemIsEscaped, w, r)
+							default:
+								s.notAllowed(w, r, "GET")
+							}
+
+							return
+						}
+						switch elem[0] {
+						case '/': // Prefix: "/webhooks"
+							origElem := elem
+							if l := len("/webhooks"); len(elem) >= l && elem[0:l] == "/webhooks" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch r.Method {
+								case "GET":
+									s.handleListCommandWebhooksRequest([1]string{
+										args[0],
+									}, elemIsEscaped, w, r)
+								default:
+									s.notAllowed(w, r, "GET")
+								}
+
+								return
+							}
+
+							elem = origElem
+						}
+
+						elem = origElem
+					}
 
 					elem = origElem
 				}
@@ -300,65 +472,259 @@ func (s *Server) FindPath(method string, u *url.URL) (r Route, _ bool) {
 				}
 
 				elem = origElem
-			case 'v': // Prefix: "v1/commands"
+			case 'v': // Prefix: "v1/"
 				origElem := elem
-				if l := len("v1/commands"); len(elem) >= l && elem[0:l] == "v1/commands" {
+				if l := len("v1/"); len(elem) >= l && elem[0:l] == "v1/" {
 					elem = elem[l:]
 				} else {
 					break
 				}
 
 				if len(elem) == 0 {
-					switch method {
-					case "GET":
-						r.name = ListCommandsOperation
-						r.summary = "List all commands"
-						r.operationID = "listCommands"
-						r.pathPattern = "/v1/commands"
-						r.args = args
-						r.count = 0
-						return r, true
-					case "POST":
-						r.name = CreateCommandOperation
-						r.summary = "Create a new command"
-						r.operationID = "createCommand"
-						r.pathPattern = "/v1/commands"
-						r.args = args
-						r.count = 0
-						return r, true
-					default:
-						return
-					}
+					break
 				}
 				switch elem[0] {
-				case '/': // Prefix: "/"
+				case 'a': // Prefix: "admin/webhooks/dead-letters"
 					origElem := elem
-					if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+					if l := len("admin/webhooks/dead-letters"); len(elem) >= l && elem[0:l] == "admin/webhooks/dead-letters" {
 						elem = elem[l:]
 					} else {
 						break
 					}
 
-					// Param: "id"
-					// Leaf parameter
-					args[0] = elem
-					elem = ""
+					if len(elem) == 0 {
+						switch method {
+						case "GET":
+							r.name = ListDeadLettersOperation
+							r.summary = "List dead-lettered webhooks"
+							r.operationID = "listDeadLetters"
+							r.pathPattern = "/v1/admin/webhooks/dead-letters"
+							r.args = args
+							r.count = 0
+							return r, true
+						default:
+							return
+						}
+					}
+					switch elem[0] {
+					case '/': // Prefix: "/"
+						origElem := elem
+						if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							break
+						}
+						switch elem[0] {
+						case 'd': // Prefix: "delete"
+							origElem := elem
+							if l := len("delete"); len(elem) >= l && elem[0:l] == "delete" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch method {
+								case "POST":
+									r.name = DeleteDeadLettersOperation
+									r.summary = "Delete dead-lettered webhooks in bulk"
+									r.operationID = "deleteDeadLetters"
+									r.pathPattern = "/v1/admin/webhooks/dead-letters/delete"
+									r.args = args
+									r.count = 0
+									return r, true
+								default:
+									return
+								}
+							}
+
+							elem = origElem
+						case 'r': // Prefix: "replay"
+							origElem := elem
+							if l := len("replay"); len(elem) >= l && elem[0:l] == "replay" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch method {
+								case "POST":
+									r.name = ReplayDeadLettersOperation
+									r.summary = "Replay dead-lettered webhooks in bulk"
+									r.operationID = "replayDeadLetters"
+									r.pathPattern = "/v1/admin/webhooks/dead-letters/replay"
+									r.args = args
+									r.count = 0
+									return r, true
+								default:
+									return
+								}
+							}
+
+							elem = origElem
+						}
+						// Param: "task_id"
+						// Match until "/"
+						idx := strings.IndexByte(elem, '/')
+						if idx < 0 {
+							idx = len(elem)
+						}
+						args[0] = elem[:idx]
+						elem = elem[idx:]
+
+						if len(elem) == 0 {
+							switch method {
+							case "DELETE":
+								r.name = DeleteDeadLetterOperation
+								r.summary = "Delete a dead-lettered webhook"
+								r.operationID = "deleteDeadLetter"
+								r.pathPattern = "/v1/admin/webhooks/dead-letters/{task_id}"
+								r.args = args
+								r.count = 1
+								return r, true
+							case "GET":
+								r.name = GetDeadLetterOperation
+								r.summary = "Get a dead-lettered webhook"
+								r.operationID = "getDeadLetter"
+								r.pathPattern = "/v1/admin/webhooks/dead-letters/{task_id}"
+								r.args = args
+								r.count = 1
+								return r, true
+							default:
+								return
+							}
+						}
+						switch elem[0] {
+						case '/': // Prefix: "/replay"
+							origElem := elem
+							if l := len("/replay"); len(elem) >= l && elem[0:l] == "/replay" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch method {
+								case "POST":
+									r.name = ReplayDeadLetterOperation
+									r.summary = "Replay a dead-lettered webhook"
+									r.operationID = "replayDeadLetter"
+									r.pathPattern = "/v1/admin/webhooks/dead-letters/{task_id}/replay"
+									r.args = args
+									r.count = 1
+									return r, true
+								default:
+									return
+								}
+							}
+
+							elem = origElem
+						}
+
+						elem = origElem
+					}
+
+					elem = origElem
+				case 'c': // Prefix: "commands"
+					origElem := elem
+					if l := len("commands"); len(elem) >= l && elem[0:l] == "commands" {
+						elem = elem[l:]
+					} else {
+						break
+					}
 
 					if len(elem) == 0 {
-						// Leaf node.
 						switch method {
 						case "GET":
-							r.name = GetCommandOperation
-							r.summary = "Get command by ID"
-							r.operationID = "getCommand"
-							r.pathPattern = "/v1/commands/{id}"
+							r.name = ListCommandsOperation
+							r.summary = "List all commands"
+							r.operationID = "listCommands"
+							r.pathPattern = "/v1/commands"
+							r.args = args
+							r.count = 0
+							return r, true
+						case "POST":
+							r.name = CreateCommandOperation
+							r.summary = "Create a new command"
+							r.operationID = "createCommand"
+							r.pathPattern = "/v1/commands"
 							r.args = args
-							r.count = 1
+							r.count = 0
 							return r, true
 						default:
 							return
 						}
 					}
+					switch elem[0] {
+					case '/': // Prefix: "/"
+						origElem := elem
+						if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						// Param: "id"
+						// Match until "/"
+						idx := strings.IndexByte(elem, '/')
+						if idx < 0 {
+							idx = len(elem)
+						}
+						args[0] = elem[:idx]
+						elem = elem[idx:]
+
+						if len(elem) == 0 {
+							switch method {
+							case "GET":
+								r.name = GetCommandOperation
+								r.summary = "Get command by ID"
+								r.operationID = "getCommand"
+								r.pathPattern = "/v1/commands/{id}"
+								r.args = args
+								r.count = 1
+								return r, true
+							default:
+								return
+							}
+						}
+						switch elem[0] {
+						case '/': // Prefix: "/webhooks"
+							origElem := elem
+							if l := len("/webhooks"); len(elem) >= l && elem[0:l] == "/webhooks" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch method {
+								case "GET":
+									r.name = ListCommandWebhooksOperation
+									r.summary = "List webhook deliveries for a command"
+									r.operationID = "listCommandWebhooks"
+									r.pathPattern = "/v1/commands/{id}/webhooks"
+									r.args = args
+									r.count = 1
+									return r, true
+								default:
+									return
+								}
+							}
+
+							elem = origElem
+						}
+
+						elem = origElem
+					}
 
 					elem = origElem
 				}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-faster/errors"
+	"github.com/go-faster/jx"
 )
 
 // Ref: #/components/schemas/CommandListResponse
@@ -37,20 +38,31 @@ func (s *CommandListResponse) SetTotal(val int) {
 	s.Total = val
 }
 
+func (*CommandListResponse) listCommandsRes() {}
+
 // Ref: #/components/schemas/CommandRequest
 type CommandRequest struct {
-	// Map of input file keys to URLs.
+	// Map of input file keys to URLs, or to objects with integrity checks. Keys are also file names in
+	// the job directory, so they may only contain letters, digits, `_` and `-`.
 	InputFiles OptCommandRequestInputFiles `json:"input_files"`
 	// Map of output file keys to filenames.
 	OutputFiles CommandRequestOutputFiles `json:"output_files"`
-	// Single FFmpeg command with {{placeholders}} for inputs/outputs.
+	// Single FFmpeg command with {{placeholders}} for inputs/outputs. Arguments are split like a POSIX
+	// shell would, with quotes and backslash escapes.
 	FfmpegCommand OptString `json:"ffmpeg_command"`
 	// Multiple FFmpeg commands to run in sequence.
 	FfmpegCommands []string `json:"ffmpeg_commands"`
-	// Webhook URL to POST results when complete.
+	// FFmpeg commands as argument lists, run in sequence without any parsing. Use instead of
+	// ffmpeg_command(s); placeholders are replaced within each argument.
+	FfmpegArgs [][]string `json:"ffmpeg_args"`
+	// Webhook URL to POST results when complete, or a sink URL such as `redis-stream://name`.
 	Webhook OptURI `json:"webhook"`
 	// Your custom reference ID for tracking.
 	ReferenceID OptString `json:"reference_id"`
+	// Resources the worker gives FFmpeg, such as threads, memory and CPU and I/O priority, and how much
+	// of the worker's budget the command takes. Estimated from the inputs' resolution and duration if
+	// omitted.
+	ResourceClass OptCommandRequestResourceClass `json:"resource_class"`
 }
 
 // GetInputFiles returns the value of InputFiles.
@@ -73,6 +85,11 @@ func (s *CommandRequest) GetFfmpegCommands() []string {
 	return s.FfmpegCommands
 }
 
+// GetFfmpegArgs returns the value of FfmpegArgs.
+func (s *CommandRequest) GetFfmpegArgs() [][]string {
+	return s.FfmpegArgs
+}
+
 // GetWebhook returns the value of Webhook.
 func (s *CommandRequest) GetWebhook() OptURI {
 	return s.Webhook
@@ -83,6 +100,11 @@ func (s *CommandRequest) GetReferenceID() OptString {
 	return s.ReferenceID
 }
 
+// GetResourceClass returns the value of ResourceClass.
+func (s *CommandRequest) GetResourceClass() OptCommandRequestResourceClass {
+	return s.ResourceClass
+}
+
 // SetInputFiles sets the value of InputFiles.
 func (s *CommandRequest) SetInputFiles(val OptCommandRequestInputFiles) {
 	s.InputFiles = val
@@ -103,6 +125,11 @@ func (s *CommandRequest) SetFfmpegCommands(val []string) {
 	s.FfmpegCommands = val
 }
 
+// SetFfmpegArgs sets the value of FfmpegArgs.
+func (s *CommandRequest) SetFfmpegArgs(val [][]string) {
+	s.FfmpegArgs = val
+}
+
 // SetWebhook sets the value of Webhook.
 func (s *CommandRequest) SetWebhook(val OptURI) {
 	s.Webhook = val
@@ -113,13 +140,19 @@ func (s *CommandRequest) SetReferenceID(val OptString) {
 	s.ReferenceID = val
 }
 
-// Map of input file keys to URLs.
-type CommandRequestInputFiles map[string]string
+// SetResourceClass sets the value of ResourceClass.
+func (s *CommandRequest) SetResourceClass(val OptCommandRequestResourceClass) {
+	s.ResourceClass = val
+}
+
+// Map of input file keys to URLs, or to objects with integrity checks. Keys are also file names in
+// the job directory, so they may only contain letters, digits, `_` and `-`.
+type CommandRequestInputFiles map[string]InputFile
 
 func (s *CommandRequestInputFiles) init() CommandRequestInputFiles {
 	m := *s
 	if m == nil {
-		m = map[string]string{}
+		m = map[string]InputFile{}
 		*s = m
 	}
 	return m
@@ -137,6 +170,57 @@ func (s *CommandRequestOutputFiles) init() CommandRequestOutputFiles {
 	return m
 }
 
+// Resources the worker gives FFmpeg, such as threads, memory and CPU and I/O priority, and how much
+// of the worker's budget the command takes. Estimated from the inputs' resolution and duration if
+// omitted.
+type CommandRequestResourceClass string
+
+const (
+	CommandRequestResourceClassSmall  CommandRequestResourceClass = "small"
+	CommandRequestResourceClassMedium CommandRequestResourceClass = "medium"
+	CommandRequestResourceClassLarge  CommandRequestResourceClass = "large"
+)
+
+// AllValues returns all CommandRequestResourceClass values.
+func (CommandRequestResourceClass) AllValues() []CommandRequestResourceClass {
+	return []CommandRequestResourceClass{
+		CommandRequestResourceClassSmall,
+		CommandRequestResourceClassMedium,
+		CommandRequestResourceClassLarge,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s CommandRequestResourceClass) MarshalText() ([]byte, error) {
+	switch s {
+	case CommandRequestResourceClassSmall:
+		return []byte(s), nil
+	case CommandRequestResourceClassMedium:
+		return []byte(s), nil
+	case CommandRequestResourceClassLarge:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *CommandRequestResourceClass) UnmarshalText(data []byte) error {
+	switch CommandRequestResourceClass(data) {
+	case CommandRequestResourceClassSmall:
+		*s = CommandRequestResourceClassSmall
+		return nil
+	case CommandRequestResourceClassMedium:
+		*s = CommandRequestResourceClassMedium
+		return nil
+	case CommandRequestResourceClassLarge:
+		*s = CommandRequestResourceClassLarge
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
 // Ref: #/components/schemas/CommandResponse
 type CommandResponse struct {
 	// Unique identifier for the command.
@@ -255,12 +339,19 @@ type CommandStatus struct {
 	FfmpegCommandRunSeconds OptFloat64 `json:"ffmpeg_command_run_seconds"`
 	// Total processing time including downloads.
 	TotalProcessingSeconds OptFloat64 `json:"total_processing_seconds"`
+	// Wall-clock time spent downloading inputs.
+	DownloadSeconds OptFloat64 `json:"download_seconds"`
+	// Total size of the downloaded inputs.
+	DownloadBytes OptInt64 `json:"download_bytes"`
+	// Overall input download throughput.
+	DownloadMbytesPerSecond OptFloat64 `json:"download_mbytes_per_second"`
 	// Error message if command failed.
 	Error OptString `json:"error"`
 	// When the command was created.
 	CreatedAt time.Time `json:"created_at"`
 	// When the command completed.
-	CompletedAt OptDateTime `json:"completed_at"`
+	CompletedAt   OptDateTime      `json:"completed_at"`
+	WebhookStatus OptWebhookStatus `json:"webhook_status"`
 }
 
 // GetCommandID returns the value of CommandID.
@@ -293,6 +384,21 @@ func (s *CommandStatus) GetTotalProcessingSeconds() OptFloat64 {
 	return s.TotalProcessingSeconds
 }
 
+// GetDownloadSeconds returns the value of DownloadSeconds.
+func (s *CommandStatus) GetDownloadSeconds() OptFloat64 {
+	return s.DownloadSeconds
+}
+
+// GetDownloadBytes returns the value of DownloadBytes.
+func (s *CommandStatus) GetDownloadBytes() OptInt64 {
+	return s.DownloadBytes
+}
+
+// GetDownloadMbytesPerSecond returns the value of DownloadMbytesPerSecond.
+func (s *CommandStatus) GetDownloadMbytesPerSecond() OptFloat64 {
+	return s.DownloadMbytesPerSecond
+}
+
 // GetError returns the value of Error.
 func (s *CommandStatus) GetError() OptString {
 	return s.Error
@@ -308,6 +414,11 @@ func (s *CommandStatus) GetCompletedAt() OptDateTime {
 	return s.CompletedAt
 }
 
+// GetWebhookStatus returns the value of WebhookStatus.
+func (s *CommandStatus) GetWebhookStatus() OptWebhookStatus {
+	return s.WebhookStatus
+}
+
 // SetCommandID sets the value of CommandID.
 func (s *CommandStatus) SetCommandID(val string) {
 	s.CommandID = val
@@ -338,6 +449,21 @@ func (s *CommandStatus) SetTotalProcessingSeconds(val OptFloat64) {
 	s.TotalProcessingSeconds = val
 }
 
+// SetDownloadSeconds sets the value of DownloadSeconds.
+func (s *CommandStatus) SetDownloadSeconds(val OptFloat64) {
+	s.DownloadSeconds = val
+}
+
+// SetDownloadBytes sets the value of DownloadBytes.
+func (s *CommandStatus) SetDownloadBytes(val OptInt64) {
+	s.DownloadBytes = val
+}
+
+// SetDownloadMbytesPerSecond sets the value of DownloadMbytesPerSecond.
+func (s *CommandStatus) SetDownloadMbytesPerSecond(val OptFloat64) {
+	s.DownloadMbytesPerSecond = val
+}
+
 // SetError sets the value of Error.
 func (s *CommandStatus) SetError(val OptString) {
 	s.Error = val
@@ -353,6 +479,11 @@ func (s *CommandStatus) SetCompletedAt(val OptDateTime) {
 	s.CompletedAt = val
 }
 
+// SetWebhookStatus sets the value of WebhookStatus.
+func (s *CommandStatus) SetWebhookStatus(val OptWebhookStatus) {
+	s.WebhookStatus = val
+}
+
 func (*CommandStatus) getCommandRes() {}
 
 // Map of output files with their metadata.
@@ -438,264 +569,1150 @@ type CreateCommandInternalServerError ErrorResponse
 
 func (*CreateCommandInternalServerError) createCommandRes() {}
 
-// Ref: #/components/schemas/ErrorResponse
-type ErrorResponse struct {
-	// Error message.
-	Error string `json:"error"`
+// Ref: #/components/schemas/DeadLetter
+type DeadLetter struct {
+	// Identifier of the dead-lettered delivery task.
+	TaskID string `json:"task_id"`
+	// Command the delivery belongs to.
+	CommandID string `json:"command_id"`
+	// Destination URL.
+	URL string `json:"url"`
+	// Command status the delivery carries.
+	Event string `json:"event"`
+	// Number of retries made before dead-lettering.
+	Retried int `json:"retried"`
+	// Error from the final attempt.
+	LastError OptString `json:"last_error"`
+	// When the final attempt failed.
+	LastFailedAt OptDateTime `json:"last_failed_at"`
+	// Webhook body (only returned when fetching a single delivery).
+	Payload OptDeadLetterPayload `json:"payload"`
 }
 
-// GetError returns the value of Error.
-func (s *ErrorResponse) GetError() string {
-	return s.Error
+// GetTaskID returns the value of TaskID.
+func (s *DeadLetter) GetTaskID() string {
+	return s.TaskID
 }
 
-// SetError sets the value of Error.
-func (s *ErrorResponse) SetError(val string) {
-	s.Error = val
+// GetCommandID returns the value of CommandID.
+func (s *DeadLetter) GetCommandID() string {
+	return s.CommandID
 }
 
-type GetCommandBadRequest ErrorResponse
+// GetURL returns the value of URL.
+func (s *DeadLetter) GetURL() string {
+	return s.URL
+}
 
-func (*GetCommandBadRequest) getCommandRes() {}
+// GetEvent returns the value of Event.
+func (s *DeadLetter) GetEvent() string {
+	return s.Event
+}
 
-type GetCommandNotFound ErrorResponse
+// GetRetried returns the value of Retried.
+func (s *DeadLetter) GetRetried() int {
+	return s.Retried
+}
 
-func (*GetCommandNotFound) getCommandRes() {}
+// GetLastError returns the value of LastError.
+func (s *DeadLetter) GetLastError() OptString {
+	return s.LastError
+}
 
-type GetOpenAPIOK struct{}
+// GetLastFailedAt returns the value of LastFailedAt.
+func (s *DeadLetter) GetLastFailedAt() OptDateTime {
+	return s.LastFailedAt
+}
 
-// Ref: #/components/schemas/HealthResponse
-type HealthResponse struct {
-	// Status of the service.
-	Status string `json:"status"`
+// GetPayload returns the value of Payload.
+func (s *DeadLetter) GetPayload() OptDeadLetterPayload {
+	return s.Payload
 }
 
-// GetStatus returns the value of Status.
-func (s *HealthResponse) GetStatus() string {
-	return s.Status
+// SetTaskID sets the value of TaskID.
+func (s *DeadLetter) SetTaskID(val string) {
+	s.TaskID = val
 }
 
-// SetStatus sets the value of Status.
-func (s *HealthResponse) SetStatus(val string) {
-	s.Status = val
+// SetCommandID sets the value of CommandID.
+func (s *DeadLetter) SetCommandID(val string) {
+	s.CommandID = val
 }
 
-// NewOptCommandRequest returns new OptCommandRequest with value set to v.
-func NewOptCommandRequest(v CommandRequest) OptCommandRequest {
-	return OptCommandRequest{
-		Value: v,
-		Set:   true,
-	}
+// SetURL sets the value of URL.
+func (s *DeadLetter) SetURL(val string) {
+	s.URL = val
 }
 
-// OptCommandRequest is optional CommandRequest.
-type OptCommandRequest struct {
-	Value CommandRequest
-	Set   bool
+// SetEvent sets the value of Event.
+func (s *DeadLetter) SetEvent(val string) {
+	s.Event = val
 }
 
-// IsSet returns true if OptCommandRequest was set.
-func (o OptCommandRequest) IsSet() bool { return o.Set }
+// SetRetried sets the value of Retried.
+func (s *DeadLetter) SetRetried(val int) {
+	s.Retried = val
+}
 
-// Reset unsets value.
-func (o *OptCommandRequest) Reset() {
-	var v CommandRequest
-	o.Value = v
-	o.Set = false
+// SetLastError sets the value of LastError.
+func (s *DeadLetter) SetLastError(val OptString) {
+	s.LastError = val
 }
 
-// SetTo sets value to v.
-func (o *OptCommandRequest) SetTo(v CommandRequest) {
-	o.Set = true
-	o.Value = v
+// SetLastFailedAt sets the value of LastFailedAt.
+func (s *DeadLetter) SetLastFailedAt(val OptDateTime) {
+	s.LastFailedAt = val
 }
 
-// Get returns value and boolean that denotes whether value was set.
-func (o OptCommandRequest) Get() (v CommandRequest, ok bool) {
-	if !o.Set {
-		return v, false
-	}
-	return o.Value, true
+// SetPayload sets the value of Payload.
+func (s *DeadLetter) SetPayload(val OptDeadLetterPayload) {
+	s.Payload = val
 }
 
-// Or returns value if set, or given parameter if does not.
-func (o OptCommandRequest) Or(d CommandRequest) CommandRequest {
-	if v, ok := o.Get(); ok {
-		return v
-	}
-	return d
+func (*DeadLetter) getDeadLetterRes() {}
+
+// Ref: #/components/schemas/DeadLetterActionResponse
+type DeadLetterActionResponse struct {
+	// Number of deliveries affected.
+	Count int `json:"count"`
+	// IDs of the new tasks delivering the replayed webhooks.
+	TaskIds []string `json:"task_ids"`
 }
 
-// NewOptCommandRequestInputFiles returns new OptCommandRequestInputFiles with value set to v.
-func NewOptCommandRequestInputFiles(v CommandRequestInputFiles) OptCommandRequestInputFiles {
-	return OptCommandRequestInputFiles{
-		Value: v,
-		Set:   true,
-	}
+// GetCount returns the value of Count.
+func (s *DeadLetterActionResponse) GetCount() int {
+	return s.Count
 }
 
-// OptCommandRequestInputFiles is optional CommandRequestInputFiles.
-type OptCommandRequestInputFiles struct {
-	Value CommandRequestInputFiles
-	Set   bool
+// GetTaskIds returns the value of TaskIds.
+func (s *DeadLetterActionResponse) GetTaskIds() []string {
+	return s.TaskIds
 }
 
-// IsSet returns true if OptCommandRequestInputFiles was set.
-func (o OptCommandRequestInputFiles) IsSet() bool { return o.Set }
+// SetCount sets the value of Count.
+func (s *DeadLetterActionResponse) SetCount(val int) {
+	s.Count = val
+}
 
-// Reset unsets value.
-func (o *OptCommandRequestInputFiles) Reset() {
-	var v CommandRequestInputFiles
-	o.Value = v
-	o.Set = false
+// SetTaskIds sets the value of TaskIds.
+func (s *DeadLetterActionResponse) SetTaskIds(val []string) {
+	s.TaskIds = val
 }
 
-// SetTo sets value to v.
-func (o *OptCommandRequestInputFiles) SetTo(v CommandRequestInputFiles) {
-	o.Set = true
-	o.Value = v
+func (*DeadLetterActionResponse) deleteDeadLettersRes() {}
+func (*DeadLetterActionResponse) replayDeadLetterRes()  {}
+func (*DeadLetterActionResponse) replayDeadLettersRes() {}
+
+// Filter selecting dead-lettered deliveries. At least one of command_id, endpoint, since or until is
+// required.
+// Ref: #/components/schemas/DeadLetterBulkRequest
+type DeadLetterBulkRequest struct {
+	// Only match deliveries for this command.
+	CommandID OptString `json:"command_id"`
+	// Only match deliveries to this URL or below it, with the same scheme, host and port, and a path at
+	// or under its path.
+	Endpoint OptString `json:"endpoint"`
+	// Only match deliveries that failed at or after this time.
+	Since OptDateTime `json:"since"`
+	// Only match deliveries that failed before this time.
+	Until OptDateTime `json:"until"`
+	// When replaying, deliver to this URL instead of the original one.
+	URL OptURI `json:"url"`
 }
 
-// Get returns value and boolean that denotes whether value was set.
-func (o OptCommandRequestInputFiles) Get() (v CommandRequestInputFiles, ok bool) {
-	if !o.Set {
-		return v, false
-	}
-	return o.Value, true
+// GetCommandID returns the value of CommandID.
+func (s *DeadLetterBulkRequest) GetCommandID() OptString {
+	return s.CommandID
 }
 
-// Or returns value if set, or given parameter if does not.
-func (o OptCommandRequestInputFiles) Or(d CommandRequestInputFiles) CommandRequestInputFiles {
-	if v, ok := o.Get(); ok {
-		return v
-	}
-	return d
+// GetEndpoint returns the value of Endpoint.
+func (s *DeadLetterBulkRequest) GetEndpoint() OptString {
+	return s.Endpoint
 }
 
-// NewOptCommandStatusOutputFiles returns new OptCommandStatusOutputFiles with value set to v.
-func NewOptCommandStatusOutputFiles(v CommandStatusOutputFiles) OptCommandStatusOutputFiles {
-	return OptCommandStatusOutputFiles{
-		Value: v,
-		Set:   true,
-	}
+// GetSince returns the value of Since.
+func (s *DeadLetterBulkRequest) GetSince() OptDateTime {
+	return s.Since
 }
 
-// OptCommandStatusOutputFiles is optional CommandStatusOutputFiles.
-type OptCommandStatusOutputFiles struct {
-	Value CommandStatusOutputFiles
-	Set   bool
+// GetUntil returns the value of Until.
+func (s *DeadLetterBulkRequest) GetUntil() OptDateTime {
+	return s.Until
 }
 
-// IsSet returns true if OptCommandStatusOutputFiles was set.
-func (o OptCommandStatusOutputFiles) IsSet() bool { return o.Set }
+// GetURL returns the value of URL.
+func (s *DeadLetterBulkRequest) GetURL() OptURI {
+	return s.URL
+}
 
-// Reset unsets value.
-func (o *OptCommandStatusOutputFiles) Reset() {
-	var v CommandStatusOutputFiles
-	o.Value = v
-	o.Set = false
+// SetCommandID sets the value of CommandID.
+func (s *DeadLetterBulkRequest) SetCommandID(val OptString) {
+	s.CommandID = val
 }
 
-// SetTo sets value to v.
-func (o *OptCommandStatusOutputFiles) SetTo(v CommandStatusOutputFiles) {
-	o.Set = true
-	o.Value = v
+// SetEndpoint sets the value of Endpoint.
+func (s *DeadLetterBulkRequest) SetEndpoint(val OptString) {
+	s.Endpoint = val
 }
 
-// Get returns value and boolean that denotes whether value was set.
-func (o OptCommandStatusOutputFiles) Get() (v CommandStatusOutputFiles, ok bool) {
-	if !o.Set {
-		return v, false
-	}
-	return o.Value, true
+// SetSince sets the value of Since.
+func (s *DeadLetterBulkRequest) SetSince(val OptDateTime) {
+	s.Since = val
 }
 
-// Or returns value if set, or given parameter if does not.
-func (o OptCommandStatusOutputFiles) Or(d CommandStatusOutputFiles) CommandStatusOutputFiles {
-	if v, ok := o.Get(); ok {
-		return v
-	}
-	return d
+// SetUntil sets the value of Until.
+func (s *DeadLetterBulkRequest) SetUntil(val OptDateTime) {
+	s.Until = val
 }
 
-// NewOptDateTime returns new OptDateTime with value set to v.
-func NewOptDateTime(v time.Time) OptDateTime {
-	return OptDateTime{
-		Value: v,
-		Set:   true,
-	}
+// SetURL sets the value of URL.
+func (s *DeadLetterBulkRequest) SetURL(val OptURI) {
+	s.URL = val
 }
 
-// OptDateTime is optional time.Time.
-type OptDateTime struct {
-	Value time.Time
-	Set   bool
+// Ref: #/components/schemas/DeadLetterListResponse
+type DeadLetterListResponse struct {
+	// Matching dead-lettered deliveries.
+	DeadLetters []DeadLetter `json:"dead_letters"`
+	// Number of deliveries returned.
+	Total int `json:"total"`
 }
 
-// IsSet returns true if OptDateTime was set.
-func (o OptDateTime) IsSet() bool { return o.Set }
+// GetDeadLetters returns the value of DeadLetters.
+func (s *DeadLetterListResponse) GetDeadLetters() []DeadLetter {
+	return s.DeadLetters
+}
 
-// Reset unsets value.
-func (o *OptDateTime) Reset() {
-	var v time.Time
-	o.Value = v
-	o.Set = false
+// GetTotal returns the value of Total.
+func (s *DeadLetterListResponse) GetTotal() int {
+	return s.Total
 }
 
-// SetTo sets value to v.
-func (o *OptDateTime) SetTo(v time.Time) {
-	o.Set = true
-	o.Value = v
+// SetDeadLetters sets the value of DeadLetters.
+func (s *DeadLetterListResponse) SetDeadLetters(val []DeadLetter) {
+	s.DeadLetters = val
 }
 
-// Get returns value and boolean that denotes whether value was set.
-func (o OptDateTime) Get() (v time.Time, ok bool) {
-	if !o.Set {
-		return v, false
-	}
-	return o.Value, true
+// SetTotal sets the value of Total.
+func (s *DeadLetterListResponse) SetTotal(val int) {
+	s.Total = val
 }
 
-// Or returns value if set, or given parameter if does not.
-func (o OptDateTime) Or(d time.Time) time.Time {
-	if v, ok := o.Get(); ok {
-		return v
+func (*DeadLetterListResponse) listDeadLettersRes() {}
+
+// Webhook body (only returned when fetching a single delivery).
+type DeadLetterPayload map[string]jx.Raw
+
+func (s *DeadLetterPayload) init() DeadLetterPayload {
+	m := *s
+	if m == nil {
+		m = map[string]jx.Raw{}
+		*s = m
 	}
-	return d
+	return m
 }
 
-// NewOptFloat64 returns new OptFloat64 with value set to v.
-func NewOptFloat64(v float64) OptFloat64 {
-	return OptFloat64{
-		Value: v,
-		Set:   true,
-	}
+// Ref: #/components/schemas/DeadLetterReplayRequest
+type DeadLetterReplayRequest struct {
+	// Deliver to this URL instead of the original one.
+	URL OptURI `json:"url"`
 }
 
-// OptFloat64 is optional float64.
-type OptFloat64 struct {
-	Value float64
-	Set   bool
+// GetURL returns the value of URL.
+func (s *DeadLetterReplayRequest) GetURL() OptURI {
+	return s.URL
 }
 
-// IsSet returns true if OptFloat64 was set.
-func (o OptFloat64) IsSet() bool { return o.Set }
+// SetURL sets the value of URL.
+func (s *DeadLetterReplayRequest) SetURL(val OptURI) {
+	s.URL = val
+}
 
-// Reset unsets value.
-func (o *OptFloat64) Reset() {
-	var v float64
-	o.Value = v
+// DeleteDeadLetterNoContent is response for DeleteDeadLetter operation.
+type DeleteDeadLetterNoContent struct{}
+
+func (*DeleteDeadLetterNoContent) deleteDeadLetterRes() {}
+
+type DeleteDeadLettersBadRequest ErrorResponse
+
+func (*DeleteDeadLettersBadRequest) deleteDeadLettersRes() {}
+
+type DeleteDeadLettersInternalServerError ErrorResponse
+
+func (*DeleteDeadLettersInternalServerError) deleteDeadLettersRes() {}
+
+// Ref: #/components/schemas/ErrorResponse
+type ErrorResponse struct {
+	// Error message.
+	Error string `json:"error"`
+}
+
+// GetError returns the value of Error.
+func (s *ErrorResponse) GetError() string {
+	return s.Error
+}
+
+// SetError sets the value of Error.
+func (s *ErrorResponse) SetError(val string) {
+	s.Error = val
+}
+
+func (*ErrorResponse) deleteDeadLetterRes() {}
+func (*ErrorResponse) getDeadLetterRes()    {}
+func (*ErrorResponse) listCommandsRes()     {}
+
+type GetCommandBadRequest ErrorResponse
+
+func (*GetCommandBadRequest) getCommandRes() {}
+
+type GetCommandInternalServerError ErrorResponse
+
+func (*GetCommandInternalServerError) getCommandRes() {}
+
+type GetCommandNotFound ErrorResponse
+
+func (*GetCommandNotFound) getCommandRes() {}
+
+type GetOpenAPIOK struct{}
+
+// Ref: #/components/schemas/HealthResponse
+type HealthResponse struct {
+	// Status of the service.
+	Status string `json:"status"`
+}
+
+// GetStatus returns the value of Status.
+func (s *HealthResponse) GetStatus() string {
+	return s.Status
+}
+
+// SetStatus sets the value of Status.
+func (s *HealthResponse) SetStatus(val string) {
+	s.Status = val
+}
+
+// Ref: #/components/schemas/InputFile
+// InputFile represents sum type.
+type InputFile struct {
+	Type          InputFileType // switch on this field
+	String        string
+	InputFileSpec InputFileSpec
+}
+
+// InputFileType is oneOf type of InputFile.
+type InputFileType string
+
+// Possible values for InputFileType.
+const (
+	StringInputFile        InputFileType = "string"
+	InputFileSpecInputFile InputFileType = "InputFileSpec"
+)
+
+// IsString reports whether InputFile is string.
+func (s InputFile) IsString() bool { return s.Type == StringInputFile }
+
+// IsInputFileSpec reports whether InputFile is InputFileSpec.
+func (s InputFile) IsInputFileSpec() bool { return s.Type == InputFileSpecInputFile }
+
+// SetString sets InputFile to string.
+func (s *InputFile) SetString(v string) {
+	s.Type = StringInputFile
+	s.String = v
+}
+
+// GetString returns string and true boolean if InputFile is string.
+func (s InputFile) GetString() (v string, ok bool) {
+	if !s.IsString() {
+		return v, false
+	}
+	return s.String, true
+}
+
+// NewStringInputFile returns new InputFile from string.
+func NewStringInputFile(v string) InputFile {
+	var s InputFile
+	s.SetString(v)
+	return s
+}
+
+// SetInputFileSpec sets InputFile to InputFileSpec.
+func (s *InputFile) SetInputFileSpec(v InputFileSpec) {
+	s.Type = InputFileSpecInputFile
+	s.InputFileSpec = v
+}
+
+// GetInputFileSpec returns InputFileSpec and true boolean if InputFile is InputFileSpec.
+func (s InputFile) GetInputFileSpec() (v InputFileSpec, ok bool) {
+	if !s.IsInputFileSpec() {
+		return v, false
+	}
+	return s.InputFileSpec, true
+}
+
+// NewInputFileSpecInputFile returns new InputFile from InputFileSpec.
+func NewInputFileSpecInputFile(v InputFileSpec) InputFile {
+	var s InputFile
+	s.SetInputFileSpec(v)
+	return s
+}
+
+// An input to download from `url`, or a small text file given inline with `content`.
+// Ref: #/components/schemas/InputFileSpec
+type InputFileSpec struct {
+	// URL to download, or a `data:` URI holding the file.
+	URL OptString `json:"url"`
+	// Inline file content, such as a concat list or subtitles. Placeholders for other inputs are
+	// replaced with their file names.
+	Content OptString `json:"content"`
+	// File name for inline content. Its extension is kept, which helps FFmpeg pick a demuxer.
+	Filename OptString `json:"filename"`
+	// Expected SHA-256 of the file, checked after download.
+	SHA256 OptString `json:"sha256"`
+	// Abort the download once it exceeds this size. The worker's own limit still applies.
+	MaxBytes OptInt64 `json:"max_bytes"`
+	// Reject the input unless ffprobe finds this kind of media.
+	Expect OptInputFileSpecExpect `json:"expect"`
+	// Extra request headers for HTTP(S) inputs. Values are redacted in status responses and webhooks.
+	Headers OptInputFileSpecHeaders `json:"headers"`
+	// Name of a credential profile configured on the worker.
+	Auth OptString `json:"auth"`
+	// Download the whole input before running FFmpeg, or let FFmpeg read only the parts it needs.
+	// Streaming falls back to downloading if it fails.
+	Mode OptInputFileSpecMode `json:"mode"`
+	// File extension to save the input with. By default it is taken from the URL path, then the
+	// response's Content-Disposition or Content-Type, then by probing the file.
+	Extension OptString `json:"extension"`
+	// Unpack a zip, tar or tar.gz archive into a directory. The placeholder expands to the directory, e.
+	// g. `{{in_1}}/frame_%05d.png`.
+	Extract OptBool `json:"extract"`
+}
+
+// GetURL returns the value of URL.
+func (s *InputFileSpec) GetURL() OptString {
+	return s.URL
+}
+
+// GetContent returns the value of Content.
+func (s *InputFileSpec) GetContent() OptString {
+	return s.Content
+}
+
+// GetFilename returns the value of Filename.
+func (s *InputFileSpec) GetFilename() OptString {
+	return s.Filename
+}
+
+// GetSHA256 returns the value of SHA256.
+func (s *InputFileSpec) GetSHA256() OptString {
+	return s.SHA256
+}
+
+// GetMaxBytes returns the value of MaxBytes.
+func (s *InputFileSpec) GetMaxBytes() OptInt64 {
+	return s.MaxBytes
+}
+
+// GetExpect returns the value of Expect.
+func (s *InputFileSpec) GetExpect() OptInputFileSpecExpect {
+	return s.Expect
+}
+
+// GetHeaders returns the value of Headers.
+func (s *InputFileSpec) GetHeaders() OptInputFileSpecHeaders {
+	return s.Headers
+}
+
+// GetAuth returns the value of Auth.
+func (s *InputFileSpec) GetAuth() OptString {
+	return s.Auth
+}
+
+// GetMode returns the value of Mode.
+func (s *InputFileSpec) GetMode() OptInputFileSpecMode {
+	return s.Mode
+}
+
+// GetExtension returns the value of Extension.
+func (s *InputFileSpec) GetExtension() OptString {
+	return s.Extension
+}
+
+// GetExtract returns the value of Extract.
+func (s *InputFileSpec) GetExtract() OptBool {
+	return s.Extract
+}
+
+// SetURL sets the value of URL.
+func (s *InputFileSpec) SetURL(val OptString) {
+	s.URL = val
+}
+
+// SetContent sets the value of Content.
+func (s *InputFileSpec) SetContent(val OptString) {
+	s.Content = val
+}
+
+// SetFilename sets the value of Filename.
+func (s *InputFileSpec) SetFilename(val OptString) {
+	s.Filename = val
+}
+
+// SetSHA256 sets the value of SHA256.
+func (s *InputFileSpec) SetSHA256(val OptString) {
+	s.SHA256 = val
+}
+
+// SetMaxBytes sets the value of MaxBytes.
+func (s *InputFileSpec) SetMaxBytes(val OptInt64) {
+	s.MaxBytes = val
+}
+
+// SetExpect sets the value of Expect.
+func (s *InputFileSpec) SetExpect(val OptInputFileSpecExpect) {
+	s.Expect = val
+}
+
+// SetHeaders sets the value of Headers.
+func (s *InputFileSpec) SetHeaders(val OptInputFileSpecHeaders) {
+	s.Headers = val
+}
+
+// SetAuth sets the value of Auth.
+func (s *InputFileSpec) SetAuth(val OptString) {
+	s.Auth = val
+}
+
+// SetMode sets the value of Mode.
+func (s *InputFileSpec) SetMode(val OptInputFileSpecMode) {
+	s.Mode = val
+}
+
+// SetExtension sets the value of Extension.
+func (s *InputFileSpec) SetExtension(val OptString) {
+	s.Extension = val
+}
+
+// SetExtract sets the value of Extract.
+func (s *InputFileSpec) SetExtract(val OptBool) {
+	s.Extract = val
+}
+
+// Reject the input unless ffprobe finds this kind of media.
+type InputFileSpecExpect string
+
+const (
+	InputFileSpecExpectVideo InputFileSpecExpect = "video"
+	InputFileSpecExpectAudio InputFileSpecExpect = "audio"
+	InputFileSpecExpectImage InputFileSpecExpect = "image"
+)
+
+// AllValues returns all InputFileSpecExpect values.
+func (InputFileSpecExpect) AllValues() []InputFileSpecExpect {
+	return []InputFileSpecExpect{
+		InputFileSpecExpectVideo,
+		InputFileSpecExpectAudio,
+		InputFileSpecExpectImage,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s InputFileSpecExpect) MarshalText() ([]byte, error) {
+	switch s {
+	case InputFileSpecExpectVideo:
+		return []byte(s), nil
+	case InputFileSpecExpectAudio:
+		return []byte(s), nil
+	case InputFileSpecExpectImage:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *InputFileSpecExpect) UnmarshalText(data []byte) error {
+	switch InputFileSpecExpect(data) {
+	case InputFileSpecExpectVideo:
+		*s = InputFileSpecExpectVideo
+		return nil
+	case InputFileSpecExpectAudio:
+		*s = InputFileSpecExpectAudio
+		return nil
+	case InputFileSpecExpectImage:
+		*s = InputFileSpecExpectImage
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+// Extra request headers for HTTP(S) inputs. Values are redacted in status responses and webhooks.
+type InputFileSpecHeaders map[string]string
+
+func (s *InputFileSpecHeaders) init() InputFileSpecHeaders {
+	m := *s
+	if m == nil {
+		m = map[string]string{}
+		*s = m
+	}
+	return m
+}
+
+// Download the whole input before running FFmpeg, or let FFmpeg read only the parts it needs.
+// Streaming falls back to downloading if it fails.
+type InputFileSpecMode string
+
+const (
+	InputFileSpecModeDownload InputFileSpecMode = "download"
+	InputFileSpecModeStream   InputFileSpecMode = "stream"
+)
+
+// AllValues returns all InputFileSpecMode values.
+func (InputFileSpecMode) AllValues() []InputFileSpecMode {
+	return []InputFileSpecMode{
+		InputFileSpecModeDownload,
+		InputFileSpecModeStream,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s InputFileSpecMode) MarshalText() ([]byte, error) {
+	switch s {
+	case InputFileSpecModeDownload:
+		return []byte(s), nil
+	case InputFileSpecModeStream:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *InputFileSpecMode) UnmarshalText(data []byte) error {
+	switch InputFileSpecMode(data) {
+	case InputFileSpecModeDownload:
+		*s = InputFileSpecModeDownload
+		return nil
+	case InputFileSpecModeStream:
+		*s = InputFileSpecModeStream
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+type ListCommandWebhooksBadRequest ErrorResponse
+
+func (*ListCommandWebhooksBadRequest) listCommandWebhooksRes() {}
+
+type ListCommandWebhooksInternalServerError ErrorResponse
+
+func (*ListCommandWebhooksInternalServerError) listCommandWebhooksRes() {}
+
+type ListCommandWebhooksNotFound ErrorResponse
+
+func (*ListCommandWebhooksNotFound) listCommandWebhooksRes() {}
+
+type ListDeadLettersBadRequest ErrorResponse
+
+func (*ListDeadLettersBadRequest) listDeadLettersRes() {}
+
+type ListDeadLettersInternalServerError ErrorResponse
+
+func (*ListDeadLettersInternalServerError) listDeadLettersRes() {}
+
+// NewOptBool returns new OptBool with value set to v.
+func NewOptBool(v bool) OptBool {
+	return OptBool{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptBool is optional bool.
+type OptBool struct {
+	Value bool
+	Set   bool
+}
+
+// IsSet returns true if OptBool was set.
+func (o OptBool) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptBool) Reset() {
+	var v bool
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptBool) SetTo(v bool) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptBool) Get() (v bool, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptBool) Or(d bool) bool {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptCommandRequest returns new OptCommandRequest with value set to v.
+func NewOptCommandRequest(v CommandRequest) OptCommandRequest {
+	return OptCommandRequest{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptCommandRequest is optional CommandRequest.
+type OptCommandRequest struct {
+	Value CommandRequest
+	Set   bool
+}
+
+// IsSet returns true if OptCommandRequest was set.
+func (o OptCommandRequest) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptCommandRequest) Reset() {
+	var v CommandRequest
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptCommandRequest) SetTo(v CommandRequest) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptCommandRequest) Get() (v CommandRequest, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptCommandRequest) Or(d CommandRequest) CommandRequest {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptCommandRequestInputFiles returns new OptCommandRequestInputFiles with value set to v.
+func NewOptCommandRequestInputFiles(v CommandRequestInputFiles) OptCommandRequestInputFiles {
+	return OptCommandRequestInputFiles{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptCommandRequestInputFiles is optional CommandRequestInputFiles.
+type OptCommandRequestInputFiles struct {
+	Value CommandRequestInputFiles
+	Set   bool
+}
+
+// IsSet returns true if OptCommandRequestInputFiles was set.
+func (o OptCommandRequestInputFiles) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptCommandRequestInputFiles) Reset() {
+	var v CommandRequestInputFiles
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptCommandRequestInputFiles) SetTo(v CommandRequestInputFiles) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptCommandRequestInputFiles) Get() (v CommandRequestInputFiles, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptCommandRequestInputFiles) Or(d CommandRequestInputFiles) CommandRequestInputFiles {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptCommandRequestResourceClass returns new OptCommandRequestResourceClass with value set to v.
+func NewOptCommandRequestResourceClass(v CommandRequestResourceClass) OptCommandRequestResourceClass {
+	return OptCommandRequestResourceClass{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptCommandRequestResourceClass is optional CommandRequestResourceClass.
+type OptCommandRequestResourceClass struct {
+	Value CommandRequestResourceClass
+	Set   bool
+}
+
+// IsSet returns true if OptCommandRequestResourceClass was set.
+func (o OptCommandRequestResourceClass) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptCommandRequestResourceClass) Reset() {
+	var v CommandRequestResourceClass
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptCommandRequestResourceClass) SetTo(v CommandRequestResourceClass) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptCommandRequestResourceClass) Get() (v CommandRequestResourceClass, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptCommandRequestResourceClass) Or(d CommandRequestResourceClass) CommandRequestResourceClass {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptCommandStatusOutputFiles returns new OptCommandStatusOutputFiles with value set to v.
+func NewOptCommandStatusOutputFiles(v CommandStatusOutputFiles) OptCommandStatusOutputFiles {
+	return OptCommandStatusOutputFiles{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptCommandStatusOutputFiles is optional CommandStatusOutputFiles.
+type OptCommandStatusOutputFiles struct {
+	Value CommandStatusOutputFiles
+	Set   bool
+}
+
+// IsSet returns true if OptCommandStatusOutputFiles was set.
+func (o OptCommandStatusOutputFiles) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptCommandStatusOutputFiles) Reset() {
+	var v CommandStatusOutputFiles
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptCommandStatusOutputFiles) SetTo(v CommandStatusOutputFiles) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptCommandStatusOutputFiles) Get() (v CommandStatusOutputFiles, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptCommandStatusOutputFiles) Or(d CommandStatusOutputFiles) CommandStatusOutputFiles {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptDateTime returns new OptDateTime with value set to v.
+func NewOptDateTime(v time.Time) OptDateTime {
+	return OptDateTime{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptDateTime is optional time.Time.
+type OptDateTime struct {
+	Value time.Time
+	Set   bool
+}
+
+// IsSet returns true if OptDateTime was set.
+func (o OptDateTime) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptDateTime) Reset() {
+	var v time.Time
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptDateTime) SetTo(v time.Time) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptDateTime) Get() (v time.Time, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptDateTime) Or(d time.Time) time.Time {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptDeadLetterPayload returns new OptDeadLetterPayload with value set to v.
+func NewOptDeadLetterPayload(v DeadLetterPayload) OptDeadLetterPayload {
+	return OptDeadLetterPayload{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptDeadLetterPayload is optional DeadLetterPayload.
+type OptDeadLetterPayload struct {
+	Value DeadLetterPayload
+	Set   bool
+}
+
+// IsSet returns true if OptDeadLetterPayload was set.
+func (o OptDeadLetterPayload) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptDeadLetterPayload) Reset() {
+	var v DeadLetterPayload
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptDeadLetterPayload) SetTo(v DeadLetterPayload) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptDeadLetterPayload) Get() (v DeadLetterPayload, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptDeadLetterPayload) Or(d DeadLetterPayload) DeadLetterPayload {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptDeadLetterReplayRequest returns new OptDeadLetterReplayRequest with value set to v.
+func NewOptDeadLetterReplayRequest(v DeadLetterReplayRequest) OptDeadLetterReplayRequest {
+	return OptDeadLetterReplayRequest{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptDeadLetterReplayRequest is optional DeadLetterReplayRequest.
+type OptDeadLetterReplayRequest struct {
+	Value DeadLetterReplayRequest
+	Set   bool
+}
+
+// IsSet returns true if OptDeadLetterReplayRequest was set.
+func (o OptDeadLetterReplayRequest) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptDeadLetterReplayRequest) Reset() {
+	var v DeadLetterReplayRequest
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptDeadLetterReplayRequest) SetTo(v DeadLetterReplayRequest) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptDeadLetterReplayRequest) Get() (v DeadLetterReplayRequest, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptDeadLetterReplayRequest) Or(d DeadLetterReplayRequest) DeadLetterReplayRequest {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptFloat64 returns new OptFloat64 with value set to v.
+func NewOptFloat64(v float64) OptFloat64 {
+	return OptFloat64{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptFloat64 is optional float64.
+type OptFloat64 struct {
+	Value float64
+	Set   bool
+}
+
+// IsSet returns true if OptFloat64 was set.
+func (o OptFloat64) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptFloat64) Reset() {
+	var v float64
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptFloat64) SetTo(v float64) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptFloat64) Get() (v float64, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptFloat64) Or(d float64) float64 {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptInputFileSpecExpect returns new OptInputFileSpecExpect with value set to v.
+func NewOptInputFileSpecExpect(v InputFileSpecExpect) OptInputFileSpecExpect {
+	return OptInputFileSpecExpect{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptInputFileSpecExpect is optional InputFileSpecExpect.
+type OptInputFileSpecExpect struct {
+	Value InputFileSpecExpect
+	Set   bool
+}
+
+// IsSet returns true if OptInputFileSpecExpect was set.
+func (o OptInputFileSpecExpect) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptInputFileSpecExpect) Reset() {
+	var v InputFileSpecExpect
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptInputFileSpecExpect) SetTo(v InputFileSpecExpect) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptInputFileSpecExpect) Get() (v InputFileSpecExpect, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptInputFileSpecExpect) Or(d InputFileSpecExpect) InputFileSpecExpect {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptInputFileSpecHeaders returns new OptInputFileSpecHeaders with value set to v.
+func NewOptInputFileSpecHeaders(v InputFileSpecHeaders) OptInputFileSpecHeaders {
+	return OptInputFileSpecHeaders{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptInputFileSpecHeaders is optional InputFileSpecHeaders.
+type OptInputFileSpecHeaders struct {
+	Value InputFileSpecHeaders
+	Set   bool
+}
+
+// IsSet returns true if OptInputFileSpecHeaders was set.
+func (o OptInputFileSpecHeaders) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptInputFileSpecHeaders) Reset() {
+	var v InputFileSpecHeaders
+	o.Value = v
 	o.Set = false
 }
 
 // SetTo sets value to v.
-func (o *OptFloat64) SetTo(v float64) {
+func (o *OptInputFileSpecHeaders) SetTo(v InputFileSpecHeaders) {
 	o.Set = true
 	o.Value = v
 }
 
 // Get returns value and boolean that denotes whether value was set.
-func (o OptFloat64) Get() (v float64, ok bool) {
+func (o OptInputFileSpecHeaders) Get() (v InputFileSpecHeaders, ok bool) {
 	if !o.Set {
 		return v, false
 	}
@@ -703,7 +1720,53 @@ func (o OptFloat64) Get() (v float64, ok bool) {
 }
 
 // Or returns value if set, or given parameter if does not.
-func (o OptFloat64) Or(d float64) float64 {
+func (o OptInputFileSpecHeaders) Or(d InputFileSpecHeaders) InputFileSpecHeaders {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptInputFileSpecMode returns new OptInputFileSpecMode with value set to v.
+func NewOptInputFileSpecMode(v InputFileSpecMode) OptInputFileSpecMode {
+	return OptInputFileSpecMode{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptInputFileSpecMode is optional InputFileSpecMode.
+type OptInputFileSpecMode struct {
+	Value InputFileSpecMode
+	Set   bool
+}
+
+// IsSet returns true if OptInputFileSpecMode was set.
+func (o OptInputFileSpecMode) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptInputFileSpecMode) Reset() {
+	var v InputFileSpecMode
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptInputFileSpecMode) SetTo(v InputFileSpecMode) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptInputFileSpecMode) Get() (v InputFileSpecMode, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptInputFileSpecMode) Or(d InputFileSpecMode) InputFileSpecMode {
 	if v, ok := o.Get(); ok {
 		return v
 	}
@@ -756,6 +1819,52 @@ func (o OptInt) Or(d int) int {
 	return d
 }
 
+// NewOptInt64 returns new OptInt64 with value set to v.
+func NewOptInt64(v int64) OptInt64 {
+	return OptInt64{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptInt64 is optional int64.
+type OptInt64 struct {
+	Value int64
+	Set   bool
+}
+
+// IsSet returns true if OptInt64 was set.
+func (o OptInt64) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptInt64) Reset() {
+	var v int64
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptInt64) SetTo(v int64) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptInt64) Get() (v int64, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptInt64) Or(d int64) int64 {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
 // NewOptString returns new OptString with value set to v.
 func NewOptString(v string) OptString {
 	return OptString{
@@ -848,6 +1957,52 @@ func (o OptURI) Or(d url.URL) url.URL {
 	return d
 }
 
+// NewOptWebhookStatus returns new OptWebhookStatus with value set to v.
+func NewOptWebhookStatus(v WebhookStatus) OptWebhookStatus {
+	return OptWebhookStatus{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptWebhookStatus is optional WebhookStatus.
+type OptWebhookStatus struct {
+	Value WebhookStatus
+	Set   bool
+}
+
+// IsSet returns true if OptWebhookStatus was set.
+func (o OptWebhookStatus) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptWebhookStatus) Reset() {
+	var v WebhookStatus
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptWebhookStatus) SetTo(v WebhookStatus) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptWebhookStatus) Get() (v WebhookStatus, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptWebhookStatus) Or(d WebhookStatus) WebhookStatus {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
 // Ref: #/components/schemas/OutputFileInfo
 type OutputFileInfo struct {
 	// Unique identifier for this output file.
@@ -998,3 +2153,317 @@ func (s *OutputFileInfoFileType) UnmarshalText(data []byte) error {
 		return errors.Errorf("invalid value: %q", data)
 	}
 }
+
+type ReplayDeadLetterInternalServerError ErrorResponse
+
+func (*ReplayDeadLetterInternalServerError) replayDeadLetterRes() {}
+
+type ReplayDeadLetterNotFound ErrorResponse
+
+func (*ReplayDeadLetterNotFound) replayDeadLetterRes() {}
+
+type ReplayDeadLettersBadRequest ErrorResponse
+
+func (*ReplayDeadLettersBadRequest) replayDeadLettersRes() {}
+
+type ReplayDeadLettersInternalServerError ErrorResponse
+
+func (*ReplayDeadLettersInternalServerError) replayDeadLettersRes() {}
+
+// Ref: #/components/schemas/WebhookDeliveryAttempt
+type WebhookDeliveryAttempt struct {
+	// Attempt number for this delivery (1-based).
+	Attempt int `json:"attempt"`
+	// Identifier of the webhook delivery task.
+	TaskID string `json:"task_id"`
+	// Destination URL.
+	URL string `json:"url"`
+	// Command status that was delivered.
+	Event string `json:"event"`
+	// When the attempt started.
+	Timestamp time.Time `json:"timestamp"`
+	// HTTP status returned by the endpoint.
+	HTTPStatus OptInt `json:"http_status"`
+	// Time until the response or error, in milliseconds.
+	LatencyMs int64 `json:"latency_ms"`
+	// Response body returned by the endpoint (truncated).
+	ResponseBody OptString `json:"response_body"`
+	// Error message if the attempt failed.
+	Error OptString `json:"error"`
+	// Whether the endpoint returned a 2xx status.
+	Success bool `json:"success"`
+	// Whether this was the last attempt for the delivery.
+	Final bool `json:"final"`
+}
+
+// GetAttempt returns the value of Attempt.
+func (s *WebhookDeliveryAttempt) GetAttempt() int {
+	return s.Attempt
+}
+
+// GetTaskID returns the value of TaskID.
+func (s *WebhookDeliveryAttempt) GetTaskID() string {
+	return s.TaskID
+}
+
+// GetURL returns the value of URL.
+func (s *WebhookDeliveryAttempt) GetURL() string {
+	return s.URL
+}
+
+// GetEvent returns the value of Event.
+func (s *WebhookDeliveryAttempt) GetEvent() string {
+	return s.Event
+}
+
+// GetTimestamp returns the value of Timestamp.
+func (s *WebhookDeliveryAttempt) GetTimestamp() time.Time {
+	return s.Timestamp
+}
+
+// GetHTTPStatus returns the value of HTTPStatus.
+func (s *WebhookDeliveryAttempt) GetHTTPStatus() OptInt {
+	return s.HTTPStatus
+}
+
+// GetLatencyMs returns the value of LatencyMs.
+func (s *WebhookDeliveryAttempt) GetLatencyMs() int64 {
+	return s.LatencyMs
+}
+
+// GetResponseBody returns the value of ResponseBody.
+func (s *WebhookDeliveryAttempt) GetResponseBody() OptString {
+	return s.ResponseBody
+}
+
+// GetError returns the value of Error.
+func (s *WebhookDeliveryAttempt) GetError() OptString {
+	return s.Error
+}
+
+// GetSuccess returns the value of Success.
+func (s *WebhookDeliveryAttempt) GetSuccess() bool {
+	return s.Success
+}
+
+// GetFinal returns the value of Final.
+func (s *WebhookDeliveryAttempt) GetFinal() bool {
+	return s.Final
+}
+
+// SetAttempt sets the value of Attempt.
+func (s *WebhookDeliveryAttempt) SetAttempt(val int) {
+	s.Attempt = val
+}
+
+// SetTaskID sets the value of TaskID.
+func (s *WebhookDeliveryAttempt) SetTaskID(val string) {
+	s.TaskID = val
+}
+
+// SetURL sets the value of URL.
+func (s *WebhookDeliveryAttempt) SetURL(val string) {
+	s.URL = val
+}
+
+// SetEvent sets the value of Event.
+func (s *WebhookDeliveryAttempt) SetEvent(val string) {
+	s.Event = val
+}
+
+// SetTimestamp sets the value of Timestamp.
+func (s *WebhookDeliveryAttempt) SetTimestamp(val time.Time) {
+	s.Timestamp = val
+}
+
+// SetHTTPStatus sets the value of HTTPStatus.
+func (s *WebhookDeliveryAttempt) SetHTTPStatus(val OptInt) {
+	s.HTTPStatus = val
+}
+
+// SetLatencyMs sets the value of LatencyMs.
+func (s *WebhookDeliveryAttempt) SetLatencyMs(val int64) {
+	s.LatencyMs = val
+}
+
+// SetResponseBody sets the value of ResponseBody.
+func (s *WebhookDeliveryAttempt) SetResponseBody(val OptString) {
+	s.ResponseBody = val
+}
+
+// SetError sets the value of Error.
+func (s *WebhookDeliveryAttempt) SetError(val OptString) {
+	s.Error = val
+}
+
+// SetSuccess sets the value of Success.
+func (s *WebhookDeliveryAttempt) SetSuccess(val bool) {
+	s.Success = val
+}
+
+// SetFinal sets the value of Final.
+func (s *WebhookDeliveryAttempt) SetFinal(val bool) {
+	s.Final = val
+}
+
+// Ref: #/components/schemas/WebhookDeliveryListResponse
+type WebhookDeliveryListResponse struct {
+	// Command the deliveries belong to.
+	CommandID string `json:"command_id"`
+	// Delivery attempts, oldest first.
+	Attempts []WebhookDeliveryAttempt `json:"attempts"`
+	// Number of attempts returned.
+	Total int `json:"total"`
+}
+
+// GetCommandID returns the value of CommandID.
+func (s *WebhookDeliveryListResponse) GetCommandID() string {
+	return s.CommandID
+}
+
+// GetAttempts returns the value of Attempts.
+func (s *WebhookDeliveryListResponse) GetAttempts() []WebhookDeliveryAttempt {
+	return s.Attempts
+}
+
+// GetTotal returns the value of Total.
+func (s *WebhookDeliveryListResponse) GetTotal() int {
+	return s.Total
+}
+
+// SetCommandID sets the value of CommandID.
+func (s *WebhookDeliveryListResponse) SetCommandID(val string) {
+	s.CommandID = val
+}
+
+// SetAttempts sets the value of Attempts.
+func (s *WebhookDeliveryListResponse) SetAttempts(val []WebhookDeliveryAttempt) {
+	s.Attempts = val
+}
+
+// SetTotal sets the value of Total.
+func (s *WebhookDeliveryListResponse) SetTotal(val int) {
+	s.Total = val
+}
+
+func (*WebhookDeliveryListResponse) listCommandWebhooksRes() {}
+
+// Ref: #/components/schemas/WebhookStatus
+type WebhookStatus struct {
+	// Delivery state of the most recent webhook.
+	State WebhookStatusState `json:"state"`
+	// Number of delivery attempts made.
+	Attempts int `json:"attempts"`
+	// When the last delivery attempt started.
+	LastAttemptAt OptDateTime `json:"last_attempt_at"`
+	// HTTP status returned by the last attempt.
+	LastHTTPStatus OptInt `json:"last_http_status"`
+	// Error from the last attempt, if it failed.
+	LastError OptString `json:"last_error"`
+}
+
+// GetState returns the value of State.
+func (s *WebhookStatus) GetState() WebhookStatusState {
+	return s.State
+}
+
+// GetAttempts returns the value of Attempts.
+func (s *WebhookStatus) GetAttempts() int {
+	return s.Attempts
+}
+
+// GetLastAttemptAt returns the value of LastAttemptAt.
+func (s *WebhookStatus) GetLastAttemptAt() OptDateTime {
+	return s.LastAttemptAt
+}
+
+// GetLastHTTPStatus returns the value of LastHTTPStatus.
+func (s *WebhookStatus) GetLastHTTPStatus() OptInt {
+	return s.LastHTTPStatus
+}
+
+// GetLastError returns the value of LastError.
+func (s *WebhookStatus) GetLastError() OptString {
+	return s.LastError
+}
+
+// SetState sets the value of State.
+func (s *WebhookStatus) SetState(val WebhookStatusState) {
+	s.State = val
+}
+
+// SetAttempts sets the value of Attempts.
+func (s *WebhookStatus) SetAttempts(val int) {
+	s.Attempts = val
+}
+
+// SetLastAttemptAt sets the value of LastAttemptAt.
+func (s *WebhookStatus) SetLastAttemptAt(val OptDateTime) {
+	s.LastAttemptAt = val
+}
+
+// SetLastHTTPStatus sets the value of LastHTTPStatus.
+func (s *WebhookStatus) SetLastHTTPStatus(val OptInt) {
+	s.LastHTTPStatus = val
+}
+
+// SetLastError sets the value of LastError.
+func (s *WebhookStatus) SetLastError(val OptString) {
+	s.LastError = val
+}
+
+// Delivery state of the most recent webhook.
+type WebhookStatusState string
+
+const (
+	WebhookStatusStatePENDING   WebhookStatusState = "PENDING"
+	WebhookStatusStateDELIVERED WebhookStatusState = "DELIVERED"
+	WebhookStatusStateRETRYING  WebhookStatusState = "RETRYING"
+	WebhookStatusStateFAILED    WebhookStatusState = "FAILED"
+)
+
+// AllValues returns all WebhookStatusState values.
+func (WebhookStatusState) AllValues() []WebhookStatusState {
+	return []WebhookStatusState{
+		WebhookStatusStatePENDING,
+		WebhookStatusStateDELIVERED,
+		WebhookStatusStateRETRYING,
+		WebhookStatusStateFAILED,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s WebhookStatusState) MarshalText() ([]byte, error) {
+	switch s {
+	case WebhookStatusStatePENDING:
+		return []byte(s), nil
+	case WebhookStatusStateDELIVERED:
+		return []byte(s), nil
+	case WebhookStatusStateRETRYING:
+		return []byte(s), nil
+	case WebhookStatusStateFAILED:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *WebhookStatusState) UnmarshalText(data []byte) error {
+	switch WebhookStatusState(data) {
+	case WebhookStatusStatePENDING:
+		*s = WebhookStatusStatePENDING
+		return nil
+	case WebhookStatusStateDELIVERED:
+		*s = WebhookStatusStateDELIVERED
+		return nil
+	case WebhookStatusStateRETRYING:
+		*s = WebhookStatusStateRETRYING
+		return nil
+	case WebhookStatusStateFAILED:
+		*s = WebhookStatusStateFAILED
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
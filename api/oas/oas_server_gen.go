@@ -14,12 +14,30 @@ type Handler interface {
 	//
 	// POST /v1/commands
 	CreateCommand(ctx context.Context, req *CommandRequest) (CreateCommandRes, error)
+	// DeleteDeadLetter implements deleteDeadLetter operation.
+	//
+	// Permanently delete a dead-lettered delivery.
+	//
+	// DELETE /v1/admin/webhooks/dead-letters/{task_id}
+	DeleteDeadLetter(ctx context.Context, params DeleteDeadLetterParams) (DeleteDeadLetterRes, error)
+	// DeleteDeadLetters implements deleteDeadLetters operation.
+	//
+	// Permanently delete every dead-lettered delivery matching the filter.
+	//
+	// POST /v1/admin/webhooks/dead-letters/delete
+	DeleteDeadLetters(ctx context.Context, req *DeadLetterBulkRequest) (DeleteDeadLettersRes, error)
 	// GetCommand implements getCommand operation.
 	//
 	// Get the status and results of a specific command.
 	//
 	// GET /v1/commands/{id}
 	GetCommand(ctx context.Context, params GetCommandParams) (GetCommandRes, error)
+	// GetDeadLetter implements getDeadLetter operation.
+	//
+	// Get a dead-lettered delivery including its payload.
+	//
+	// GET /v1/admin/webhooks/dead-letters/{task_id}
+	GetDeadLetter(ctx context.Context, params GetDeadLetterParams) (GetDeadLetterRes, error)
 	// GetOpenAPI implements getOpenAPI operation.
 	//
 	// Returns the OpenAPI 3.1 specification for this API.
@@ -32,12 +50,36 @@ type Handler interface {
 	//
 	// GET /health
 	HealthCheck(ctx context.Context) (*HealthResponse, error)
+	// ListCommandWebhooks implements listCommandWebhooks operation.
+	//
+	// Get every webhook delivery attempt recorded for a command.
+	//
+	// GET /v1/commands/{id}/webhooks
+	ListCommandWebhooks(ctx context.Context, params ListCommandWebhooksParams) (ListCommandWebhooksRes, error)
 	// ListCommands implements listCommands operation.
 	//
 	// Get a list of all FFmpeg commands with their current status.
 	//
 	// GET /v1/commands
-	ListCommands(ctx context.Context) (*CommandListResponse, error)
+	ListCommands(ctx context.Context) (ListCommandsRes, error)
+	// ListDeadLetters implements listDeadLetters operation.
+	//
+	// List webhook deliveries that exhausted their retries, newest failures first.
+	//
+	// GET /v1/admin/webhooks/dead-letters
+	ListDeadLetters(ctx context.Context, params ListDeadLettersParams) (ListDeadLettersRes, error)
+	// ReplayDeadLetter implements replayDeadLetter operation.
+	//
+	// Re-enqueue a dead-lettered delivery, optionally to a different URL.
+	//
+	// POST /v1/admin/webhooks/dead-letters/{task_id}/replay
+	ReplayDeadLetter(ctx context.Context, req OptDeadLetterReplayRequest, params ReplayDeadLetterParams) (ReplayDeadLetterRes, error)
+	// ReplayDeadLetters implements replayDeadLetters operation.
+	//
+	// Re-enqueue every dead-lettered delivery matching the filter.
+	//
+	// POST /v1/admin/webhooks/dead-letters/replay
+	ReplayDeadLetters(ctx context.Context, req *DeadLetterBulkRequest) (ReplayDeadLettersRes, error)
 }
 
 // Server implements http server based on OpenAPI v3 specification and
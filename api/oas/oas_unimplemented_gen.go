@@ -22,6 +22,24 @@ func (UnimplementedHandler) CreateCommand(ctx context.Context, req *CommandReque
 	return r, ht.ErrNotImplemented
 }
 
+// DeleteDeadLetter implements deleteDeadLetter operation.
+//
+// Permanently delete a dead-lettered delivery.
+//
+// DELETE /v1/admin/webhooks/dead-letters/{task_id}
+func (UnimplementedHandler) DeleteDeadLetter(ctx context.Context, params DeleteDeadLetterParams) (r DeleteDeadLetterRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// DeleteDeadLetters implements deleteDeadLetters operation.
+//
+// Permanently delete every dead-lettered delivery matching the filter.
+//
+// POST /v1/admin/webhooks/dead-letters/delete
+func (UnimplementedHandler) DeleteDeadLetters(ctx context.Context, req *DeadLetterBulkRequest) (r DeleteDeadLettersRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
 // GetCommand implements getCommand operation.
 //
 // Get the status and results of a specific command.
@@ -31,6 +49,15 @@ func (UnimplementedHandler) GetCommand(ctx context.Context, params GetCommandPar
 	return r, ht.ErrNotImplemented
 }
 
+// GetDeadLetter implements getDeadLetter operation.
+//
+// Get a dead-lettered delivery including its payload.
+//
+// GET /v1/admin/webhooks/dead-letters/{task_id}
+func (UnimplementedHandler) GetDeadLetter(ctx context.Context, params GetDeadLetterParams) (r GetDeadLetterRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
 // GetOpenAPI implements getOpenAPI operation.
 //
 // Returns the OpenAPI 3.1 specification for this API.
@@ -49,11 +76,47 @@ func (UnimplementedHandler) HealthCheck(ctx context.Context) (r *HealthResponse,
 	return r, ht.ErrNotImplemented
 }
 
+// ListCommandWebhooks implements listCommandWebhooks operation.
+//
+// Get every webhook delivery attempt recorded for a command.
+//
+// GET /v1/commands/{id}/webhooks
+func (UnimplementedHandler) ListCommandWebhooks(ctx context.Context, params ListCommandWebhooksParams) (r ListCommandWebhooksRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
 // ListCommands implements listCommands operation.
 //
 // Get a list of all FFmpeg commands with their current status.
 //
 // GET /v1/commands
-func (UnimplementedHandler) ListCommands(ctx context.Context) (r *CommandListResponse, _ error) {
+func (UnimplementedHandler) ListCommands(ctx context.Context) (r ListCommandsRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// ListDeadLetters implements listDeadLetters operation.
+//
+// List webhook deliveries that exhausted their retries, newest failures first.
+//
+// GET /v1/admin/webhooks/dead-letters
+func (UnimplementedHandler) ListDeadLetters(ctx context.Context, params ListDeadLettersParams) (r ListDeadLettersRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// ReplayDeadLetter implements replayDeadLetter operation.
+//
+// Re-enqueue a dead-lettered delivery, optionally to a different URL.
+//
+// POST /v1/admin/webhooks/dead-letters/{task_id}/replay
+func (UnimplementedHandler) ReplayDeadLetter(ctx context.Context, req OptDeadLetterReplayRequest, params ReplayDeadLetterParams) (r ReplayDeadLetterRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// ReplayDeadLetters implements replayDeadLetters operation.
+//
+// Re-enqueue every dead-lettered delivery matching the filter.
+//
+// POST /v1/admin/webhooks/dead-letters/replay
+func (UnimplementedHandler) ReplayDeadLetters(ctx context.Context, req *DeadLetterBulkRequest) (r ReplayDeadLettersRes, _ error) {
 	return r, ht.ErrNotImplemented
 }
@@ -0,0 +1,175 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces text matched by a mask pattern
+const Mask = "[REDACTED]"
+
+// Redactor removes sensitive data from command requests before they leave the
+// system. Fields are addressed by dot-separated paths, where "*" matches every
+// key of an object, e.g. "input_files.*".
+type Redactor struct {
+	stripQuery [][]string
+	drop       [][]string
+	maskFields [][]string
+	patterns   []*regexp.Regexp
+}
+
+// New builds a redactor from comma-separated settings. stripQuery and drop are
+// field paths; patterns are regular expressions (write a literal comma as \x2c).
+func New(stripQuery, drop, patterns string) (*Redactor, error) {
+	r := &Redactor{
+		stripQuery: splitPaths(stripQuery),
+		drop:       splitPaths(drop),
+	}
+	for _, p := range splitList(patterns) {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mask pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// MaskFields replaces the values at the given field paths with Mask, in
+// addition to the configured redactions. Use it for fields that must never be
+// shown, whatever the settings.
+func (r *Redactor) MaskFields(paths ...string) {
+	for _, p := range paths {
+		r.maskFields = append(r.maskFields, strings.Split(p, "."))
+	}
+}
+
+// Object returns v as a JSON object with redactions applied
+func (r *Redactor) Object(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	r.Apply(obj)
+	return obj, nil
+}
+
+// JSON returns a JSON object with redactions applied
+func (r *Redactor) JSON(data []byte) ([]byte, error) {
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	r.Apply(obj)
+	return json.Marshal(obj)
+}
+
+// Apply redacts obj in place: dropped fields are removed first, then masked
+// fields are replaced, then query strings are stripped, then mask patterns are
+// applied to every string.
+func (r *Redactor) Apply(obj map[string]any) {
+	for _, path := range r.drop {
+		walk(obj, path, func(any) (any, bool) { return nil, false })
+	}
+	for _, path := range r.maskFields {
+		walk(obj, path, func(any) (any, bool) { return Mask, true })
+	}
+	for _, path := range r.stripQuery {
+		walk(obj, path, func(v any) (any, bool) { return stripQuery(v), true })
+	}
+	if len(r.patterns) > 0 {
+		for k, v := range obj {
+			obj[k] = r.mask(v)
+		}
+	}
+}
+
+// mask replaces pattern matches in every string within v
+func (r *Redactor) mask(v any) any {
+	switch node := v.(type) {
+	case string:
+		for _, re := range r.patterns {
+			node = re.ReplaceAllString(node, Mask)
+		}
+		return node
+	case map[string]any:
+		for k, child := range node {
+			node[k] = r.mask(child)
+		}
+	case []any:
+		for i, child := range node {
+			node[i] = r.mask(child)
+		}
+	}
+	return v
+}
+
+// walk calls fn for each value in obj matching path. fn returns the
+// replacement value, or false to remove the field.
+func walk(obj map[string]any, path []string, fn func(any) (any, bool)) {
+	key, rest := path[0], path[1:]
+	for k, child := range obj {
+		if key != "*" && key != k {
+			continue
+		}
+		if len(rest) > 0 {
+			if next, ok := child.(map[string]any); ok {
+				walk(next, rest, fn)
+			}
+			continue
+		}
+		if value, keep := fn(child); keep {
+			obj[k] = value
+		} else {
+			delete(obj, k)
+		}
+	}
+}
+
+// stripQuery applies StripURL to string values
+func stripQuery(v any) any {
+	if s, ok := v.(string); ok {
+		return StripURL(s)
+	}
+	return v
+}
+
+// StripURL removes the query string, fragment and credentials from a URL.
+// Strings that aren't absolute URLs are returned unchanged.
+func StripURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		return s
+	}
+	u.RawQuery = ""
+	u.ForceQuery = false
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.User = nil
+	return u.String()
+}
+
+func splitPaths(s string) [][]string {
+	var paths [][]string
+	for _, p := range splitList(s) {
+		paths = append(paths, strings.Split(p, "."))
+	}
+	return paths
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
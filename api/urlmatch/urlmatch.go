@@ -0,0 +1,70 @@
+package urlmatch
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Parse parses a base URL, which must be absolute and have no user info,
+// query or fragment
+func Parse(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("url must be absolute: %s", raw)
+	}
+	if u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("url may not have user info, a query or a fragment: %s", raw)
+	}
+	return u, nil
+}
+
+// Under reports whether u goes to base: the scheme and host:port must be the
+// same, and the path must be base's or below it on a "/" boundary, once
+// decoded and with dot segments removed
+func Under(base, u *url.URL) bool {
+	if !strings.EqualFold(u.Scheme, base.Scheme) || hostPort(u) != hostPort(base) {
+		return false
+	}
+	prefix := base.Path
+	p := cleanPath(u.Path)
+	switch {
+	case prefix == "" || prefix == "/":
+		return true
+	case strings.HasSuffix(prefix, "/"):
+		return p+"/" == prefix || strings.HasPrefix(p, prefix)
+	default:
+		return p == prefix || strings.HasPrefix(p, prefix+"/")
+	}
+}
+
+// hostPort returns a URL's lowercased host and port, with the scheme's default port if none is given
+func hostPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		switch strings.ToLower(u.Scheme) {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(strings.ToLower(u.Hostname()), port)
+}
+
+// cleanPath removes dot segments, which the receiver would resolve, keeping a trailing slash
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	clean := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && clean != "/" {
+		clean += "/"
+	}
+	return clean
+}
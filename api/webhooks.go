@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+
+	"ffmpeg-api/deliveries"
+	"ffmpeg-api/oas"
+)
+
+// ListCommandWebhooks returns the webhook delivery attempts for a command
+func (h *Handler) ListCommandWebhooks(ctx context.Context, params oas.ListCommandWebhooksParams) (oas.ListCommandWebhooksRes, error) {
+	if params.ID == "" {
+		return &oas.ListCommandWebhooksBadRequest{Error: "command_id required"}, nil
+	}
+
+	attempts, err := deliveries.Load(ctx, redisClient, params.ID, 0)
+	if err != nil {
+		return &oas.ListCommandWebhooksInternalServerError{Error: err.Error()}, nil
+	}
+	if len(attempts) == 0 {
+		// Commands without a webhook have no attempts, so only report 404
+		// when the command itself is unknown.
+		if _, err := asynqInspector.GetTaskInfo("ffmpeg", params.ID); err != nil {
+			return &oas.ListCommandWebhooksNotFound{Error: "command not found"}, nil
+		}
+	}
+
+	resp := &oas.WebhookDeliveryListResponse{
+		CommandID: params.ID,
+		Attempts:  make([]oas.WebhookDeliveryAttempt, 0, len(attempts)),
+		Total:     len(attempts),
+	}
+	for _, a := range attempts {
+		item := oas.WebhookDeliveryAttempt{
+			Attempt:   a.Attempt,
+			TaskID:    a.TaskID,
+			URL:       a.URL,
+			Event:     a.Event,
+			Timestamp: a.Timestamp,
+			LatencyMs: a.LatencyMS,
+			Success:   a.Success,
+			Final:     a.Final,
+		}
+		if a.HTTPStatus > 0 {
+			item.HTTPStatus.SetTo(a.HTTPStatus)
+		}
+		if a.ResponseBody != "" {
+			item.ResponseBody.SetTo(a.ResponseBody)
+		}
+		if a.Error != "" {
+			item.Error.SetTo(a.Error)
+		}
+		resp.Attempts = append(resp.Attempts, item)
+	}
+
+	return resp, nil
+}
+
+// webhookStatus summarizes the delivery log for a command that requested a webhook
+func webhookStatus(ctx context.Context, commandID string) (oas.WebhookStatus, error) {
+	ws := oas.WebhookStatus{State: oas.WebhookStatusStatePENDING}
+
+	count, err := deliveries.Count(ctx, redisClient, commandID)
+	if err != nil || count == 0 {
+		return ws, err
+	}
+	ws.Attempts = int(count)
+
+	last, err := deliveries.Load(ctx, redisClient, commandID, -1)
+	if err != nil || len(last) == 0 {
+		return ws, err
+	}
+	a := last[0]
+
+	switch {
+	case a.Success:
+		ws.State = oas.WebhookStatusStateDELIVERED
+	case a.Final:
+		ws.State = oas.WebhookStatusStateFAILED
+	default:
+		ws.State = oas.WebhookStatusStateRETRYING
+	}
+	ws.LastAttemptAt.SetTo(a.Timestamp)
+	if a.HTTPStatus > 0 {
+		ws.LastHTTPStatus.SetTo(a.HTTPStatus)
+	}
+	if a.Error != "" {
+		ws.LastError.SetTo(a.Error)
+	}
+
+	return ws, nil
+}
@@ -0,0 +1,41 @@
+#!/bin/sh
+# Packages used by more than one service are edited in one module and copied
+# to the others, since the modules don't import each other.
+#
+#   scripts/shared.sh sync    copy each package from its source to the copies
+#   scripts/shared.sh check   fail if a copy differs from its source
+set -e
+cd "$(dirname "$0")/.."
+
+# Source and copy, one package per line
+PACKAGES="
+worker/argpolicy api/argpolicy
+worker/egress webhooks/egress
+worker/redact api/redact
+worker/shellwords api/shellwords
+webhooks/deliveries api/deliveries
+webhooks/secrets worker/secrets
+webhooks/urlmatch api/urlmatch
+"
+
+status=0
+echo "$PACKAGES" | while read -r src dst; do
+	[ -n "$src" ] || continue
+	case "$1" in
+	sync)
+		rm -rf "$dst"
+		cp -r "$src" "$dst"
+		;;
+	check)
+		if ! diff -r "$src" "$dst" >/dev/null; then
+			echo "$dst differs from $src; edit $src and run scripts/shared.sh sync" >&2
+			exit 1
+		fi
+		;;
+	*)
+		echo "usage: $0 sync|check" >&2
+		exit 2
+		;;
+	esac
+done || status=$?
+exit $status
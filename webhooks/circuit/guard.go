@@ -0,0 +1,199 @@
+package circuit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// State is the circuit state of a destination host
+type State string
+
+const (
+	StateClosed   State = "closed"    // Deliveries flow normally
+	StateOpen     State = "open"      // Deliveries are deferred until the open period ends
+	StateHalfOpen State = "half-open" // A single probe delivery is in flight
+)
+
+// maxWait is the longest Acquire will block waiting for a rate limit token.
+// Longer waits are deferred back to the queue so they don't hold a worker slot.
+const maxWait = time.Second
+
+// maxTrackedHosts bounds the host table; idle healthy hosts are pruned past it
+const maxTrackedHosts = 10000
+
+// Settings configures the per-host limits
+type Settings struct {
+	FailureThreshold int           // Consecutive failures before the circuit opens
+	OpenDuration     time.Duration // How long the circuit stays open before a probe
+	MaxRPS           float64       // Max requests per second per host (0 = unlimited)
+	MaxInFlight      int           // Max concurrent requests per host (0 = unlimited)
+}
+
+// DeferredError is returned by Acquire when a delivery must wait for its host.
+// It is not a delivery failure and should not consume a retry.
+type DeferredError struct {
+	Host       string
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *DeferredError) Error() string {
+	return fmt.Sprintf("delivery to %s deferred: %s", e.Host, e.Reason)
+}
+
+// Guard tracks health and load per destination host. State is kept in memory,
+// so with several webhook service replicas each enforces its own limits.
+type Guard struct {
+	settings Settings
+	now      func() time.Time // Replaced in tests
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	state     State
+	failures  int
+	openUntil time.Time
+	inFlight  int
+	limiter   *rate.Limiter
+}
+
+// NewGuard creates a guard with the given settings
+func NewGuard(settings Settings) *Guard {
+	return &Guard{
+		settings: settings,
+		now:      time.Now,
+		hosts:    make(map[string]*hostState),
+	}
+}
+
+// Acquire reserves a delivery slot for host. On success it returns a release
+// function that must be called once with whether the host behaved healthily.
+// If the host's circuit is open or its limits are reached, Acquire returns a
+// *DeferredError saying when to try again.
+func (g *Guard) Acquire(ctx context.Context, host string) (func(healthy bool), error) {
+	host = strings.ToLower(host)
+
+	g.mu.Lock()
+	h := g.host(host)
+	now := g.now()
+
+	probe := false
+	switch h.state {
+	case StateOpen:
+		if now.Before(h.openUntil) {
+			g.mu.Unlock()
+			return nil, &DeferredError{Host: host, Reason: "circuit open", RetryAfter: h.openUntil.Sub(now)}
+		}
+		// Open period is over: this delivery becomes the probe
+		probe = true
+	case StateHalfOpen:
+		g.mu.Unlock()
+		return nil, &DeferredError{Host: host, Reason: "circuit half-open, probe in flight", RetryAfter: g.settings.OpenDuration}
+	}
+
+	if g.settings.MaxInFlight > 0 && h.inFlight >= g.settings.MaxInFlight {
+		g.mu.Unlock()
+		return nil, &DeferredError{Host: host, Reason: "too many requests in flight", RetryAfter: time.Second}
+	}
+
+	var reservation *rate.Reservation
+	if h.limiter != nil {
+		reservation = h.limiter.ReserveN(now, 1)
+		if delay := reservation.DelayFrom(now); delay > maxWait {
+			reservation.CancelAt(now)
+			g.mu.Unlock()
+			return nil, &DeferredError{Host: host, Reason: "rate limit reached", RetryAfter: delay}
+		}
+	}
+
+	if probe {
+		h.state = StateHalfOpen
+		log.Printf("[circuit] %s half-open, sending probe", host)
+	}
+	h.inFlight++
+	g.mu.Unlock()
+
+	if reservation != nil {
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				reservation.CancelAt(g.now())
+				g.mu.Lock()
+				h.inFlight--
+				if probe {
+					h.state = StateOpen
+				}
+				g.mu.Unlock()
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	return func(healthy bool) {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		h.inFlight--
+		g.record(host, h, healthy)
+	}, nil
+}
+
+// host returns the state for host, creating it if needed. Caller holds g.mu.
+func (g *Guard) host(host string) *hostState {
+	if h, ok := g.hosts[host]; ok {
+		return h
+	}
+
+	if len(g.hosts) >= maxTrackedHosts {
+		for name, h := range g.hosts {
+			if h.state == StateClosed && h.failures == 0 && h.inFlight == 0 {
+				delete(g.hosts, name)
+			}
+		}
+	}
+
+	h := &hostState{state: StateClosed}
+	if g.settings.MaxRPS > 0 {
+		burst := int(g.settings.MaxRPS)
+		if burst < 1 {
+			burst = 1
+		}
+		h.limiter = rate.NewLimiter(rate.Limit(g.settings.MaxRPS), burst)
+	}
+	g.hosts[host] = h
+	return h
+}
+
+// record updates the circuit after a delivery. Caller holds g.mu.
+func (g *Guard) record(host string, h *hostState, healthy bool) {
+	if healthy {
+		if h.state != StateClosed {
+			log.Printf("[circuit] %s closed, probe succeeded", host)
+		}
+		h.state = StateClosed
+		h.failures = 0
+		return
+	}
+
+	h.failures++
+	switch {
+	case h.state == StateHalfOpen:
+		h.state = StateOpen
+		h.openUntil = g.now().Add(g.settings.OpenDuration)
+		log.Printf("[circuit] %s re-opened, probe failed", host)
+	case h.state == StateClosed && g.settings.FailureThreshold > 0 && h.failures >= g.settings.FailureThreshold:
+		h.state = StateOpen
+		h.openUntil = g.now().Add(g.settings.OpenDuration)
+		log.Printf("[circuit] %s opened after %d consecutive failures", host, h.failures)
+	}
+}
@@ -0,0 +1,130 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestGuard returns a guard whose clock only moves when advance is called
+func newTestGuard(settings Settings) (*Guard, func(time.Duration)) {
+	g := NewGuard(settings)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return now }
+	return g, func(d time.Duration) { now = now.Add(d) }
+}
+
+// deferred returns the reason Acquire deferred a delivery, or "" if it got a
+// slot, which is then released with healthy
+func deferred(t *testing.T, g *Guard, host string, healthy bool) string {
+	t.Helper()
+	release, err := g.Acquire(context.Background(), host)
+	if err != nil {
+		var d *DeferredError
+		if !errors.As(err, &d) {
+			t.Fatalf("Acquire(%s) = %v, want nil or *DeferredError", host, err)
+		}
+		return d.Reason
+	}
+	release(healthy)
+	return ""
+}
+
+func TestGuardCircuit(t *testing.T) {
+	g, advance := newTestGuard(Settings{FailureThreshold: 3, OpenDuration: time.Minute})
+
+	// Failures below the threshold, and a success resetting them, keep it closed
+	deferred(t, g, "a.example", false)
+	deferred(t, g, "a.example", false)
+	deferred(t, g, "a.example", true)
+	deferred(t, g, "a.example", false)
+	deferred(t, g, "a.example", false)
+	if reason := deferred(t, g, "a.example", false); reason != "" {
+		t.Fatalf("third consecutive failure deferred: %s", reason)
+	}
+
+	// Open: deferred until the open period ends, other hosts unaffected
+	_, err := g.Acquire(context.Background(), "A.example")
+	var d *DeferredError
+	if !errors.As(err, &d) || d.Reason != "circuit open" || d.RetryAfter != time.Minute {
+		t.Fatalf("Acquire while open = %v, want circuit open for 1m", err)
+	}
+	if reason := deferred(t, g, "b.example", true); reason != "" {
+		t.Errorf("other host deferred: %s", reason)
+	}
+
+	// Half-open: one probe at a time; a failed probe re-opens it
+	advance(time.Minute)
+	release, err := g.Acquire(context.Background(), "a.example")
+	if err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if reason := deferred(t, g, "a.example", true); reason != "circuit half-open, probe in flight" {
+		t.Errorf("Acquire during probe deferred with %q", reason)
+	}
+	release(false)
+	if reason := deferred(t, g, "a.example", true); reason != "circuit open" {
+		t.Errorf("after failed probe deferred with %q, want circuit open", reason)
+	}
+
+	// A successful probe closes it
+	advance(time.Minute)
+	deferred(t, g, "a.example", true)
+	if got := g.hosts["a.example"]; got.state != StateClosed || got.failures != 0 {
+		t.Errorf("after successful probe: state %s, %d failures", got.state, got.failures)
+	}
+}
+
+func TestGuardMaxInFlight(t *testing.T) {
+	g, _ := newTestGuard(Settings{MaxInFlight: 2})
+	ctx := context.Background()
+
+	first, err := g.Acquire(ctx, "a.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Acquire(ctx, "a.example"); err != nil {
+		t.Fatal(err)
+	}
+	if reason := deferred(t, g, "a.example", true); reason != "too many requests in flight" {
+		t.Errorf("third request deferred with %q", reason)
+	}
+	first(true)
+	if reason := deferred(t, g, "a.example", true); reason != "" {
+		t.Errorf("after a release, deferred with %q", reason)
+	}
+}
+
+func TestGuardRateLimit(t *testing.T) {
+	g, advance := newTestGuard(Settings{MaxRPS: 0.5})
+
+	if reason := deferred(t, g, "a.example", true); reason != "" {
+		t.Fatalf("first request deferred: %s", reason)
+	}
+	// The next token is 2s away, longer than Acquire waits
+	_, err := g.Acquire(context.Background(), "a.example")
+	var d *DeferredError
+	if !errors.As(err, &d) || d.Reason != "rate limit reached" || d.RetryAfter != 2*time.Second {
+		t.Fatalf("second request = %v, want rate limit reached for 2s", err)
+	}
+	advance(2 * time.Second)
+	if reason := deferred(t, g, "a.example", true); reason != "" {
+		t.Errorf("after 2s deferred with %q", reason)
+	}
+}
+
+func TestGuardRateLimitWaitCancelled(t *testing.T) {
+	g, _ := newTestGuard(Settings{MaxRPS: 1, MaxInFlight: 1})
+	deferred(t, g, "a.example", true)
+
+	// The next token is 1s away, so Acquire waits for it until ctx is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := g.Acquire(ctx, "a.example"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire = %v, want context.Canceled", err)
+	}
+	if h := g.hosts["a.example"]; h.inFlight != 0 {
+		t.Errorf("in flight after cancelled wait = %d, want 0", h.inFlight)
+	}
+}
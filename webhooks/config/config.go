@@ -0,0 +1,171 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"webhook-service/egress"
+)
+
+// Config holds all webhook service configuration
+type Config struct {
+	// Redis configuration
+	Redis RedisConfig
+
+	// Delivery configuration
+	Delivery DeliveryConfig
+
+	// Retry schedule
+	Retry RetryConfig
+
+	// Per-command event ordering
+	Ordering OrderingConfig
+
+	// Per-destination host limits
+	Hosts HostConfig
+
+	// Destination policy (SSRF protection)
+	Destinations DestinationConfig
+
+	// Non-HTTP sinks
+	Sinks SinkConfig
+
+	// Path to the registered endpoints file (JSON)
+	EndpointsFile string
+
+	// Directory holding secrets referenced by registered endpoints
+	SecretsDir string
+
+	// CloudEvents "source" attribute for endpoints using a CloudEvents format
+	CloudEventsSource string
+
+	// Health check server port
+	HealthPort string
+}
+
+// RedisConfig holds Redis connection settings
+type RedisConfig struct {
+	Addr string
+}
+
+// DeliveryConfig holds webhook delivery settings
+type DeliveryConfig struct {
+	Concurrency     int
+	HTTPTimeout     time.Duration
+	RetentionHours  int
+	LogBodyMaxBytes int
+}
+
+// RetryConfig holds the delivery retry schedule
+type RetryConfig struct {
+	Schedule string        // Explicit comma-separated delays (e.g. "10s,1m,5m"); overrides exponential backoff
+	Base     time.Duration // First delay with exponential backoff
+	MaxDelay time.Duration // Upper bound for any single delay, including Retry-After
+	Jitter   float64       // Random +/- fraction applied to exponential delays
+	MaxRetry int           // Retries before moving to the DLQ with exponential backoff
+	MaxAge   time.Duration // Give up on deliveries older than this (0 = no limit)
+}
+
+// OrderingConfig holds per-command event ordering settings
+type OrderingConfig struct {
+	MaxWait time.Duration // Deliver an event anyway after waiting this long for an earlier one (0 = wait indefinitely)
+}
+
+// HostConfig holds circuit breaker and rate limit settings applied per destination host
+type HostConfig struct {
+	FailureThreshold int           // Consecutive failures before the circuit opens
+	OpenDuration     time.Duration // How long the circuit stays open before a probe
+	MaxRPS           float64       // Max requests per second per host (0 = unlimited)
+	MaxInFlight      int           // Max concurrent requests per host (0 = unlimited)
+}
+
+// DestinationConfig restricts where webhooks may be delivered (see egress.NewPolicy)
+type DestinationConfig struct {
+	AllowedSchemes string
+	AllowedHosts   string
+	AllowedPorts   string
+	BlockedCIDRs   string
+	MaxRedirects   int
+}
+
+// SinkConfig holds settings for the Redis sinks. Target names are prefixed so
+// events can't be written to arbitrary keys.
+type SinkConfig struct {
+	StreamPrefix  string // Stream key prefix for redis-stream:// targets
+	StreamMaxLen  int64  // Approximate max entries per stream (0 = unlimited)
+	ChannelPrefix string // Channel prefix for redis-pubsub:// targets
+}
+
+// Load loads configuration from environment variables with sensible defaults
+func Load() *Config {
+	return &Config{
+		Redis: RedisConfig{
+			Addr: getEnv("REDIS_ADDR", "localhost:6379"),
+		},
+		Delivery: DeliveryConfig{
+			Concurrency:     getEnvInt("CONCURRENCY", 10),
+			HTTPTimeout:     time.Duration(getEnvInt("HTTP_TIMEOUT", 10)) * time.Second,
+			RetentionHours:  getEnvInt("RETENTION_HOURS", 72),
+			LogBodyMaxBytes: getEnvInt("DELIVERY_LOG_BODY_BYTES", 1024),
+		},
+		Retry: RetryConfig{
+			Schedule: getEnv("RETRY_SCHEDULE", ""),
+			Base:     time.Duration(getEnvInt("RETRY_BASE_SECONDS", 10)) * time.Second,
+			MaxDelay: time.Duration(getEnvInt("RETRY_MAX_DELAY_SECONDS", 8*60*60)) * time.Second,
+			Jitter:   getEnvFloat("RETRY_JITTER", 0.2),
+			MaxRetry: getEnvInt("MAX_RETRY", 5),
+			MaxAge:   time.Duration(getEnvInt("RETRY_MAX_AGE_HOURS", 24)) * time.Hour,
+		},
+		Ordering: OrderingConfig{
+			MaxWait: time.Duration(getEnvInt("ORDERING_MAX_WAIT_SECONDS", 300)) * time.Second,
+		},
+		Hosts: HostConfig{
+			FailureThreshold: getEnvInt("CIRCUIT_FAILURE_THRESHOLD", 5),
+			OpenDuration:     time.Duration(getEnvInt("CIRCUIT_OPEN_SECONDS", 60)) * time.Second,
+			MaxRPS:           getEnvFloat("HOST_MAX_RPS", 10),
+			MaxInFlight:      getEnvInt("HOST_MAX_IN_FLIGHT", 4),
+		},
+		Destinations: DestinationConfig{
+			AllowedSchemes: getEnv("WEBHOOK_ALLOWED_SCHEMES", "http,https"),
+			AllowedHosts:   getEnv("WEBHOOK_ALLOWED_HOSTS", ""),
+			AllowedPorts:   getEnv("WEBHOOK_ALLOWED_PORTS", ""),
+			BlockedCIDRs:   getEnv("WEBHOOK_BLOCKED_CIDRS", egress.DefaultBlockedCIDRs),
+			MaxRedirects:   getEnvInt("WEBHOOK_MAX_REDIRECTS", 0),
+		},
+		Sinks: SinkConfig{
+			StreamPrefix:  getEnv("SINK_STREAM_PREFIX", "events:"),
+			StreamMaxLen:  int64(getEnvInt("SINK_STREAM_MAX_LEN", 100000)),
+			ChannelPrefix: getEnv("SINK_PUBSUB_PREFIX", "events:"),
+		},
+		EndpointsFile:     getEnv("ENDPOINTS_FILE", ""),
+		SecretsDir:        getEnv("SECRETS_DIR", "/run/secrets"),
+		CloudEventsSource: getEnv("CLOUDEVENTS_SOURCE", "/burrowcode/commands"),
+		HealthPort:        getEnv("HEALTH_PORT", "8081"),
+	}
+}
+
+func getEnv(key, defaultVal string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultVal
+}
+
+func getEnvInt(key string, defaultVal int) int {
+	if val := os.Getenv(key); val != "" {
+		if intVal, err := strconv.Atoi(val); err == nil {
+			return intVal
+		}
+	}
+	return defaultVal
+}
+
+func getEnvFloat(key string, defaultVal float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		if floatVal, err := strconv.ParseFloat(val, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultVal
+}
@@ -0,0 +1,125 @@
+package deliveries
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// KeyPrefix is the Redis key prefix for per-command delivery logs. The API
+// reads them with its copy of this package.
+const KeyPrefix = "webhook:deliveries:"
+
+// maxAttemptsPerCommand caps how many attempts are kept for a single command
+const maxAttemptsPerCommand = 100
+
+// Attempt describes a single webhook delivery attempt
+type Attempt struct {
+	Attempt      int       `json:"attempt"`                 // 1-based attempt number
+	TaskID       string    `json:"task_id"`                 // asynq task ID of the delivery
+	URL          string    `json:"url"`                     // Destination URL
+	Event        string    `json:"event"`                   // Command status being delivered (e.g. "SUCCESS")
+	Timestamp    time.Time `json:"timestamp"`               // When the attempt started
+	HTTPStatus   int       `json:"http_status,omitempty"`   // Response status code, if a response was received
+	LatencyMS    int64     `json:"latency_ms"`              // Time until the response (or error) in milliseconds
+	ResponseBody string    `json:"response_body,omitempty"` // Response body, truncated
+	Error        string    `json:"error,omitempty"`         // Transport or status error
+	Success      bool      `json:"success"`                 // True if the endpoint returned 2xx
+	Final        bool      `json:"final"`                   // True if no further retries will be made
+}
+
+// Log records delivery attempts in Redis, one list per command
+type Log struct {
+	rdb          *redis.Client
+	retention    time.Duration
+	maxBodyBytes int
+}
+
+// NewLog creates a delivery log. Entries expire after retention, and stored
+// response bodies are truncated to maxBodyBytes.
+func NewLog(rdb *redis.Client, retention time.Duration, maxBodyBytes int) *Log {
+	return &Log{
+		rdb:          rdb,
+		retention:    retention,
+		maxBodyBytes: maxBodyBytes,
+	}
+}
+
+// MaxBodyBytes returns how many bytes of a response body are worth reading
+func (l *Log) MaxBodyBytes() int {
+	return l.maxBodyBytes
+}
+
+// Record appends an attempt to the command's delivery log
+func (l *Log) Record(commandID string, a Attempt) error {
+	a.ResponseBody = truncate(a.ResponseBody, l.maxBodyBytes)
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("marshal attempt: %w", err)
+	}
+
+	// Use a fresh context: the task context may already be cancelled or timed out,
+	// and those are exactly the attempts worth recording.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key := KeyPrefix + commandID
+	pipe := l.rdb.TxPipeline()
+	pipe.RPush(ctx, key, data)
+	pipe.LTrim(ctx, key, -maxAttemptsPerCommand, -1)
+	pipe.Expire(ctx, key, l.retention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("record attempt: %w", err)
+	}
+	return nil
+}
+
+// Load reads a command's attempts from start to the end of its log. Pass 0
+// for the full log or -1 for only the most recent attempt. Entries that can't
+// be decoded are skipped.
+func Load(ctx context.Context, rdb *redis.Client, commandID string, start int64) ([]Attempt, error) {
+	raw, err := rdb.LRange(ctx, KeyPrefix+commandID, start, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("load attempts: %w", err)
+	}
+
+	attempts := make([]Attempt, 0, len(raw))
+	for _, r := range raw {
+		var a Attempt
+		if err := json.Unmarshal([]byte(r), &a); err == nil {
+			attempts = append(attempts, a)
+		}
+	}
+	return attempts, nil
+}
+
+// Count returns how many attempts are kept for a command
+func Count(ctx context.Context, rdb *redis.Client, commandID string) (int64, error) {
+	n, err := rdb.LLen(ctx, KeyPrefix+commandID).Result()
+	if err != nil {
+		return 0, fmt.Errorf("count attempts: %w", err)
+	}
+	return n, nil
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 sequence. A
+// sequence already cut off at the end, e.g. by a limited read, is dropped too.
+func truncate(s string, n int) string {
+	if len(s) > n {
+		s = s[:n]
+	}
+	for i := len(s) - 1; i >= 0 && i >= len(s)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(s[i]) {
+			if !utf8.FullRuneInString(s[i:]) {
+				s = s[:i]
+			}
+			break
+		}
+	}
+	return s
+}
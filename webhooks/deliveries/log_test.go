@@ -0,0 +1,25 @@
+package deliveries
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"héllo", 2, "h"}, // é is 2 bytes
+		{"héllo", 3, "hé"},
+		{"日本", 4, "日"},               // 日 is 3 bytes
+		{"ok\xe6\x97", 10, "ok"},     // Already cut off mid-rune
+		{"\xff\xfe", 10, "\xff\xfe"}, // Not UTF-8; left to json.Marshal
+		{"", 5, ""},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckIP(t *testing.T) {
+	p, err := NewPolicy("", "", "", DefaultBlockedCIDRs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		ip      string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"172.32.0.1", false},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"224.0.0.1", true},
+		{"8.8.8.8", false},
+		{"::1", true},
+		{"::", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:169.254.169.254", true},
+		{"fd00::1", true},
+		{"fe80::1", true},
+		{"64:ff9b::a9fe:a9fe", true},
+		{"2001:4860:4860::8888", false},
+	}
+	for _, tt := range tests {
+		err := p.CheckIP(net.ParseIP(tt.ip))
+		if tt.blocked != errors.Is(err, ErrDenied) {
+			t.Errorf("CheckIP(%s) = %v, want blocked %v", tt.ip, err, tt.blocked)
+		}
+	}
+}
+
+func TestNewPolicyErrors(t *testing.T) {
+	if _, err := NewPolicy("", "", "", "10.0.0.0/33", 0); err == nil {
+		t.Error("invalid CIDR accepted")
+	}
+	if _, err := NewPolicy("", "", "0", "", 0); err == nil {
+		t.Error("invalid port accepted")
+	}
+	p, err := NewPolicy("", "", "", "none", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.CheckIP(net.ParseIP("127.0.0.1")); err != nil {
+		t.Errorf("none still blocks: %v", err)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	p, err := NewPolicy("https", "media.example.com,*.cdn.example.com", "443,8443", DefaultBlockedCIDRs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		url     string
+		allowed bool
+	}{
+		{"https://media.example.com/a.mp4", true},
+		{"https://MEDIA.example.com/a.mp4", true},
+		{"https://eu.cdn.example.com:8443/a.mp4", true},
+		{"https://cdn.example.com/a.mp4", false},
+		{"https://media.example.com.attacker.net/a.mp4", false},
+		{"https://attacker.net/media.example.com", false},
+		{"http://media.example.com/a.mp4", false},
+		{"https://media.example.com:8080/a.mp4", false},
+		{"https:///a.mp4", false},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = p.CheckURL(u)
+		if tt.allowed != (err == nil) {
+			t.Errorf("CheckURL(%s) = %v, want allowed %v", tt.url, err, tt.allowed)
+		}
+	}
+
+	ipPolicy, err := NewPolicy("", "", "", DefaultBlockedCIDRs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, raw := range []string{"http://127.0.0.1/", "http://[::1]:8080/", "http://169.254.169.254/latest/meta-data"} {
+		u, _ := url.Parse(raw)
+		if err := ipPolicy.CheckURL(u); !errors.Is(err, ErrDenied) {
+			t.Errorf("CheckURL(%s) = %v, want denied", raw, err)
+		}
+	}
+}
+
+func TestClientBlocksAtDial(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	p, err := NewPolicy("", "", "", DefaultBlockedCIDRs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if _, err := p.Client(5 * time.Second).Do(req); !errors.Is(err, ErrDenied) {
+		t.Errorf("loopback request error = %v, want denied", err)
+	}
+
+	open, err := NewPolicy("", "", "", "none", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := open.Client(5 * time.Second).Do(req.Clone(ctx))
+	if err != nil {
+		t.Fatalf("request without blocked ranges: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestClientRedirects(t *testing.T) {
+	target := "http://169.254.169.254/latest"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target, http.StatusFound)
+	}))
+	defer srv.Close()
+
+	// Redirects are rejected by default, without being followed
+	p, err := NewPolicy("", "", "", "none", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.Client(5 * time.Second).Get(srv.URL)
+	if !errors.Is(err, ErrDenied) || !strings.Contains(err.Error(), "redirects are disabled") {
+		t.Errorf("redirect with redirects disabled: error = %v, want redirects disabled", err)
+	}
+
+	// Followed redirects are checked
+	p, err = NewPolicy("", "127.0.0.1", "", "none", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Client(5 * time.Second).Get(srv.URL); !errors.Is(err, ErrDenied) {
+		t.Errorf("redirect to %s error = %v, want denied", target, err)
+	}
+}
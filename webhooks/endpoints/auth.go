@@ -0,0 +1,132 @@
+package endpoints
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"webhook-service/secrets"
+)
+
+// AuthConfig adds an Authorization header to deliveries
+type AuthConfig struct {
+	Type           string `json:"type"`                      // "basic" or "bearer"
+	Username       string `json:"username,omitempty"`        // For basic auth
+	PasswordSecret string `json:"password_secret,omitempty"` // Secret holding the basic auth password
+	TokenSecret    string `json:"token_secret,omitempty"`    // Secret holding the bearer token
+}
+
+// TLSConfig configures HTTPS connections to an endpoint. Certificates and keys
+// are PEM-encoded secrets.
+type TLSConfig struct {
+	CertSecret string `json:"cert_secret,omitempty"` // Client certificate (chain)
+	KeySecret  string `json:"key_secret,omitempty"`  // Client private key
+	CASecret   string `json:"ca_secret,omitempty"`   // CA bundle trusted instead of the system roots
+	ServerName string `json:"server_name,omitempty"` // Overrides the name verified on the server certificate
+}
+
+// Header returns the extra headers sent with HTTP deliveries to this endpoint
+func (e *Endpoint) Header() http.Header {
+	return e.header
+}
+
+// ClientTLS returns the endpoint's TLS settings, or nil to use the defaults
+func (e *Endpoint) ClientTLS() *tls.Config {
+	return e.tlsConfig
+}
+
+// resolveAuth builds the endpoint's headers and TLS settings, resolving secrets from store
+func (e *Endpoint) resolveAuth(store *secrets.Store) error {
+	e.header = make(http.Header)
+	for name, value := range e.Headers {
+		e.header.Set(name, value)
+	}
+	for name, secret := range e.SecretHeaders {
+		value, err := store.Get(secret)
+		if err != nil {
+			return fmt.Errorf("header %s: %w", name, err)
+		}
+		e.header.Set(name, value)
+	}
+
+	if e.Auth != nil {
+		if e.header.Get("Authorization") != "" {
+			return fmt.Errorf("auth and an Authorization header are mutually exclusive")
+		}
+		switch strings.ToLower(e.Auth.Type) {
+		case "basic":
+			if e.Auth.Username == "" || e.Auth.PasswordSecret == "" {
+				return fmt.Errorf("basic auth requires username and password_secret")
+			}
+			password, err := store.Get(e.Auth.PasswordSecret)
+			if err != nil {
+				return fmt.Errorf("basic auth: %w", err)
+			}
+			credentials := base64.StdEncoding.EncodeToString([]byte(e.Auth.Username + ":" + password))
+			e.header.Set("Authorization", "Basic "+credentials)
+		case "bearer":
+			if e.Auth.TokenSecret == "" {
+				return fmt.Errorf("bearer auth requires token_secret")
+			}
+			token, err := store.Get(e.Auth.TokenSecret)
+			if err != nil {
+				return fmt.Errorf("bearer auth: %w", err)
+			}
+			e.header.Set("Authorization", "Bearer "+token)
+		default:
+			return fmt.Errorf("unknown auth type: %q", e.Auth.Type)
+		}
+	}
+
+	if e.TLS != nil {
+		tlsConfig, err := e.TLS.build(store)
+		if err != nil {
+			return fmt.Errorf("tls: %w", err)
+		}
+		e.tlsConfig = tlsConfig
+	}
+	return nil
+}
+
+func (c *TLSConfig) build(store *secrets.Store) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.ServerName,
+	}
+
+	if (c.CertSecret == "") != (c.KeySecret == "") {
+		return nil, fmt.Errorf("cert_secret and key_secret must be set together")
+	}
+	if c.CertSecret != "" {
+		certPEM, err := store.Get(c.CertSecret)
+		if err != nil {
+			return nil, err
+		}
+		keyPEM, err := store.Get(c.KeySecret)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if c.CASecret != "" {
+		caPEM, err := store.Get(c.CASecret)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caPEM)) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", c.CASecret)
+		}
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
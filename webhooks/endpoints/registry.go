@@ -0,0 +1,199 @@
+package endpoints
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"text/template"
+	"time"
+
+	"webhook-service/envelope"
+	"webhook-service/secrets"
+	"webhook-service/urlmatch"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// disabledKeyPrefix is the Redis key prefix marking a registered endpoint as disabled
+const disabledKeyPrefix = "webhook:endpoint:disabled:"
+
+// Endpoint is a registered webhook receiver. Deliveries to a URL with the same
+// scheme, host and port, and a path at or below the endpoint's, are treated as
+// going to that endpoint.
+type Endpoint struct {
+	Name         string `json:"name"`
+	URL          string `json:"url"`
+	Format       string `json:"format,omitempty"`        // Payload format (see envelope.Format); default "json"
+	Template     string `json:"template,omitempty"`      // Inline payload template for the "template" format
+	TemplateFile string `json:"template_file,omitempty"` // Or a file containing it
+	ContentType  string `json:"content_type,omitempty"`  // Content-Type for templated payloads; default application/json
+	Sink         string `json:"sink,omitempty"`          // Deliver to this sink (e.g. "redis-stream") instead of the request URL
+	Target       string `json:"target,omitempty"`        // Stream or channel name for the sink
+
+	// HTTP delivery settings. Secrets are referenced by name (see secrets.Store).
+	Headers       map[string]string `json:"headers,omitempty"`        // Static headers
+	SecretHeaders map[string]string `json:"secret_headers,omitempty"` // Header name to secret name
+	Auth          *AuthConfig       `json:"auth,omitempty"`
+	TLS           *TLSConfig        `json:"tls,omitempty"`
+
+	url       *url.URL
+	format    envelope.Format
+	tmpl      *template.Template
+	header    http.Header
+	tlsConfig *tls.Config
+}
+
+// Registry holds the registered endpoints and their disabled state
+type Registry struct {
+	rdb       *redis.Client
+	endpoints []Endpoint
+}
+
+// Load reads registered endpoints from a JSON file containing an array of
+// endpoints, resolving their secrets from store. An empty path gives an empty registry.
+func Load(path string, rdb *redis.Client, store *secrets.Store) (*Registry, error) {
+	r := &Registry{rdb: rdb}
+	if path == "" {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read endpoints file: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.endpoints); err != nil {
+		return nil, fmt.Errorf("parse endpoints file: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, e := range r.endpoints {
+		if e.Name == "" || e.URL == "" {
+			return nil, fmt.Errorf("endpoint requires name and url: %+v", e)
+		}
+		if (e.Sink == "") != (e.Target == "") {
+			return nil, fmt.Errorf("endpoint %s: sink and target must be set together", e.Name)
+		}
+		if seen[e.Name] {
+			return nil, fmt.Errorf("duplicate endpoint name: %s", e.Name)
+		}
+		seen[e.Name] = true
+	}
+
+	for i := range r.endpoints {
+		if err := r.endpoints[i].parseURL(); err != nil {
+			return nil, fmt.Errorf("endpoint %s: %w", r.endpoints[i].Name, err)
+		}
+		if err := r.endpoints[i].parsePayload(); err != nil {
+			return nil, fmt.Errorf("endpoint %s: %w", r.endpoints[i].Name, err)
+		}
+		if err := r.endpoints[i].resolveAuth(store); err != nil {
+			return nil, fmt.Errorf("endpoint %s: %w", r.endpoints[i].Name, err)
+		}
+	}
+
+	return r, nil
+}
+
+// parseURL parses the endpoint's URL, which must be absolute
+func (e *Endpoint) parseURL() error {
+	u, err := urlmatch.Parse(e.URL)
+	if err != nil {
+		return err
+	}
+	e.url = u
+	return nil
+}
+
+// parsePayload validates the endpoint's payload format and parses its template
+func (e *Endpoint) parsePayload() error {
+	format, err := envelope.ParseFormat(e.Format)
+	if err != nil {
+		return err
+	}
+	e.format = format
+
+	if e.TemplateFile != "" {
+		if e.Template != "" {
+			return fmt.Errorf("template and template_file are mutually exclusive")
+		}
+		data, err := os.ReadFile(e.TemplateFile)
+		if err != nil {
+			return fmt.Errorf("read template file: %w", err)
+		}
+		e.Template = string(data)
+	}
+
+	if format != envelope.FormatTemplate {
+		if e.Template != "" {
+			return fmt.Errorf("template requires format %q", envelope.FormatTemplate)
+		}
+		return nil
+	}
+	if e.Template == "" {
+		return fmt.Errorf("format %q requires template or template_file", envelope.FormatTemplate)
+	}
+	e.tmpl, err = envelope.ParseTemplate(e.Name, e.Template)
+	if err != nil {
+		return fmt.Errorf("parse template: %w", err)
+	}
+	return nil
+}
+
+// Encode builds the request body and headers for an event sent to this endpoint
+func (e *Endpoint) Encode(enc *envelope.Encoder, ev envelope.Event) (*envelope.Request, error) {
+	return enc.Encode(e.format, e.tmpl, e.ContentType, ev)
+}
+
+// SinkURL returns the sink URL deliveries to this endpoint are redirected to, or nil
+func (e *Endpoint) SinkURL() *url.URL {
+	if e.Sink == "" {
+		return nil
+	}
+	return &url.URL{Scheme: e.Sink, Host: e.Target}
+}
+
+// Len returns the number of registered endpoints
+func (r *Registry) Len() int {
+	return len(r.endpoints)
+}
+
+// All returns the registered endpoints
+func (r *Registry) All() []Endpoint {
+	return r.endpoints
+}
+
+// Match returns the registered endpoint for a delivery URL, preferring the
+// one with the longest path. It returns nil if no endpoint matches.
+func (r *Registry) Match(rawURL string) *Endpoint {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	var best *Endpoint
+	for i := range r.endpoints {
+		e := &r.endpoints[i]
+		if urlmatch.Under(e.url, u) && (best == nil || len(e.url.Path) > len(best.url.Path)) {
+			best = e
+		}
+	}
+	return best
+}
+
+// Disabled returns the reason an endpoint was disabled, or "" if it is enabled
+func (r *Registry) Disabled(ctx context.Context, e *Endpoint) (string, error) {
+	reason, err := r.rdb.Get(ctx, disabledKeyPrefix+e.Name).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return reason, err
+}
+
+// Disable marks an endpoint as disabled until the key is removed
+func (r *Registry) Disable(ctx context.Context, e *Endpoint, reason string) error {
+	value := fmt.Sprintf("%s at %s", reason, time.Now().UTC().Format(time.RFC3339))
+	return r.rdb.Set(ctx, disabledKeyPrefix+e.Name, value, 0).Err()
+}
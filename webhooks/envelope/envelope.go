@@ -0,0 +1,167 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Format selects how an event is encoded into a webhook request
+type Format string
+
+const (
+	FormatJSON                  Format = "json"                   // The event body as a JSON object (default)
+	FormatCloudEventsStructured Format = "cloudevents-structured" // CloudEvents 1.0, structured content mode
+	FormatCloudEventsBinary     Format = "cloudevents-binary"     // CloudEvents 1.0, binary content mode
+	FormatTemplate              Format = "template"               // A user-defined text/template
+)
+
+// cloudEventsSpecVersion is the CloudEvents version produced
+const cloudEventsSpecVersion = "1.0"
+
+// Event is a command event to be delivered
+type Event struct {
+	ID          string         // Unique event ID, stable across retries
+	CommandID   string         // Command the event belongs to
+	Status      string         // Command status (e.g. "SUCCESS")
+	Sequence    int64          // Position in the command's events (0 if unordered)
+	ReferenceID string         // Caller's reference ID, if any
+	Time        time.Time      // When the event was produced
+	Body        map[string]any // Full event body
+}
+
+// TemplateData is the data available to payload templates
+type TemplateData struct {
+	EventID     string
+	CommandID   string
+	Status      string
+	Sequence    int64
+	ReferenceID string
+	Time        time.Time
+	Outputs     any            // The body's output_files
+	Body        map[string]any // The full event body
+}
+
+// Request is an encoded event ready to send
+type Request struct {
+	Body   []byte
+	Header http.Header
+}
+
+// ParseFormat validates a format name, defaulting to FormatJSON
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case "":
+		return FormatJSON, nil
+	case FormatJSON, FormatCloudEventsStructured, FormatCloudEventsBinary, FormatTemplate:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown payload format: %q", s)
+	}
+}
+
+// ParseTemplate parses a payload template. Templates can use the "json"
+// function to encode any value, e.g. {{ json .Outputs }}.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=zero").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(text)
+}
+
+// Encoder turns events into requests
+type Encoder struct {
+	Source string // CloudEvents source attribute
+}
+
+// Encode builds the request for an event. tmpl and contentType are only used with FormatTemplate.
+func (e *Encoder) Encode(format Format, tmpl *template.Template, contentType string, ev Event) (*Request, error) {
+	header := make(http.Header)
+
+	switch format {
+	case FormatJSON, "":
+		body, err := json.Marshal(ev.Body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal body: %w", err)
+		}
+		header.Set("Content-Type", "application/json")
+		return &Request{Body: body, Header: header}, nil
+
+	case FormatCloudEventsStructured:
+		event := map[string]any{
+			"specversion":     cloudEventsSpecVersion,
+			"id":              ev.ID,
+			"source":          e.Source,
+			"type":            eventType(ev.Status),
+			"subject":         ev.CommandID,
+			"time":            ev.Time.UTC().Format(time.RFC3339Nano),
+			"datacontenttype": "application/json",
+			"data":            ev.Body,
+		}
+		if ev.Sequence > 0 {
+			event["sequence"] = strconv.FormatInt(ev.Sequence, 10)
+		}
+		body, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("marshal cloudevent: %w", err)
+		}
+		header.Set("Content-Type", "application/cloudevents+json")
+		return &Request{Body: body, Header: header}, nil
+
+	case FormatCloudEventsBinary:
+		body, err := json.Marshal(ev.Body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal body: %w", err)
+		}
+		header.Set("Content-Type", "application/json")
+		header.Set("ce-specversion", cloudEventsSpecVersion)
+		header.Set("ce-id", ev.ID)
+		header.Set("ce-source", e.Source)
+		header.Set("ce-type", eventType(ev.Status))
+		header.Set("ce-subject", ev.CommandID)
+		header.Set("ce-time", ev.Time.UTC().Format(time.RFC3339Nano))
+		if ev.Sequence > 0 {
+			header.Set("ce-sequence", strconv.FormatInt(ev.Sequence, 10))
+		}
+		return &Request{Body: body, Header: header}, nil
+
+	case FormatTemplate:
+		if tmpl == nil {
+			return nil, fmt.Errorf("template format requires a template")
+		}
+		var buf bytes.Buffer
+		err := tmpl.Execute(&buf, TemplateData{
+			EventID:     ev.ID,
+			CommandID:   ev.CommandID,
+			Status:      ev.Status,
+			Sequence:    ev.Sequence,
+			ReferenceID: ev.ReferenceID,
+			Time:        ev.Time,
+			Outputs:     ev.Body["output_files"],
+			Body:        ev.Body,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("execute template: %w", err)
+		}
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		header.Set("Content-Type", contentType)
+		return &Request{Body: buf.Bytes(), Header: header}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown payload format: %q", format)
+	}
+}
+
+// eventType maps a command status to a CloudEvents type, e.g. "SUCCESS" -> "burrowcode.command.success"
+func eventType(status string) string {
+	return "burrowcode.command." + strings.ToLower(status)
+}
@@ -2,16 +2,18 @@ module webhook-service
 
 go 1.25
 
-require github.com/hibiken/asynq v0.25.1
+require (
+	github.com/hibiken/asynq v0.25.1
+	github.com/redis/go-redis/v9 v9.7.0
+	golang.org/x/time v0.8.0
+)
 
 require (
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 	github.com/google/uuid v1.6.0 // indirect
-	github.com/redis/go-redis/v9 v9.7.0 // indirect
 	github.com/robfig/cron/v3 v3.0.1 // indirect
 	github.com/spf13/cast v1.7.0 // indirect
 	golang.org/x/sys v0.27.0 // indirect
-	golang.org/x/time v0.8.0 // indirect
 	google.golang.org/protobuf v1.35.2 // indirect
 )
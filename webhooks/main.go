@@ -3,75 +3,195 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
-	"os"
-	"strconv"
-	"strings"
+	"net/url"
 	"time"
 
+	"webhook-service/circuit"
+	"webhook-service/config"
+	"webhook-service/deliveries"
+	"webhook-service/egress"
+	"webhook-service/endpoints"
+	"webhook-service/envelope"
+	"webhook-service/ordering"
+	"webhook-service/retry"
+	"webhook-service/secrets"
+	"webhook-service/sinks"
+
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
 )
 
 const TypeWebhookDeliver = "webhook:deliver"
 
 type WebhookPayload struct {
-	URL       string         `json:"url"`
-	CommandID string         `json:"command_id"`
-	Status    string         `json:"status"`
-	Body      map[string]any `json:"body"`
+	URL         string         `json:"url"`
+	CommandID   string         `json:"command_id"`
+	Status      string         `json:"status"`
+	Sequence    int64          `json:"sequence,omitempty"`
+	ReferenceID string         `json:"reference_id,omitempty"`
+	Body        map[string]any `json:"body"`
+	EnqueuedAt  time.Time      `json:"enqueued_at"`
+
+	// Set when the delivery is replayed from the DLQ as a new task
+	EventID    string    `json:"event_id,omitempty"`   // The original task's ID, kept as the event ID
+	ReplayedAt time.Time `json:"replayed_at,omitzero"` // Restarts the RETRY_MAX_AGE budget
+}
+
+// eventID returns the ID receivers see for the event, the same across retries and replays
+func (p *WebhookPayload) eventID(taskID string) string {
+	if p.EventID != "" {
+		return p.EventID
+	}
+	return taskID
 }
 
-var httpClient *http.Client
+// retriesSince returns when the delivery's retries started
+func (p *WebhookPayload) retriesSince() time.Time {
+	if !p.ReplayedAt.IsZero() {
+		return p.ReplayedAt
+	}
+	return p.EnqueuedAt
+}
+
+var (
+	cfg              *config.Config
+	httpClient       *http.Client
+	destPolicy       *egress.Policy
+	deliveryLog      *deliveries.Log
+	hostGuard        *circuit.Guard
+	retryPolicy      retry.Policy
+	endpointRegistry *endpoints.Registry
+	encoder          *envelope.Encoder
+	deliverySinks    sinks.Set
+	endpointClients  map[string]*http.Client // Per-endpoint clients with custom TLS settings
+	sequencer        *ordering.Sequencer
+)
 
 func main() {
-	redisAddr := getEnv("REDIS_ADDR", "localhost:6379")
-	concurrency := getEnvInt("CONCURRENCY", 10)
-	httpTimeout := getEnvInt("HTTP_TIMEOUT", 10)
-	healthPort := getEnv("HEALTH_PORT", "8081")
+	cfg = config.Load()
+
+	// Destinations are checked against the policy before each delivery and again at dial time
+	var err error
+	destPolicy, err = egress.NewPolicy(
+		cfg.Destinations.AllowedSchemes,
+		cfg.Destinations.AllowedHosts,
+		cfg.Destinations.AllowedPorts,
+		cfg.Destinations.BlockedCIDRs,
+		cfg.Destinations.MaxRedirects,
+	)
+	if err != nil {
+		log.Fatalf("Invalid destination policy: %v", err)
+	}
+	httpClient = destPolicy.Client(cfg.Delivery.HTTPTimeout)
+
+	// Delivery attempts are recorded in Redis so the API can report them
+	rdb := redis.NewClient(&redis.Options{Addr: cfg.Redis.Addr})
+	defer rdb.Close()
+	deliveryLog = deliveries.NewLog(rdb, time.Duration(cfg.Delivery.RetentionHours)*time.Hour, cfg.Delivery.LogBodyMaxBytes)
+
+	schedule, err := retry.ParseSchedule(cfg.Retry.Schedule)
+	if err != nil {
+		log.Fatalf("Invalid RETRY_SCHEDULE: %v", err)
+	}
+	retryPolicy = retry.Policy{
+		Schedule: schedule,
+		Base:     cfg.Retry.Base,
+		MaxDelay: cfg.Retry.MaxDelay,
+		Jitter:   cfg.Retry.Jitter,
+		MaxRetry: cfg.Retry.MaxRetry,
+		MaxAge:   cfg.Retry.MaxAge,
+	}
 
-	httpClient = &http.Client{
-		Timeout: time.Duration(httpTimeout) * time.Second,
+	endpointRegistry, err = endpoints.Load(cfg.EndpointsFile, rdb, &secrets.Store{Dir: cfg.SecretsDir})
+	if err != nil {
+		log.Fatalf("Failed to load endpoints: %v", err)
+	}
+	if endpointRegistry.Len() > 0 {
+		log.Printf("Registered endpoints: %d", endpointRegistry.Len())
 	}
 
+	encoder = &envelope.Encoder{Source: cfg.CloudEventsSource}
+
+	// Events go to the sink registered for their URL scheme
+	httpSink := &sinks.HTTPSink{Client: httpClient, MaxBodyBytes: deliveryLog.MaxBodyBytes()}
+	deliverySinks = sinks.Set{
+		"http":         httpSink,
+		"https":        httpSink,
+		"redis-stream": &sinks.StreamSink{RDB: rdb, Prefix: cfg.Sinks.StreamPrefix, MaxLen: cfg.Sinks.StreamMaxLen},
+		"redis-pubsub": &sinks.PubSubSink{RDB: rdb, Prefix: cfg.Sinks.ChannelPrefix},
+	}
+	endpointClients = make(map[string]*http.Client)
+	for _, e := range endpointRegistry.All() {
+		if tlsConfig := e.ClientTLS(); tlsConfig != nil {
+			endpointClients[e.Name] = destPolicy.TLSClient(cfg.Delivery.HTTPTimeout, tlsConfig)
+		}
+		if e.Sink == "" {
+			continue
+		}
+		if _, err := deliverySinks.For(e.Sink); err != nil || e.Sink == "http" || e.Sink == "https" {
+			log.Fatalf("Endpoint %s: unsupported sink %q", e.Name, e.Sink)
+		}
+	}
+
+	// Events for the same command are delivered in sequence order
+	sequencer = ordering.NewSequencer(rdb, cfg.Ordering.MaxWait, time.Duration(cfg.Delivery.RetentionHours)*time.Hour)
+
+	// Track health and load per destination host
+	hostGuard = circuit.NewGuard(circuit.Settings{
+		FailureThreshold: cfg.Hosts.FailureThreshold,
+		OpenDuration:     cfg.Hosts.OpenDuration,
+		MaxRPS:           cfg.Hosts.MaxRPS,
+		MaxInFlight:      cfg.Hosts.MaxInFlight,
+	})
+
 	// Start health check server in background
-	go startHealthServer(healthPort)
+	go startHealthServer(cfg.HealthPort)
 
 	srv := asynq.NewServer(
-		asynq.RedisClientOpt{Addr: redisAddr},
+		asynq.RedisClientOpt{Addr: cfg.Redis.Addr},
 		asynq.Config{
-			Concurrency: concurrency,
+			Concurrency: cfg.Delivery.Concurrency,
 			Queues:      map[string]int{"webhooks": 1},
+			// Deliveries deferred by the host guard or waiting for an earlier event are requeued
+			// without using up a retry. Everything else follows the retry policy; the handler
+			// returns SkipRetry once it's exhausted.
+			IsFailure: func(err error) bool {
+				var deferred *circuit.DeferredError
+				var waiting *ordering.DeferredError
+				return !errors.As(err, &deferred) && !errors.As(err, &waiting)
+			},
+			RetryDelayFunc: func(n int, err error, t *asynq.Task) time.Duration {
+				var deferred *circuit.DeferredError
+				if errors.As(err, &deferred) {
+					return deferred.RetryAfter
+				}
+				var waiting *ordering.DeferredError
+				if errors.As(err, &waiting) {
+					return waiting.RetryAfter
+				}
+				var delayed *retry.DelayedError
+				if errors.As(err, &delayed) {
+					return delayed.Delay
+				}
+				return retryPolicy.NextDelay(n, err)
+			},
 		},
 	)
 
 	mux := asynq.NewServeMux()
 	mux.HandleFunc(TypeWebhookDeliver, handleWebhookDeliver)
 
-	log.Printf("Webhook Service started (concurrency=%d, http_timeout=%ds, health_port=%s)", concurrency, httpTimeout, healthPort)
+	log.Printf("Webhook Service started (concurrency=%d, http_timeout=%s, health_port=%s, host_max_rps=%g, host_max_in_flight=%d)",
+		cfg.Delivery.Concurrency, cfg.Delivery.HTTPTimeout, cfg.HealthPort, cfg.Hosts.MaxRPS, cfg.Hosts.MaxInFlight)
 	if err := srv.Run(mux); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func getEnv(key, defaultVal string) string {
-	if val := os.Getenv(key); val != "" {
-		return val
-	}
-	return defaultVal
-}
-
-func getEnvInt(key string, defaultVal int) int {
-	if val := os.Getenv(key); val != "" {
-		if intVal, err := strconv.Atoi(val); err == nil {
-			return intVal
-		}
-	}
-	return defaultVal
-}
-
 func startHealthServer(port string) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -91,37 +211,203 @@ func handleWebhookDeliver(ctx context.Context, t *asynq.Task) error {
 		return fmt.Errorf("unmarshal payload: %w", err)
 	}
 
-	start := time.Now()
-
-	body, err := json.Marshal(payload.Body)
+	dest, err := url.Parse(payload.URL)
 	if err != nil {
-		return fmt.Errorf("marshal body: %w", err)
+		return fmt.Errorf("parse url: %v: %w", err, asynq.SkipRetry)
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	attempt := deliveries.Attempt{
+		Attempt:   retried + 1,
+		TaskID:    t.ResultWriter().TaskID(),
+		URL:       payload.URL,
+		Event:     payload.Status,
+		Timestamp: time.Now(),
+	}
+
+	// Once this event is delivered or given up on, the command's next event can go
+	defer func() {
+		if attempt.Final {
+			markSequenceDone(payload.CommandID, payload.Sequence)
+		}
+	}()
+
+	endpoint := endpointRegistry.Match(payload.URL)
+	if endpoint != nil {
+		reason, err := endpointRegistry.Disabled(ctx, endpoint)
+		if err != nil {
+			return fmt.Errorf("check endpoint %s: %w", endpoint.Name, err)
+		}
+		if reason != "" {
+			err := fmt.Errorf("endpoint %s is disabled: %s", endpoint.Name, reason)
+			attempt.Error = err.Error()
+			attempt.Final = true
+			recordAttempt(payload.CommandID, attempt)
+			return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+		}
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", payload.URL, strings.NewReader(string(body)))
+	// Registered endpoints can redirect their deliveries to another sink
+	target := dest
+	if endpoint != nil && endpoint.Sink != "" {
+		target = endpoint.SinkURL()
+	}
+	sink, err := deliverySinks.For(target.Scheme)
 	if err != nil {
-		log.Printf("[%s] error=%q", payload.CommandID, err.Error())
-		return fmt.Errorf("create request: %w", err)
+		attempt.Error = err.Error()
+		attempt.Final = true
+		recordAttempt(payload.CommandID, attempt)
+		return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := httpClient.Do(req)
-	duration := time.Since(start)
+	// HTTP destinations are checked against the full destination policy; other sinks
+	// only need an allowed scheme. Sinks set by a registered endpoint are trusted.
+	isHTTP := target.Scheme == "http" || target.Scheme == "https"
+	var policyErr error
+	switch {
+	case target != dest:
+	case isHTTP:
+		policyErr = destPolicy.CheckURL(dest)
+	default:
+		policyErr = destPolicy.CheckScheme(dest)
+		if policyErr == nil {
+			_, policyErr = sinks.TargetName(dest)
+		}
+	}
+	if policyErr != nil {
+		attempt.Error = policyErr.Error()
+		attempt.Final = true
+		recordAttempt(payload.CommandID, attempt)
+		return fmt.Errorf("%w: %w", policyErr, asynq.SkipRetry)
+	}
 
+	// A payload that can't be encoded (e.g. a template error) won't encode on retry either
+	req, err := encodePayload(&payload, endpoint, payload.eventID(attempt.TaskID))
 	if err != nil {
-		log.Printf("[%s] duration=%s error=%q", payload.CommandID, duration, err.Error())
-		return fmt.Errorf("http request failed: %w", err)
+		attempt.Error = err.Error()
+		attempt.Final = true
+		recordAttempt(payload.CommandID, attempt)
+		return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+	}
+
+	if err := sequencer.Check(ctx, payload.CommandID, payload.Sequence, payload.EnqueuedAt); err != nil {
+		log.Printf("[%s] %v", payload.CommandID, err)
+		return err
+	}
+
+	// Only HTTP hosts are guarded; the other sinks are internal services
+	release := func(healthy bool) {}
+	if isHTTP {
+		release, err = hostGuard.Acquire(ctx, target.Host)
+		if err != nil {
+			log.Printf("[%s] %v", payload.CommandID, err)
+			return err
+		}
 	}
-	defer resp.Body.Close()
 
-	// Drain and discard response body to allow connection reuse
-	io.Copy(io.Discard, resp.Body)
+	msg := &sinks.Message{
+		EventID:   payload.eventID(attempt.TaskID),
+		CommandID: payload.CommandID,
+		Status:    payload.Status,
+		Target:    target,
+		Request:   req,
+	}
+	if endpoint != nil {
+		msg.Header = endpoint.Header()
+		msg.Client = endpointClients[endpoint.Name]
+	}
+	err = sink.Deliver(ctx, msg, &attempt)
+	release(hostHealthy(err, attempt.HTTPStatus))
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		log.Printf("[%s] duration=%s status=%d", payload.CommandID, duration, resp.StatusCode)
-		return fmt.Errorf("webhook returned non-2xx status: %d", resp.StatusCode)
+	if err == nil {
+		attempt.Success = true
+		attempt.Final = true
+		recordAttempt(payload.CommandID, attempt)
+		return nil
 	}
+	attempt.Error = err.Error()
 
-	log.Printf("[%s] duration=%s status=%d success=true", payload.CommandID, duration, resp.StatusCode)
-	return nil
+	// 410 Gone means the receiver is never coming back
+	if attempt.HTTPStatus == http.StatusGone {
+		attempt.Final = true
+		recordAttempt(payload.CommandID, attempt)
+		if endpoint != nil {
+			if disableErr := endpointRegistry.Disable(ctx, endpoint, "410 Gone"); disableErr != nil {
+				log.Printf("[%s] Failed to disable endpoint %s: %v", payload.CommandID, endpoint.Name, disableErr)
+			} else {
+				log.Printf("[%s] Endpoint %s disabled after 410 Gone", payload.CommandID, endpoint.Name)
+			}
+		}
+		return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+	}
+
+	// Destinations rejected at dial time (e.g. resolving to a private address) won't change on retry
+	if errors.Is(err, egress.ErrDenied) {
+		attempt.Final = true
+		recordAttempt(payload.CommandID, attempt)
+		return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+	}
+
+	next := retryPolicy.NextDelay(retried, err)
+	attempt.Final = retryPolicy.Exhausted(retried, payload.retriesSince(), next)
+	recordAttempt(payload.CommandID, attempt)
+	if attempt.Final {
+		return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+	}
+	return &retry.DelayedError{Err: err, Delay: next}
+}
+
+func recordAttempt(commandID string, attempt deliveries.Attempt) {
+	if err := deliveryLog.Record(commandID, attempt); err != nil {
+		log.Printf("[%s] Failed to record delivery attempt: %v", commandID, err)
+	}
+}
+
+func markSequenceDone(commandID string, sequence int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sequencer.Done(ctx, commandID, sequence); err != nil {
+		log.Printf("[%s] Failed to record delivered sequence %d: %v", commandID, sequence, err)
+	}
+}
+
+// hostHealthy reports whether a delivery outcome says the host is up. Client
+// errors other than timeouts and throttling are the receiver rejecting the
+// payload, not a sign the host is down.
+func hostHealthy(err error, status int) bool {
+	if err == nil || errors.Is(err, egress.ErrDenied) {
+		return true
+	}
+	switch {
+	case status == 0:
+		return false
+	case status == http.StatusRequestTimeout, status == http.StatusTooManyRequests:
+		return false
+	case status >= 500:
+		return false
+	default:
+		return true
+	}
+}
+
+// encodePayload builds the request body and headers in the endpoint's payload
+// format. Unregistered URLs get the plain JSON body.
+func encodePayload(payload *WebhookPayload, endpoint *endpoints.Endpoint, eventID string) (*envelope.Request, error) {
+	eventTime := payload.EnqueuedAt
+	if eventTime.IsZero() {
+		eventTime = time.Now()
+	}
+	ev := envelope.Event{
+		ID:          eventID,
+		CommandID:   payload.CommandID,
+		Status:      payload.Status,
+		Sequence:    payload.Sequence,
+		ReferenceID: payload.ReferenceID,
+		Time:        eventTime,
+		Body:        payload.Body,
+	}
+	if endpoint == nil {
+		return encoder.Encode(envelope.FormatJSON, nil, "", ev)
+	}
+	return endpoint.Encode(encoder, ev)
 }
@@ -0,0 +1,119 @@
+package ordering
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// deliveredKeyPrefix is the Redis key prefix holding the last finished sequence per command
+const deliveredKeyPrefix = "webhook:sequence:delivered:"
+
+// retryAfter is how soon a deferred event is retried
+const retryAfter = 2 * time.Second
+
+// markDone raises the last finished sequence, never lowering it
+var markDone = redis.NewScript(`
+local last = tonumber(redis.call("GET", KEYS[1]) or "0")
+if tonumber(ARGV[1]) > last then
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+if tonumber(ARGV[2]) > 0 then
+	redis.call("EXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// DeferredError is returned when an earlier event for the same command hasn't
+// finished yet. It should be retried without counting as a failure.
+type DeferredError struct {
+	CommandID  string
+	Sequence   int64
+	Waiting    int64 // The sequence that must finish first
+	RetryAfter time.Duration
+}
+
+func (e *DeferredError) Error() string {
+	return fmt.Sprintf("event %d for command %s waiting for event %d", e.Sequence, e.CommandID, e.Waiting)
+}
+
+// Sequencer delivers each command's events in sequence order. An event is held
+// back until the previous one has been delivered or given up on, or until it
+// has waited MaxWait, after which it is delivered anyway so a lost event can't
+// block the command forever. Each such release is logged.
+type Sequencer struct {
+	store   store
+	maxWait time.Duration
+}
+
+// store keeps the last finished sequence per command
+type store interface {
+	last(ctx context.Context, commandID string) (int64, error)
+	markDone(ctx context.Context, commandID string, sequence int64) error
+}
+
+// NewSequencer creates a sequencer. State is kept in Redis for retention.
+func NewSequencer(rdb *redis.Client, maxWait, retention time.Duration) *Sequencer {
+	return &Sequencer{store: &redisStore{rdb: rdb, retention: retention}, maxWait: maxWait}
+}
+
+// Check returns a *DeferredError if the event must wait for an earlier one.
+// Events without a sequence (0) are never held back. Events older than the last
+// finished one are let through; receivers discard them by sequence.
+func (s *Sequencer) Check(ctx context.Context, commandID string, sequence int64, enqueuedAt time.Time) error {
+	if sequence <= 1 {
+		return nil
+	}
+
+	last, err := s.store.last(ctx, commandID)
+	if err != nil {
+		return err
+	}
+	if sequence <= last+1 {
+		return nil
+	}
+	if waited := time.Since(enqueuedAt); s.maxWait > 0 && waited > s.maxWait {
+		log.Printf("[ordering] %s: releasing event %d after %s, event %d hasn't finished",
+			commandID, sequence, waited.Round(time.Second), last+1)
+		return nil
+	}
+
+	return &DeferredError{
+		CommandID:  commandID,
+		Sequence:   sequence,
+		Waiting:    last + 1,
+		RetryAfter: retryAfter,
+	}
+}
+
+// Done records that an event has finished, whether it was delivered or given up on
+func (s *Sequencer) Done(ctx context.Context, commandID string, sequence int64) error {
+	if sequence == 0 {
+		return nil
+	}
+	return s.store.markDone(ctx, commandID, sequence)
+}
+
+// redisStore keeps sequences in Redis, expiring them after retention
+type redisStore struct {
+	rdb       *redis.Client
+	retention time.Duration
+}
+
+func (r *redisStore) last(ctx context.Context, commandID string) (int64, error) {
+	last, err := r.rdb.Get(ctx, deliveredKeyPrefix+commandID).Int64()
+	if err != nil && err != redis.Nil {
+		return 0, fmt.Errorf("get sequence: %w", err)
+	}
+	return last, nil
+}
+
+func (r *redisStore) markDone(ctx context.Context, commandID string, sequence int64) error {
+	key := deliveredKeyPrefix + commandID
+	seconds := strconv.Itoa(int(r.retention.Seconds()))
+	return markDone.Run(ctx, r.rdb, []string{key}, sequence, seconds).Err()
+}
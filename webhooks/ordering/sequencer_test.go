@@ -0,0 +1,118 @@
+package ordering
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore keeps sequences in memory, raising them like the Redis script does
+type memStore struct {
+	mu   sync.Mutex
+	done map[string]int64
+}
+
+func (m *memStore) last(ctx context.Context, commandID string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.done[commandID], nil
+}
+
+func (m *memStore) markDone(ctx context.Context, commandID string, sequence int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.done[commandID] = max(m.done[commandID], sequence)
+	return nil
+}
+
+func newTestSequencer(maxWait time.Duration) *Sequencer {
+	return &Sequencer{store: &memStore{done: make(map[string]int64)}, maxWait: maxWait}
+}
+
+// waiting returns the sequence an event is held back for, or 0 if it may go
+func waiting(t *testing.T, s *Sequencer, commandID string, sequence int64, enqueuedAt time.Time) int64 {
+	t.Helper()
+	err := s.Check(context.Background(), commandID, sequence, enqueuedAt)
+	if err == nil {
+		return 0
+	}
+	var deferred *DeferredError
+	if !errors.As(err, &deferred) {
+		t.Fatalf("Check(%d) = %v, want nil or *DeferredError", sequence, err)
+	}
+	return deferred.Waiting
+}
+
+func TestSequencerOutOfOrder(t *testing.T) {
+	s := newTestSequencer(5 * time.Minute)
+	ctx := context.Background()
+	now := time.Now()
+
+	// The second event arrives first and waits for the first
+	if w := waiting(t, s, "cmd", 2, now); w != 1 {
+		t.Errorf("event 2 before 1: waiting for %d, want 1", w)
+	}
+	if w := waiting(t, s, "cmd", 1, now); w != 0 {
+		t.Errorf("event 1: waiting for %d, want 0", w)
+	}
+	s.Done(ctx, "cmd", 1)
+	if w := waiting(t, s, "cmd", 2, now); w != 0 {
+		t.Errorf("event 2 after 1: waiting for %d, want 0", w)
+	}
+	if w := waiting(t, s, "cmd", 3, now); w != 2 {
+		t.Errorf("event 3 before 2: waiting for %d, want 2", w)
+	}
+
+	// Other commands aren't affected
+	if w := waiting(t, s, "other", 1, now); w != 0 {
+		t.Errorf("other command: waiting for %d, want 0", w)
+	}
+}
+
+func TestSequencerRetryOfEarlierEvent(t *testing.T) {
+	s := newTestSequencer(5 * time.Minute)
+	ctx := context.Background()
+	now := time.Now()
+
+	s.Done(ctx, "cmd", 1)
+	s.Done(ctx, "cmd", 2)
+	// A retry or replay of event 1 goes out; receivers discard it by sequence
+	if w := waiting(t, s, "cmd", 1, now); w != 0 {
+		t.Errorf("retried event 1: waiting for %d, want 0", w)
+	}
+	// Finishing it again doesn't lower the last finished sequence
+	s.Done(ctx, "cmd", 1)
+	if w := waiting(t, s, "cmd", 3, now); w != 0 {
+		t.Errorf("event 3 after a retried 1: waiting for %d, want 0", w)
+	}
+}
+
+func TestSequencerMaxWait(t *testing.T) {
+	tests := []struct {
+		maxWait time.Duration
+		waited  time.Duration
+		want    int64
+	}{
+		{5 * time.Minute, time.Minute, 1},
+		{5 * time.Minute, 6 * time.Minute, 0}, // Released, event 1 was lost
+		{0, 24 * time.Hour, 1},                // No limit
+	}
+	for _, tt := range tests {
+		s := newTestSequencer(tt.maxWait)
+		if w := waiting(t, s, "cmd", 2, time.Now().Add(-tt.waited)); w != tt.want {
+			t.Errorf("maxWait %s, waited %s: waiting for %d, want %d", tt.maxWait, tt.waited, w, tt.want)
+		}
+	}
+}
+
+func TestSequencerUnsequenced(t *testing.T) {
+	s := newTestSequencer(5 * time.Minute)
+	if w := waiting(t, s, "cmd", 0, time.Now()); w != 0 {
+		t.Errorf("unsequenced event: waiting for %d, want 0", w)
+	}
+	if err := s.Done(context.Background(), "cmd", 0); err != nil {
+		t.Errorf("Done(0) = %v", err)
+	}
+}
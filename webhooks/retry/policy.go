@@ -0,0 +1,152 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy decides when a failed delivery is retried and when it is given up
+type Policy struct {
+	Schedule []time.Duration // Explicit delays between attempts; overrides the exponential settings when set
+	Base     time.Duration   // Delay before the first retry with exponential backoff
+	MaxDelay time.Duration   // Upper bound for any single delay
+	Jitter   float64         // Random +/- fraction applied to exponential delays (e.g. 0.2)
+	MaxRetry int             // Retries before giving up with exponential backoff
+	MaxAge   time.Duration   // Give up once the delivery is this old (0 = no limit)
+}
+
+// Delay returns how long to wait before the next attempt, given how many retries were already made
+func (p Policy) Delay(retried int) time.Duration {
+	if len(p.Schedule) > 0 {
+		if retried >= len(p.Schedule) {
+			retried = len(p.Schedule) - 1
+		}
+		return p.capped(p.Schedule[retried])
+	}
+
+	// Clamp before adding jitter so large retry counts can't overflow
+	d := math.Min(float64(p.Base)*math.Pow(2, float64(retried)), float64(math.MaxInt64)/2)
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return p.capped(time.Duration(d))
+}
+
+// NextDelay returns the delay before the next attempt after err, honoring a
+// delay requested by the receiver over the schedule.
+func (p Policy) NextDelay(retried int, err error) time.Duration {
+	var after *AfterError
+	if errors.As(err, &after) {
+		return p.capped(after.After)
+	}
+	return p.Delay(retried)
+}
+
+// Exhausted reports whether a delivery that has failed after retried retries
+// should be given up rather than retried after next.
+func (p Policy) Exhausted(retried int, enqueuedAt time.Time, next time.Duration) bool {
+	limit := p.MaxRetry
+	if len(p.Schedule) > 0 {
+		limit = len(p.Schedule)
+	}
+	if retried >= limit {
+		return true
+	}
+	if p.MaxAge > 0 && !enqueuedAt.IsZero() && time.Since(enqueuedAt)+next > p.MaxAge {
+		return true
+	}
+	return false
+}
+
+func (p Policy) capped(d time.Duration) time.Duration {
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// ParseSchedule parses a comma-separated list of durations such as "10s,1m,5m,30m,2h,8h"
+func ParseSchedule(s string) ([]time.Duration, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var schedule []time.Duration
+	for _, part := range strings.Split(s, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry delay %q: %w", part, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("retry delay must be positive: %q", part)
+		}
+		schedule = append(schedule, d)
+	}
+	return schedule, nil
+}
+
+// AfterError wraps a delivery error with a delay requested by the receiver
+type AfterError struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *AfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", e.Err, e.After)
+}
+
+func (e *AfterError) Unwrap() error {
+	return e.Err
+}
+
+// DelayedError wraps a delivery error with the delay the handler chose for the
+// next attempt, so the jittered delay it checked against MaxAge is the one used
+type DelayedError struct {
+	Err   error
+	Delay time.Duration
+}
+
+func (e *DelayedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DelayedError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter returns the delay requested by a 429 or 503 response's
+// Retry-After header, given as seconds or an HTTP date.
+func RetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		// Huge values would overflow; the policy's MaxDelay caps them anyway
+		return time.Duration(min(secs, math.MaxInt64/int64(time.Second))) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		d := time.Until(at)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
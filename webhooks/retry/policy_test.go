@@ -0,0 +1,137 @@
+package retry
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDelaySchedule(t *testing.T) {
+	p := Policy{Schedule: []time.Duration{10 * time.Second, time.Minute, time.Hour}, MaxDelay: 30 * time.Minute}
+	tests := []struct {
+		retried int
+		want    time.Duration
+	}{
+		{0, 10 * time.Second},
+		{1, time.Minute},
+		{2, 30 * time.Minute}, // Capped by MaxDelay
+		{5, 30 * time.Minute}, // Past the end, the last delay repeats
+	}
+	for _, tt := range tests {
+		if got := p.Delay(tt.retried); got != tt.want {
+			t.Errorf("Delay(%d) = %s, want %s", tt.retried, got, tt.want)
+		}
+	}
+}
+
+func TestDelayExponential(t *testing.T) {
+	p := Policy{Base: 10 * time.Second, MaxDelay: time.Hour}
+	tests := []struct {
+		retried int
+		want    time.Duration
+	}{
+		{0, 10 * time.Second},
+		{1, 20 * time.Second},
+		{3, 80 * time.Second},
+		{20, time.Hour},
+		{5000, time.Hour}, // Doesn't overflow
+	}
+	for _, tt := range tests {
+		if got := p.Delay(tt.retried); got != tt.want {
+			t.Errorf("Delay(%d) = %s, want %s", tt.retried, got, tt.want)
+		}
+	}
+}
+
+func TestDelayJitter(t *testing.T) {
+	p := Policy{Base: 10 * time.Second, Jitter: 0.2}
+	for retried := range 5 {
+		base := p.Base << retried
+		lo, hi := base-base/5, base+base/5
+		for range 200 {
+			if got := p.Delay(retried); got < lo || got > hi {
+				t.Fatalf("Delay(%d) = %s, want within [%s, %s]", retried, got, lo, hi)
+			}
+		}
+	}
+}
+
+func TestNextDelay(t *testing.T) {
+	p := Policy{Base: 10 * time.Second, MaxDelay: time.Hour}
+	err := errors.New("status 500")
+	tests := []struct {
+		name string
+		err  error
+		want time.Duration
+	}{
+		{"schedule", err, 20 * time.Second},
+		{"retry-after", &AfterError{Err: err, After: 5 * time.Minute}, 5 * time.Minute},
+		{"retry-after above ceiling", &AfterError{Err: err, After: 48 * time.Hour}, time.Hour},
+		{"wrapped retry-after", &DelayedError{Err: &AfterError{Err: err, After: time.Second}}, time.Second},
+	}
+	for _, tt := range tests {
+		if got := p.NextDelay(1, tt.err); got != tt.want {
+			t.Errorf("%s: NextDelay = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExhausted(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		policy     Policy
+		retried    int
+		enqueuedAt time.Time
+		next       time.Duration
+		want       bool
+	}{
+		{"retries left", Policy{MaxRetry: 3}, 2, now, time.Minute, false},
+		{"retries used up", Policy{MaxRetry: 3}, 3, now, time.Minute, true},
+		{"schedule length", Policy{MaxRetry: 10, Schedule: []time.Duration{time.Second, time.Second}}, 2, now, time.Second, true},
+		{"within max age", Policy{MaxRetry: 10, MaxAge: time.Hour}, 1, now.Add(-50 * time.Minute), 5 * time.Minute, false},
+		{"next attempt past max age", Policy{MaxRetry: 10, MaxAge: time.Hour}, 1, now.Add(-50 * time.Minute), 15 * time.Minute, true},
+		{"no max age", Policy{MaxRetry: 10}, 1, now.Add(-48 * time.Hour), time.Hour, false},
+		{"unknown enqueue time", Policy{MaxRetry: 10, MaxAge: time.Hour}, 1, time.Time{}, time.Hour, false},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.Exhausted(tt.retried, tt.enqueuedAt, tt.next); got != tt.want {
+			t.Errorf("%s: Exhausted = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	date := time.Now().Add(2 * time.Minute).UTC().Format(http.TimeFormat)
+	past := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	tests := []struct {
+		status int
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{http.StatusTooManyRequests, "120", 2 * time.Minute, true},
+		{http.StatusServiceUnavailable, " 5 ", 5 * time.Second, true},
+		{http.StatusTooManyRequests, "99999999999999999", 9223372036 * time.Second, true}, // Clamped, not overflowed
+		{http.StatusTooManyRequests, past, 0, true},
+		{http.StatusTooManyRequests, "-1", 0, false},
+		{http.StatusTooManyRequests, "soon", 0, false},
+		{http.StatusTooManyRequests, "", 0, false},
+		{http.StatusInternalServerError, "120", 0, false}, // Only 429 and 503
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Header: http.Header{"Retry-After": {tt.header}}}
+		got, ok := RetryAfter(resp)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("RetryAfter(%d, %q) = %s, %v; want %s, %v", tt.status, tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	// An HTTP date is relative to now
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": {date}}}
+	got, ok := RetryAfter(resp)
+	if !ok || got < time.Minute || got > 2*time.Minute {
+		t.Errorf("RetryAfter(503, %q) = %s, %v; want about 2m", date, got, ok)
+	}
+}
@@ -0,0 +1,73 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"webhook-service/deliveries"
+	"webhook-service/retry"
+)
+
+// HTTPSink POSTs events to webhook URLs
+type HTTPSink struct {
+	Client       *http.Client // Used unless the message has its own
+	MaxBodyBytes int          // Response body bytes kept for the delivery log
+}
+
+func (s *HTTPSink) Name() string {
+	return "http"
+}
+
+func (s *HTTPSink) Deliver(ctx context.Context, msg *Message, attempt *deliveries.Attempt) error {
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", msg.Target.String(), bytes.NewReader(msg.Request.Body))
+	if err != nil {
+		log.Printf("[%s] error=%q", msg.CommandID, err.Error())
+		return fmt.Errorf("create request: %w", err)
+	}
+	for key, values := range msg.Header {
+		req.Header[key] = values
+	}
+	for key, values := range msg.Request.Header {
+		req.Header[key] = values
+	}
+
+	client := s.Client
+	if msg.Client != nil {
+		client = msg.Client
+	}
+	resp, err := client.Do(req)
+	duration := time.Since(start)
+	attempt.LatencyMS = duration.Milliseconds()
+
+	if err != nil {
+		log.Printf("[%s] duration=%s error=%q", msg.CommandID, duration, err.Error())
+		return fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	attempt.HTTPStatus = resp.StatusCode
+
+	// Keep the start of the body for the delivery log, then drain the rest to allow connection reuse
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, int64(s.MaxBodyBytes)))
+	attempt.ResponseBody = string(respBody)
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.Printf("[%s] duration=%s status=%d", msg.CommandID, duration, resp.StatusCode)
+		err := fmt.Errorf("webhook returned non-2xx status: %d", resp.StatusCode)
+		if after, ok := retry.RetryAfter(resp); ok {
+			return &retry.AfterError{Err: err, After: after}
+		}
+		return err
+	}
+
+	log.Printf("[%s] duration=%s status=%d success=true", msg.CommandID, duration, resp.StatusCode)
+	return nil
+}
@@ -0,0 +1,96 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"webhook-service/deliveries"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StreamSink appends events to Redis Streams. Entries get server-assigned IDs,
+// so consumer groups can read them with XREADGROUP and acknowledge with XACK.
+// The event_id field is stable across retries for consumers that deduplicate.
+type StreamSink struct {
+	RDB    *redis.Client
+	Prefix string // Prepended to the target name to form the stream key
+	MaxLen int64  // Approximate stream length to trim to (0 = no trimming)
+}
+
+func (s *StreamSink) Name() string {
+	return "redis-stream"
+}
+
+func (s *StreamSink) Deliver(ctx context.Context, msg *Message, attempt *deliveries.Attempt) error {
+	name, err := TargetName(msg.Target)
+	if err != nil {
+		return err
+	}
+
+	headers, err := json.Marshal(msg.Request.Header)
+	if err != nil {
+		return fmt.Errorf("marshal headers: %w", err)
+	}
+
+	start := time.Now()
+	id, err := s.RDB.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.Prefix + name,
+		MaxLen: s.MaxLen,
+		Approx: s.MaxLen > 0,
+		Values: map[string]any{
+			"event_id":     msg.EventID,
+			"command_id":   msg.CommandID,
+			"status":       msg.Status,
+			"content_type": msg.Request.Header.Get("Content-Type"),
+			"headers":      string(headers),
+			"body":         string(msg.Request.Body),
+		},
+	}).Result()
+	duration := time.Since(start)
+	attempt.LatencyMS = duration.Milliseconds()
+
+	if err != nil {
+		log.Printf("[%s] stream=%s duration=%s error=%q", msg.CommandID, s.Prefix+name, duration, err.Error())
+		return fmt.Errorf("xadd: %w", err)
+	}
+
+	attempt.ResponseBody = id
+	log.Printf("[%s] stream=%s id=%s duration=%s success=true", msg.CommandID, s.Prefix+name, id, duration)
+	return nil
+}
+
+// PubSubSink publishes event bodies to Redis pub/sub channels. Pub/sub has no
+// persistence: events published while nobody is subscribed are lost.
+type PubSubSink struct {
+	RDB    *redis.Client
+	Prefix string // Prepended to the target name to form the channel
+}
+
+func (s *PubSubSink) Name() string {
+	return "redis-pubsub"
+}
+
+func (s *PubSubSink) Deliver(ctx context.Context, msg *Message, attempt *deliveries.Attempt) error {
+	name, err := TargetName(msg.Target)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	receivers, err := s.RDB.Publish(ctx, s.Prefix+name, msg.Request.Body).Result()
+	duration := time.Since(start)
+	attempt.LatencyMS = duration.Milliseconds()
+
+	if err != nil {
+		log.Printf("[%s] channel=%s duration=%s error=%q", msg.CommandID, s.Prefix+name, duration, err.Error())
+		return fmt.Errorf("publish: %w", err)
+	}
+
+	attempt.ResponseBody = fmt.Sprintf("receivers=%d", receivers)
+	log.Printf("[%s] channel=%s receivers=%d duration=%s success=true", msg.CommandID, s.Prefix+name, receivers, duration)
+	return nil
+}
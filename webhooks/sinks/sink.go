@@ -0,0 +1,58 @@
+package sinks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"webhook-service/deliveries"
+	"webhook-service/envelope"
+)
+
+// Message is an encoded event addressed to a sink
+type Message struct {
+	EventID   string
+	CommandID string
+	Status    string
+	Target    *url.URL // Where to deliver; the scheme selects the sink
+	Request   *envelope.Request
+
+	// HTTP only: extra headers (e.g. auth) and a client with the endpoint's TLS settings
+	Header http.Header
+	Client *http.Client
+}
+
+// Sink delivers command events to one kind of destination
+type Sink interface {
+	// Name returns the sink name for logging
+	Name() string
+	// Deliver sends a message, filling in the outcome on attempt
+	Deliver(ctx context.Context, msg *Message, attempt *deliveries.Attempt) error
+}
+
+// Set maps URL schemes to the sink that handles them. New sinks (e.g. NATS or
+// AMQP) only need to implement Sink and be registered under their scheme.
+type Set map[string]Sink
+
+// For returns the sink for a URL scheme
+func (s Set) For(scheme string) (Sink, error) {
+	sink, ok := s[strings.ToLower(scheme)]
+	if !ok {
+		return nil, fmt.Errorf("no sink for scheme %q", scheme)
+	}
+	return sink, nil
+}
+
+// TargetName returns the stream or channel name addressed by a non-HTTP sink
+// URL, e.g. "orders" for redis-stream://orders.
+func TargetName(u *url.URL) (string, error) {
+	name := strings.Trim(u.Host+u.Path, "/")
+	if name == "" {
+		if name = u.Opaque; name == "" {
+			return "", fmt.Errorf("%s URL has no target name", u.Scheme)
+		}
+	}
+	return name, nil
+}
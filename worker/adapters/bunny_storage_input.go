@@ -0,0 +1,96 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"ffmpeg-worker/egress"
+)
+
+// BunnyStorageInputAdapter fetches bunny://zone/path inputs from Bunny Edge Storage
+type BunnyStorageInputAdapter struct {
+	StorageZone     string
+	StorageKey      string
+	StorageEndpoint string
+	client          *http.Client
+	limits          InputLimits
+}
+
+// NewBunnyStorageInputAdapter creates a Bunny Storage input adapter from the
+// same BUNNY_STORAGE_* environment variables as the storage adapter. Only the
+// configured zone can be read. Requests are made with client, which should
+// enforce the download egress policy.
+func NewBunnyStorageInputAdapter(client *http.Client, limits InputLimits) (*BunnyStorageInputAdapter, error) {
+	zone := os.Getenv("BUNNY_STORAGE_ZONE")
+	if zone == "" {
+		return nil, fmt.Errorf("BUNNY_STORAGE_ZONE is required")
+	}
+
+	key := os.Getenv("BUNNY_STORAGE_KEY")
+	if key == "" {
+		return nil, fmt.Errorf("BUNNY_STORAGE_KEY is required")
+	}
+
+	return &BunnyStorageInputAdapter{
+		StorageZone:     zone,
+		StorageKey:      key,
+		StorageEndpoint: getEnv("BUNNY_STORAGE_ENDPOINT", "storage.bunnycdn.com"),
+		client:          client,
+		limits:          limits,
+	}, nil
+}
+
+func (a *BunnyStorageInputAdapter) Name() string {
+	return "bunny-storage"
+}
+
+func (a *BunnyStorageInputAdapter) Fetch(ctx context.Context, u *url.URL, localPath string, opts FetchOptions) (ObjectInfo, error) {
+	maxBytes := a.limits.maxBytes(opts)
+
+	storagePath := strings.TrimPrefix(u.Path, "/")
+	if u.Host != a.StorageZone || storagePath == "" {
+		return ObjectInfo{}, fmt.Errorf("%w: bunny input must be bunny://%s/path", egress.ErrDenied, a.StorageZone)
+	}
+	// Dot segments could be resolved by the storage API into another path
+	if path.Clean("/"+storagePath) != "/"+storagePath {
+		return ObjectInfo{}, fmt.Errorf("%w: bunny input path must not contain . or .. segments", egress.ErrDenied)
+	}
+
+	ctx, watch, stop := idleTimeout(ctx, a.limits.IdleTimeout)
+	defer stop()
+
+	fileURL := &url.URL{Scheme: "https", Host: a.StorageEndpoint, Path: "/" + a.StorageZone + "/" + storagePath}
+	req, err := http.NewRequestWithContext(ctx, "GET", fileURL.String(), nil)
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("AccessKey", a.StorageKey)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("download failed: %w", stallError(ctx, err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return ObjectInfo{}, fmt.Errorf("download failed: %w", &StatusError{Code: resp.StatusCode, Body: string(body)})
+	}
+	if err := checkSize(resp.ContentLength, maxBytes); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	if err := writeLimited(localPath, watch(resp.Body), maxBytes); err != nil {
+		return ObjectInfo{}, stallError(ctx, err)
+	}
+
+	log.Printf("[bunny-storage] Fetched %s -> %s", storagePath, localPath)
+	return headerObjectInfo(resp.Header), nil
+}
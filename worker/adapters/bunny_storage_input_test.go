@@ -0,0 +1,97 @@
+package adapters
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ffmpeg-worker/egress"
+)
+
+// newBunnyTestServer returns a TLS server standing in for the storage API and
+// a client that trusts it under policy
+func newBunnyTestServer(t *testing.T, policy *egress.Policy, handler http.HandlerFunc) (*httptest.Server, *http.Client) {
+	t.Helper()
+	srv := httptest.NewTLSServer(handler)
+	t.Cleanup(srv.Close)
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	return srv, policy.TLSClient(10*time.Second, &tls.Config{RootCAs: roots})
+}
+
+func TestBunnyStorageInputFetch(t *testing.T) {
+	policy, err := egress.NewPolicy("", "", "", "none", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, client := newBunnyTestServer(t, policy, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/zone/videos/a.mp4" || r.Header.Get("AccessKey") != "key" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("data"))
+	})
+	a := &BunnyStorageInputAdapter{
+		StorageZone:     "zone",
+		StorageKey:      "key",
+		StorageEndpoint: strings.TrimPrefix(srv.URL, "https://"),
+		client:          client,
+	}
+
+	localPath := filepath.Join(t.TempDir(), "in")
+	u, _ := url.Parse("bunny://zone/videos/a.mp4")
+	if _, err := a.Fetch(context.Background(), u, localPath, FetchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(localPath); string(data) != "data" {
+		t.Errorf("content = %q, want data", data)
+	}
+
+	for _, rawURL := range []string{
+		"bunny://other/videos/a.mp4",
+		"bunny://zone/",
+		"bunny://zone/videos/../../other/a.mp4",
+		"bunny://zone/./a.mp4",
+	} {
+		u, _ := url.Parse(rawURL)
+		if _, err := a.Fetch(context.Background(), u, localPath, FetchOptions{}); !errors.Is(err, egress.ErrDenied) {
+			t.Errorf("Fetch(%s) = %v, want ErrDenied", rawURL, err)
+		}
+	}
+}
+
+func TestBunnyStorageInputEgressPolicy(t *testing.T) {
+	// The default policy blocks loopback, where the test server listens
+	policy, err := egress.NewPolicy("", "", "", egress.DefaultBlockedCIDRs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests int
+	srv, client := newBunnyTestServer(t, policy, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("data"))
+	})
+	a := &BunnyStorageInputAdapter{
+		StorageZone:     "zone",
+		StorageKey:      "key",
+		StorageEndpoint: strings.TrimPrefix(srv.URL, "https://"),
+		client:          client,
+	}
+
+	u, _ := url.Parse("bunny://zone/a.mp4")
+	if _, err := a.Fetch(context.Background(), u, filepath.Join(t.TempDir(), "in"), FetchOptions{}); !errors.Is(err, egress.ErrDenied) {
+		t.Errorf("Fetch = %v, want ErrDenied", err)
+	}
+	if requests != 0 {
+		t.Errorf("server got %d requests, want 0", requests)
+	}
+}
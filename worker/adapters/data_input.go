@@ -0,0 +1,79 @@
+package adapters
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"strings"
+
+	"ffmpeg-worker/egress"
+)
+
+// DataInputAdapter writes data: URIs (RFC 2397), whose content is part of the
+// request itself. It makes no network requests, so it is always enabled.
+type DataInputAdapter struct {
+	limits InputLimits
+}
+
+// NewDataInputAdapter creates a data: URI input adapter
+func NewDataInputAdapter(limits InputLimits) *DataInputAdapter {
+	return &DataInputAdapter{limits: limits}
+}
+
+func (a *DataInputAdapter) Name() string {
+	return "data"
+}
+
+func (a *DataInputAdapter) Fetch(ctx context.Context, u *url.URL, localPath string, opts FetchOptions) (ObjectInfo, error) {
+	mediaType, data, err := parseDataURI(u)
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("%w: %v", egress.ErrDenied, err)
+	}
+	if err := checkSize(int64(len(data)), a.limits.maxBytes(opts)); err != nil {
+		return ObjectInfo{}, err
+	}
+	if err := os.WriteFile(localPath, data, 0644); err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{ContentType: mediaType}, nil
+}
+
+// parseDataURI returns the media type and decoded content of a data: URI
+func parseDataURI(u *url.URL) (string, []byte, error) {
+	// The query and fragment are part of the data, not separate components
+	raw := strings.TrimPrefix(u.String(), u.Scheme+":")
+	meta, payload, ok := strings.Cut(raw, ",")
+	if !ok {
+		return "", nil, fmt.Errorf("data URI has no comma")
+	}
+
+	meta, isBase64 := strings.CutSuffix(meta, ";base64")
+	mediaType := "text/plain"
+	if meta != "" {
+		parsed, _, err := mime.ParseMediaType(meta)
+		if err != nil {
+			return "", nil, fmt.Errorf("data URI media type: %v", err)
+		}
+		mediaType = parsed
+	}
+
+	payload, err := url.PathUnescape(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("data URI: %v", err)
+	}
+	if !isBase64 {
+		return mediaType, []byte(payload), nil
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		// Padding is often left out
+		data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(payload, "="))
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("data URI: invalid base64: %v", err)
+	}
+	return mediaType, data, nil
+}
@@ -0,0 +1,95 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ffmpeg-worker/egress"
+)
+
+func TestParseDataURI(t *testing.T) {
+	tests := []struct {
+		uri       string
+		mediaType string
+		data      string
+	}{
+		{"data:,hello", "text/plain", "hello"},
+		{"data:text/vtt,WEBVTT%0A%0A00:00.000", "text/vtt", "WEBVTT\n\n00:00.000"},
+		{"data:text/plain;charset=utf-8,caf%C3%A9", "text/plain", "café"},
+		{"data:image/png;base64,iVBORw0KGgo=", "image/png", "\x89PNG\r\n\x1a\n"},
+		{"data:image/png;base64,iVBORw0KGgo", "image/png", "\x89PNG\r\n\x1a\n"}, // Missing padding
+		{"data:Text/VTT,a", "text/vtt", "a"},
+		{"data:,a?b#c", "text/plain", "a?b#c"}, // Query and fragment are data
+		{"data:,a,b", "text/plain", "a,b"},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mediaType, data, err := parseDataURI(u)
+		if err != nil {
+			t.Errorf("parseDataURI(%q): %v", tt.uri, err)
+			continue
+		}
+		if mediaType != tt.mediaType || string(data) != tt.data {
+			t.Errorf("parseDataURI(%q) = %q, %q; want %q, %q", tt.uri, mediaType, data, tt.mediaType, tt.data)
+		}
+	}
+}
+
+func TestParseDataURIErrors(t *testing.T) {
+	for _, uri := range []string{
+		"data:text/plain",            // No comma
+		"data:image/png;base64,!!!!", // Not base64
+		"data:te xt/plain,a",         // Bad media type
+	} {
+		u, err := url.Parse(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := parseDataURI(u); err == nil {
+			t.Errorf("parseDataURI(%q) succeeded", uri)
+		}
+	}
+}
+
+func TestDataInputFetch(t *testing.T) {
+	a := NewDataInputAdapter(InputLimits{MaxBytes: 10})
+	tests := []struct {
+		uri      string
+		maxBytes int64
+		wantErr  bool
+	}{
+		{"data:,0123456789", 0, false},
+		{"data:,0123456789A", 0, true},     // Over the adapter's limit
+		{"data:;base64,AAAAAAAA", 5, true}, // 6 decoded bytes, over the input's limit
+		{"data:;base64,AAAA", 5, false},    // The decoded size counts, not the URI's
+		{"data:text/plain", 0, true},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.uri)
+		localPath := filepath.Join(t.TempDir(), "in")
+		info, err := a.Fetch(context.Background(), u, localPath, FetchOptions{MaxBytes: tt.maxBytes})
+		if tt.wantErr {
+			if !errors.Is(err, egress.ErrDenied) {
+				t.Errorf("Fetch(%q) = %v, want ErrDenied", tt.uri, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Fetch(%q): %v", tt.uri, err)
+			continue
+		}
+		if info.ContentType != "text/plain" {
+			t.Errorf("Fetch(%q) content type = %q, want text/plain", tt.uri, info.ContentType)
+		}
+		if _, err := os.Stat(localPath); err != nil {
+			t.Errorf("Fetch(%q) wrote nothing: %v", tt.uri, err)
+		}
+	}
+}
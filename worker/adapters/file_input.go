@@ -0,0 +1,93 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ffmpeg-worker/egress"
+)
+
+// FileInputAdapter copies file:// inputs from allowlisted local directories,
+// such as shared volumes mounted into the worker
+type FileInputAdapter struct {
+	Roots  []string
+	limits InputLimits
+}
+
+// NewFileInputAdapter creates a file input adapter. INPUT_FILE_ROOTS lists the
+// directories inputs may be read from.
+func NewFileInputAdapter(limits InputLimits) (*FileInputAdapter, error) {
+	var roots []string
+	for _, root := range strings.Split(os.Getenv("INPUT_FILE_ROOTS"), ",") {
+		if root = strings.TrimSpace(root); root == "" {
+			continue
+		}
+		resolved, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			return nil, fmt.Errorf("input file root %s: %w", root, err)
+		}
+		roots = append(roots, resolved)
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("INPUT_FILE_ROOTS is required")
+	}
+
+	return &FileInputAdapter{Roots: roots, limits: limits}, nil
+}
+
+func (a *FileInputAdapter) Name() string {
+	return "file"
+}
+
+func (a *FileInputAdapter) Fetch(ctx context.Context, u *url.URL, localPath string, opts FetchOptions) (ObjectInfo, error) {
+	maxBytes := a.limits.maxBytes(opts)
+
+	if u.Host != "" && u.Host != "localhost" {
+		return ObjectInfo{}, fmt.Errorf("%w: file input must be a local path", egress.ErrDenied)
+	}
+
+	// Resolve symlinks so a link inside a root can't point outside it
+	path, err := filepath.EvalSymlinks(filepath.Clean(u.Path))
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("%w: %v", egress.ErrDenied, err)
+	}
+	if !a.allowed(path) {
+		return ObjectInfo{}, fmt.Errorf("%w: %s is outside the allowed input directories", egress.ErrDenied, u.Path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if !stat.Mode().IsRegular() {
+		return ObjectInfo{}, fmt.Errorf("%w: %s is not a regular file", egress.ErrDenied, u.Path)
+	}
+	if err := checkSize(stat.Size(), maxBytes); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	if err := writeLimited(localPath, f, maxBytes); err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{Filename: filepath.Base(path)}, nil
+}
+
+func (a *FileInputAdapter) allowed(path string) bool {
+	for _, root := range a.Roots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"ffmpeg-worker/egress"
+)
+
+// HTTPInputAdapter downloads inputs over HTTP(S). When a transfer is retried,
+// it resumes from the partial file with a Range request if the server supports
+// it. The If-Range validator is kept next to the partial file, so it's cleaned
+// up along with the job directory.
+type HTTPInputAdapter struct {
+	client *http.Client
+	policy *egress.Policy
+	limits InputLimits
+}
+
+// NewHTTPInputAdapter creates an HTTP input adapter. URLs are checked against
+// policy before connecting; client should enforce it at dial time too.
+func NewHTTPInputAdapter(client *http.Client, policy *egress.Policy, limits InputLimits) *HTTPInputAdapter {
+	return &HTTPInputAdapter{client: client, policy: policy, limits: limits}
+}
+
+func (a *HTTPInputAdapter) Name() string {
+	return "http"
+}
+
+func (a *HTTPInputAdapter) Fetch(ctx context.Context, u *url.URL, localPath string, opts FetchOptions) (ObjectInfo, error) {
+	maxBytes := a.limits.maxBytes(opts)
+
+	if err := a.policy.CheckURL(u); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	ctx, watch, stop := idleTimeout(ctx, a.limits.IdleTimeout)
+	defer stop()
+
+	// Only resume when the server gave a validator, so a changed file is fetched again in full
+	var offset int64
+	validator := readValidator(localPath)
+	if info, err := os.Stat(localPath); err == nil && validator != "" {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	for name, values := range opts.Header {
+		req.Header[name] = values
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", validator)
+	}
+
+	resp, err := a.clientFor(opts).Do(req)
+	if err != nil {
+		return ObjectInfo{}, stallError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	var f *os.File
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0 && contentRangeStart(resp) == offset:
+		if err := checkSize(offset+resp.ContentLength, maxBytes); err != nil {
+			return ObjectInfo{}, err
+		}
+		f, err = os.OpenFile(localPath, os.O_WRONLY|os.O_APPEND, 0)
+	case resp.StatusCode == http.StatusOK:
+		offset = 0
+		if err := checkSize(resp.ContentLength, maxBytes); err != nil {
+			return ObjectInfo{}, err
+		}
+		writeValidator(localPath, resumeValidator(resp))
+		f, err = os.Create(localPath)
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file no longer lines up with the server's; start over next time
+		writeValidator(localPath, "")
+		os.Remove(localPath)
+		return ObjectInfo{}, fmt.Errorf("resume rejected (status %d)", resp.StatusCode)
+	default:
+		return ObjectInfo{}, &StatusError{Code: resp.StatusCode}
+	}
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	err = copyLimited(f, watch(resp.Body), offset, maxBytes)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return ObjectInfo{}, stallError(ctx, err)
+	}
+
+	writeValidator(localPath, "")
+	return headerObjectInfo(resp.Header), nil
+}
+
+// Open requests the object, applying the egress policy like Fetch does. Only
+// the headers in opts are removed on redirects to other hosts; ranges are kept.
+func (a *HTTPInputAdapter) Open(ctx context.Context, u *url.URL, opts FetchOptions, ranges http.Header) (*http.Response, error) {
+	if err := a.policy.CheckURL(u); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range opts.Header {
+		req.Header[name] = values
+	}
+	for _, name := range []string{"Range", "If-Range"} {
+		if v := ranges.Get(name); v != "" {
+			req.Header.Set(name, v)
+		}
+	}
+	return a.clientFor(opts).Do(req)
+}
+
+// Version returns the object's strong ETag or Last-Modified date. It uses a
+// one-byte range request rather than HEAD, since presigned URLs are often only
+// valid for GET.
+func (a *HTTPInputAdapter) Version(ctx context.Context, u *url.URL, opts FetchOptions) (string, error) {
+	if err := a.policy.CheckURL(u); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	for name, values := range opts.Header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Range", "bytes=0-0")
+
+	resp, err := a.clientFor(opts).Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return "", &StatusError{Code: resp.StatusCode}
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return "etag:" + etag, nil
+	}
+	if modified := resp.Header.Get("Last-Modified"); modified != "" {
+		return "modified:" + modified, nil
+	}
+	return "", nil
+}
+
+// clientFor returns a client that only sends the requested headers to the
+// original host. net/http already drops Authorization and Cookie on
+// cross-domain redirects, but not custom headers such as API keys.
+func (a *HTTPInputAdapter) clientFor(opts FetchOptions) *http.Client {
+	if len(opts.Header) == 0 {
+		return a.client
+	}
+	client := *a.client
+	checkRedirect := client.CheckRedirect
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if req.URL.Host != via[0].URL.Host {
+			for name := range opts.Header {
+				req.Header.Del(name)
+			}
+		}
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		return nil
+	}
+	return &client
+}
+
+func readValidator(localPath string) string {
+	data, err := os.ReadFile(localPath + ".validator")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// writeValidator saves the If-Range validator for a partial file, or removes it when empty
+func writeValidator(localPath, validator string) {
+	if validator == "" {
+		os.Remove(localPath + ".validator")
+		return
+	}
+	os.WriteFile(localPath+".validator", []byte(validator), 0644)
+}
+
+// resumeValidator returns a value for If-Range: a strong ETag, or else Last-Modified.
+// It returns "" if the server doesn't support ranges.
+func resumeValidator(resp *http.Response) string {
+	if resp.Header.Get("Accept-Ranges") != "bytes" {
+		return ""
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// contentRangeStart returns the first byte position of a Content-Range header, or -1
+func contentRangeStart(resp *http.Response) int64 {
+	rest, ok := strings.CutPrefix(resp.Header.Get("Content-Range"), "bytes ")
+	if !ok {
+		return -1
+	}
+	start, _, ok := strings.Cut(rest, "-")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
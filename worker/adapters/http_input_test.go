@@ -0,0 +1,188 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ffmpeg-worker/egress"
+)
+
+const testContent = "0123456789"
+
+// newTestAdapter returns an HTTP adapter whose policy allows loopback servers
+func newTestAdapter(t *testing.T) *HTTPInputAdapter {
+	t.Helper()
+	policy, err := egress.NewPolicy("", "", "", "none", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewHTTPInputAdapter(policy.Client(10*time.Second), policy, InputLimits{})
+}
+
+// serveContent serves testContent with a strong ETag, honouring Range and If-Range
+func serveContent(ranges *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ranges != nil {
+			*ranges = append(*ranges, r.Header.Get("Range"))
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(testContent))
+	}
+}
+
+func fetchURL(t *testing.T, a *HTTPInputAdapter, rawURL, localPath string, opts FetchOptions) error {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = a.Fetch(context.Background(), u, localPath, opts)
+	return err
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestHTTPInputResumesInterruptedTransfer(t *testing.T) {
+	var requests atomic.Int32
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			// Send half the body, then drop the connection
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Content-Length", "10")
+			w.Write([]byte(testContent[:5]))
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		serveContent(&ranges)(w, r)
+	}))
+	defer srv.Close()
+
+	a := newTestAdapter(t)
+	localPath := filepath.Join(t.TempDir(), "in")
+	if err := fetchURL(t, a, srv.URL, localPath, FetchOptions{}); err == nil {
+		t.Fatal("interrupted fetch succeeded")
+	}
+	if got := readFile(t, localPath+".validator"); got != `"v1"` {
+		t.Errorf("validator after interruption = %q, want %q", got, `"v1"`)
+	}
+
+	if err := fetchURL(t, a, srv.URL, localPath, FetchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=5-" {
+		t.Errorf("resume ranges = %q, want [bytes=5-]", ranges)
+	}
+	if got := readFile(t, localPath); got != testContent {
+		t.Errorf("content = %q, want %q", got, testContent)
+	}
+	if _, err := os.Stat(localPath + ".validator"); !os.IsNotExist(err) {
+		t.Errorf("validator not removed after success: %v", err)
+	}
+}
+
+func TestHTTPInputResume(t *testing.T) {
+	tests := []struct {
+		name      string
+		partial   string
+		validator string // "" = no sidecar
+		wantRange string
+	}{
+		{"matching validator", "01234", `"v1"`, "bytes=5-"},
+		{"changed validator", "xxxxx", `"v0"`, "bytes=5-"}, // Server ignores the range and sends it all
+		{"no validator", "xxxxx", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ranges []string
+			srv := httptest.NewServer(serveContent(&ranges))
+			defer srv.Close()
+
+			localPath := filepath.Join(t.TempDir(), "in")
+			os.WriteFile(localPath, []byte(tt.partial), 0644)
+			if tt.validator != "" {
+				os.WriteFile(localPath+".validator", []byte(tt.validator), 0644)
+			}
+
+			if err := fetchURL(t, newTestAdapter(t), srv.URL, localPath, FetchOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			if len(ranges) != 1 || ranges[0] != tt.wantRange {
+				t.Errorf("ranges = %q, want [%s]", ranges, tt.wantRange)
+			}
+			if got := readFile(t, localPath); got != testContent {
+				t.Errorf("content = %q, want %q", got, testContent)
+			}
+		})
+	}
+}
+
+func TestHTTPInputResumeRejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+	}))
+	defer srv.Close()
+
+	localPath := filepath.Join(t.TempDir(), "in")
+	os.WriteFile(localPath, []byte("01234"), 0644)
+	os.WriteFile(localPath+".validator", []byte(`"v1"`), 0644)
+
+	if err := fetchURL(t, newTestAdapter(t), srv.URL, localPath, FetchOptions{}); err == nil {
+		t.Fatal("fetch succeeded")
+	}
+	for _, path := range []string{localPath, localPath + ".validator"} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s not removed: %v", filepath.Base(path), err)
+		}
+	}
+}
+
+func TestHTTPInputRedirectHeaders(t *testing.T) {
+	var got bytes.Buffer
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.WriteString(r.URL.Path + "=" + r.Header.Get("X-Api-Key") + ";")
+		w.Write([]byte(testContent))
+	}))
+	defer target.Close()
+	// Same IP, different port, so a different host
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/same":
+			http.Redirect(w, r, "/same-target", http.StatusFound)
+		case "/same-target":
+			got.WriteString(r.URL.Path + "=" + r.Header.Get("X-Api-Key") + ";")
+			w.Write([]byte(testContent))
+		default:
+			http.Redirect(w, r, target.URL+"/other-target", http.StatusFound)
+		}
+	}))
+	defer origin.Close()
+
+	a := newTestAdapter(t)
+	opts := FetchOptions{Header: http.Header{"X-Api-Key": {"secret"}}}
+	for _, path := range []string{"/same", "/other"} {
+		if err := fetchURL(t, a, origin.URL+path, filepath.Join(t.TempDir(), "in"), opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := "/same-target=secret;/other-target=;"; got.String() != want {
+		t.Errorf("headers after redirects = %q, want %q", got.String(), want)
+	}
+}
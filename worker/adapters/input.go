@@ -0,0 +1,243 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"ffmpeg-worker/egress"
+)
+
+// InputAdapter defines the interface for fetching input files from storage
+type InputAdapter interface {
+	// Name returns the adapter name for logging
+	Name() string
+	// Fetch downloads the object addressed by u to localPath
+	Fetch(ctx context.Context, u *url.URL, localPath string, opts FetchOptions) (ObjectInfo, error)
+}
+
+// ObjectInfo describes a fetched object, as far as its store reports it
+type ObjectInfo struct {
+	ContentType string // MIME type, e.g. "video/mp4"
+	Filename    string // Name suggested by the store, e.g. in Content-Disposition
+}
+
+// headerObjectInfo reads an object's type and name from HTTP response headers
+func headerObjectInfo(h http.Header) ObjectInfo {
+	return ObjectInfo{
+		ContentType: h.Get("Content-Type"),
+		Filename:    dispositionFilename(h.Get("Content-Disposition")),
+	}
+}
+
+// dispositionFilename returns the file name in a Content-Disposition value, without any directory
+func dispositionFilename(disposition string) string {
+	if disposition == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(disposition)
+	if err != nil {
+		return ""
+	}
+	name := params["filename"]
+	return name[strings.LastIndexAny(name, `/\`)+1:]
+}
+
+// InputVersioner is implemented by input adapters that can tell which version
+// of an object a URL currently points to without downloading it
+type InputVersioner interface {
+	// Version returns a value that changes whenever the object's content does, or "" if unknown
+	Version(ctx context.Context, u *url.URL, opts FetchOptions) (string, error)
+}
+
+// InputStreamer is implemented by input adapters that can serve parts of an
+// object on demand, so ffmpeg can read it without downloading it first
+type InputStreamer interface {
+	// Open requests the object, adding ranges (Range and If-Range headers
+	// from the reader). The response is returned as is.
+	Open(ctx context.Context, u *url.URL, opts FetchOptions, ranges http.Header) (*http.Response, error)
+}
+
+// FetchOptions are per-input settings requested with the command
+type FetchOptions struct {
+	MaxBytes int64       // Size limit for this input, only applied if lower than InputLimits.MaxBytes (0 = none)
+	Header   http.Header // Extra request headers, for adapters that make HTTP requests on the caller's behalf
+}
+
+// InputLimits apply to every input adapter
+type InputLimits struct {
+	MaxBytes    int64         // Largest input accepted (0 = unlimited)
+	IdleTimeout time.Duration // Abort a transfer once no data has arrived for this long (0 = no limit)
+}
+
+// maxBytes returns the size limit for a fetch: the lower of the adapter's and the input's
+func (l InputLimits) maxBytes(opts FetchOptions) int64 {
+	if opts.MaxBytes > 0 && (l.MaxBytes <= 0 || opts.MaxBytes < l.MaxBytes) {
+		return opts.MaxBytes
+	}
+	return l.MaxBytes
+}
+
+// StatusError is returned when a remote store answers with an unexpected HTTP status
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body != "" {
+		return fmt.Sprintf("status %d: %s", e.Code, e.Body)
+	}
+	return fmt.Sprintf("status %d", e.Code)
+}
+
+// Retryable reports whether a fetch error may succeed if tried again. Policy
+// rejections, cancellation and client errors other than timeouts and throttling
+// are final.
+func Retryable(err error) bool {
+	if errors.Is(err, egress.ErrDenied) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.Code >= 500 || status.Code == http.StatusRequestTimeout || status.Code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// InputAdapters maps URL schemes to the adapter that fetches them
+type InputAdapters map[string]InputAdapter
+
+// For returns the adapter for a URL's scheme. Unsupported schemes are denied.
+func (a InputAdapters) For(u *url.URL) (InputAdapter, error) {
+	adapter, ok := a[strings.ToLower(u.Scheme)]
+	if !ok {
+		return nil, fmt.Errorf("%w: no input adapter for scheme %q", egress.ErrDenied, u.Scheme)
+	}
+	return adapter, nil
+}
+
+// NewInputAdapters creates the input adapters listed in the INPUT_ADAPTERS
+// environment variable (default "http,https"), plus the data: URI adapter.
+// HTTP(S) downloads use client, which should enforce the download egress
+// policy.
+func NewInputAdapters(client *http.Client, policy *egress.Policy, limits InputLimits) (InputAdapters, error) {
+	adapters := InputAdapters{"data": NewDataInputAdapter(limits)}
+	for _, name := range strings.Split(getEnv("INPUT_ADAPTERS", "http,https"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "", "data":
+			continue
+		case "http", "https":
+			adapters[name] = NewHTTPInputAdapter(client, policy, limits)
+		case "s3":
+			adapter, err := NewS3InputAdapter(limits)
+			if err != nil {
+				return nil, err
+			}
+			adapters[name] = adapter
+		case "bunny":
+			adapter, err := NewBunnyStorageInputAdapter(client, limits)
+			if err != nil {
+				return nil, err
+			}
+			adapters[name] = adapter
+		case "file":
+			adapter, err := NewFileInputAdapter(limits)
+			if err != nil {
+				return nil, err
+			}
+			adapters[name] = adapter
+		default:
+			return nil, fmt.Errorf("unknown input adapter: %s", name)
+		}
+	}
+	return adapters, nil
+}
+
+// checkSize rejects inputs whose known size is over the limit
+func checkSize(size, maxBytes int64) error {
+	if maxBytes > 0 && size > maxBytes {
+		return fmt.Errorf("%w: input is %d bytes, limit is %d", egress.ErrDenied, size, maxBytes)
+	}
+	return nil
+}
+
+// writeLimited copies r to localPath. The reported size can be missing or
+// wrong, so it also stops once more than maxBytes have been read.
+func writeLimited(localPath string, r io.Reader, maxBytes int64) error {
+	f, err := os.Create(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return copyLimited(f, r, 0, maxBytes)
+}
+
+// copyLimited copies r to w, where offset bytes have already been written
+func copyLimited(w io.Writer, r io.Reader, offset, maxBytes int64) error {
+	if maxBytes <= 0 {
+		_, err := io.Copy(w, r)
+		return err
+	}
+
+	remaining := maxBytes - offset
+	n, err := io.Copy(w, io.LimitReader(r, remaining+1))
+	if err != nil {
+		return err
+	}
+	if n > remaining {
+		return fmt.Errorf("%w: input exceeds limit of %d bytes", egress.ErrDenied, maxBytes)
+	}
+	return nil
+}
+
+// errStalled is the cancellation cause when a transfer goes idle
+var errStalled = errors.New("transfer stalled")
+
+// idleTimeout returns a context that is cancelled once d passes without data
+// arriving. Wrap response bodies with the returned function so every read
+// counts as activity, and call stop when the transfer ends.
+func idleTimeout(ctx context.Context, d time.Duration) (context.Context, func(io.Reader) io.Reader, func()) {
+	if d <= 0 {
+		return ctx, func(r io.Reader) io.Reader { return r }, func() {}
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := time.AfterFunc(d, func() { cancel(fmt.Errorf("%w: no data for %s", errStalled, d)) })
+	wrap := func(r io.Reader) io.Reader {
+		return &idleReader{r: r, timer: timer, d: d}
+	}
+	return ctx, wrap, func() {
+		timer.Stop()
+		cancel(nil)
+	}
+}
+
+// stallError returns the idle timeout cause if ctx was cancelled by it, otherwise err
+func stallError(ctx context.Context, err error) error {
+	if cause := context.Cause(ctx); errors.Is(cause, errStalled) {
+		return cause
+	}
+	return err
+}
+
+type idleReader struct {
+	r     io.Reader
+	timer *time.Timer
+	d     time.Duration
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.d)
+	}
+	return n, err
+}
@@ -45,24 +45,9 @@ func NewS3Adapter() (*S3Adapter, error) {
 	pathPrefix := os.Getenv("S3_PATH_PREFIX")
 	publicURL := os.Getenv("S3_PUBLIC_URL")
 
-	// Build AWS config
-	cfg, err := config.LoadDefaultConfig(context.Background(),
-		config.WithRegion(region),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
-	)
+	client, err := newS3Client(region, endpoint, accessKey, secretKey)
 	if err != nil {
-		return nil, fmt.Errorf("load aws config: %w", err)
-	}
-
-	// Create S3 client with optional custom endpoint
-	var client *s3.Client
-	if endpoint != "" {
-		client = s3.NewFromConfig(cfg, func(o *s3.Options) {
-			o.BaseEndpoint = aws.String(endpoint)
-			o.UsePathStyle = true // Required for most S3-compatible services
-		})
-	} else {
-		client = s3.NewFromConfig(cfg)
+		return nil, err
 	}
 
 	return &S3Adapter{
@@ -75,6 +60,28 @@ func NewS3Adapter() (*S3Adapter, error) {
 	}, nil
 }
 
+// newS3Client creates an S3 client with an optional custom endpoint. Without
+// static keys, the default AWS credential chain is used.
+func newS3Client(region, endpoint, accessKey, secretKey string) (*s3.Client, error) {
+	opts := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if accessKey != "" {
+		opts = append(opts, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")))
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("load aws config: %w", err)
+	}
+
+	if endpoint != "" {
+		return s3.NewFromConfig(cfg, func(o *s3.Options) {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true // Required for most S3-compatible services
+		}), nil
+	}
+	return s3.NewFromConfig(cfg), nil
+}
+
 func (a *S3Adapter) Name() string {
 	return "s3"
 }
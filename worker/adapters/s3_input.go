@@ -0,0 +1,126 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"ffmpeg-worker/egress"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3InputAdapter fetches s3://bucket/key inputs with the worker's own
+// credentials, so callers don't need to presign private objects
+type S3InputAdapter struct {
+	client         *s3.Client
+	allowedBuckets []string
+	limits         InputLimits
+}
+
+// NewS3InputAdapter creates an S3 input adapter from the same S3_* environment
+// variables as the S3 storage adapter. Keys are optional; without them the
+// default AWS credential chain (e.g. an instance role) is used.
+// INPUT_S3_ALLOWED_BUCKETS lists the buckets inputs may be read from.
+func NewS3InputAdapter(limits InputLimits) (*S3InputAdapter, error) {
+	client, err := newS3Client(
+		getEnv("S3_REGION", "us-east-1"),
+		os.Getenv("S3_ENDPOINT"),
+		os.Getenv("S3_ACCESS_KEY"),
+		os.Getenv("S3_SECRET_KEY"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []string
+	for _, b := range strings.Split(os.Getenv("INPUT_S3_ALLOWED_BUCKETS"), ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			buckets = append(buckets, b)
+		}
+	}
+
+	if len(buckets) == 0 {
+		return nil, fmt.Errorf("INPUT_S3_ALLOWED_BUCKETS is required")
+	}
+
+	return &S3InputAdapter{client: client, allowedBuckets: buckets, limits: limits}, nil
+}
+
+func (a *S3InputAdapter) Name() string {
+	return "s3"
+}
+
+func (a *S3InputAdapter) Fetch(ctx context.Context, u *url.URL, localPath string, opts FetchOptions) (ObjectInfo, error) {
+	maxBytes := a.limits.maxBytes(opts)
+
+	bucket, key, err := a.object(u)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	ctx, watch, stop := idleTimeout(ctx, a.limits.IdleTimeout)
+	defer stop()
+
+	out, err := a.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("get from s3: %w", stallError(ctx, err))
+	}
+	defer out.Body.Close()
+
+	if out.ContentLength != nil {
+		if err := checkSize(*out.ContentLength, maxBytes); err != nil {
+			return ObjectInfo{}, err
+		}
+	}
+
+	if err := writeLimited(localPath, watch(out.Body), maxBytes); err != nil {
+		return ObjectInfo{}, stallError(ctx, err)
+	}
+
+	log.Printf("[s3] Fetched s3://%s/%s -> %s", bucket, key, localPath)
+	return ObjectInfo{
+		ContentType: aws.ToString(out.ContentType),
+		Filename:    dispositionFilename(aws.ToString(out.ContentDisposition)),
+	}, nil
+}
+
+// Version returns the object's ETag
+func (a *S3InputAdapter) Version(ctx context.Context, u *url.URL, opts FetchOptions) (string, error) {
+	bucket, key, err := a.object(u)
+	if err != nil {
+		return "", err
+	}
+	out, err := a.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("head from s3: %w", err)
+	}
+	if out.ETag == nil {
+		return "", nil
+	}
+	return "etag:" + *out.ETag, nil
+}
+
+// object returns the bucket and key addressed by an s3:// URL, if allowed
+func (a *S3InputAdapter) object(u *url.URL) (string, string, error) {
+	bucket := u.Host
+	key := strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" {
+		return "", "", fmt.Errorf("%w: s3 input must be s3://bucket/key", egress.ErrDenied)
+	}
+	if !slices.Contains(a.allowedBuckets, bucket) {
+		return "", "", fmt.Errorf("%w: bucket %q not allowed", egress.ErrDenied, bucket)
+	}
+	return bucket, key, nil
+}
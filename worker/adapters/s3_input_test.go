@@ -0,0 +1,59 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ffmpeg-worker/egress"
+)
+
+func TestS3InputRequiresBucketAllowlist(t *testing.T) {
+	t.Setenv("INPUT_S3_ALLOWED_BUCKETS", " , ")
+	if _, err := NewS3InputAdapter(InputLimits{}); err == nil {
+		t.Error("NewS3InputAdapter without allowed buckets succeeded")
+	}
+
+	t.Setenv("INPUT_S3_ALLOWED_BUCKETS", "media, uploads")
+	a, err := NewS3InputAdapter(InputLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(a.allowedBuckets, ","); got != "media,uploads" {
+		t.Errorf("allowed buckets = %s, want media,uploads", got)
+	}
+}
+
+func TestS3InputBucketAllowlist(t *testing.T) {
+	a := &S3InputAdapter{allowedBuckets: []string{"media"}}
+	tests := []struct {
+		url     string
+		allowed bool
+	}{
+		{"s3://media/a.mp4", true},
+		{"s3://media/dir/a.mp4", true},
+		{"s3://other/a.mp4", false},
+		{"s3://Media/a.mp4", false}, // Bucket names are lowercase; no case folding
+		{"s3://media/", false},
+		{"s3:///a.mp4", false},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		_, _, err := a.object(u)
+		if tt.allowed && err != nil {
+			t.Errorf("object(%s) = %v, want allowed", tt.url, err)
+		}
+		if !tt.allowed && !errors.Is(err, egress.ErrDenied) {
+			t.Errorf("object(%s) = %v, want ErrDenied", tt.url, err)
+		}
+	}
+
+	// Denied before any request is made, so no client is needed
+	u, _ := url.Parse("s3://other/a.mp4")
+	if _, err := a.Fetch(context.Background(), u, filepath.Join(t.TempDir(), "in"), FetchOptions{}); !errors.Is(err, egress.ErrDenied) {
+		t.Errorf("Fetch(%s) = %v, want ErrDenied", u, err)
+	}
+}
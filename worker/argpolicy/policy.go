@@ -0,0 +1,331 @@
+package argpolicy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrDenied is wrapped by every error returned for arguments the policy rejects
+var ErrDenied = errors.New("ffmpeg argument denied")
+
+// DefaultDeniedOptions are options that read or write files of their own
+// choosing, or that would undo the protocol restrictions
+const DefaultDeniedOptions = "dump_attachment,protocol_whitelist,protocol_blacklist,http_proxy,safe,report,progress," +
+	"allowed_extensions,allowed_segment_extensions,extension_picky"
+
+// DefaultDeniedFilters are filters that open arbitrary files, sockets or plugins
+const DefaultDeniedFilters = "movie,amovie,sendcmd,asendcmd,zmq,azmq,ladspa,lv2,frei0r,frei0r_src"
+
+// DefaultDeniedFormats are the tee muxer, which writes to several URLs, and capture and playback devices
+const DefaultDeniedFormats = "tee,alsa,pulse,oss,sndio,jack,openal,fbdev,v4l2,video4linux2,x11grab,xcbgrab,kmsgrab," +
+	"sdl,sdl2,opengl,xv,caca,decklink,libcdio,dshow,gdigrab,avfoundation"
+
+// DefaultProtocols are the protocols inputs may use
+const DefaultProtocols = "file,crypto,data"
+
+// Policy restricts what an ffmpeg command line may do
+type Policy struct {
+	DeniedOptions []string // Option names without the dash or stream specifier, e.g. "dump_attachment"
+	DeniedFilters []string // Filter names, e.g. "movie"
+	DeniedFormats []string // Formats given with -f, for inputs and outputs
+	Protocols     []string // Protocols files may be opened with, also passed to ffmpeg as -protocol_whitelist
+
+	// Options are the options ffmpeg knows and whether each takes a value,
+	// from ParseHelp. Other options are rejected. If nil, any option is
+	// accepted and only the built-in flags are known not to take a value.
+	Options map[string]bool
+}
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// New builds a policy from comma-separated lists of names
+func New(options, filters, formats, protocols string) (*Policy, error) {
+	p := &Policy{
+		DeniedOptions: splitList(options),
+		DeniedFilters: splitList(filters),
+		DeniedFormats: splitList(formats),
+		Protocols:     splitList(strings.ToLower(protocols)),
+	}
+	for _, list := range [][]string{p.DeniedOptions, p.DeniedFilters, p.DeniedFormats, p.Protocols} {
+		for _, name := range list {
+			if !namePattern.MatchString(name) {
+				return nil, fmt.Errorf("invalid name: %q", name)
+			}
+		}
+	}
+	if !slices.Contains(p.Protocols, "file") {
+		return nil, fmt.Errorf("protocols must include file")
+	}
+	return p, nil
+}
+
+// Scope is where a command's files may be
+type Scope struct {
+	// Dir is the job directory ffmpeg runs in. Every file must be inside it.
+	// It is empty when checking arguments before placeholders are expanded:
+	// files must then be relative or start with a placeholder, and files
+	// holding option values can't be read yet.
+	Dir string
+
+	// Streams are loopback URLs of streamed inputs, which may be read with -i
+	Streams []string
+}
+
+// Check parses an ffmpeg argument list and rejects denied options, formats
+// and filters, and inputs and outputs that aren't files in the scope or
+// streamed inputs. Values of options that name files, such as filter scripts
+// and -/option values, are checked the same way.
+func (p *Policy) Check(args []string, scope Scope) error {
+	var format string // -f for the next input or output
+	for _, opt := range p.scan(args) {
+		if opt.name == "" {
+			if err := p.checkFormat(format); err != nil {
+				return err
+			}
+			if err := p.checkURL(opt.value, scope, false); err != nil {
+				return fmt.Errorf("output %s: %w", opt.value, err)
+			}
+			format = ""
+			continue
+		}
+		if slices.Contains(p.DeniedOptions, opt.name) {
+			return fmt.Errorf("%w: option -%s", ErrDenied, opt.name)
+		}
+		if !p.known(opt.name) {
+			return fmt.Errorf("%w: unknown option -%s", ErrDenied, opt.name)
+		}
+		if !opt.hasValue {
+			continue
+		}
+
+		value := opt.value
+		if opt.fromFile {
+			if err := scope.checkPath(value); err != nil {
+				return fmt.Errorf("-/%s: %w", opt.name, err)
+			}
+			if scope.Dir == "" {
+				continue
+			}
+			data, err := os.ReadFile(scope.resolve(value))
+			if err != nil {
+				return fmt.Errorf("-/%s: %w", opt.name, err)
+			}
+			value = string(data)
+		}
+
+		var err error
+		switch {
+		case opt.name == "i":
+			if err = p.checkFormat(format); err == nil {
+				if format == "lavfi" {
+					err = p.checkGraph(value, scope)
+				} else {
+					err = p.checkURL(value, scope, true)
+				}
+			}
+			format = ""
+		case opt.name == "f":
+			format = value
+		case graphOptions[opt.name]:
+			err = p.checkGraph(value, scope)
+		case scriptOptions[opt.name]:
+			err = p.checkScript(value, scope)
+		case pathOptions[opt.name]:
+			err = scope.checkPath(value)
+		case deviceOptions[opt.name]:
+		default:
+			// Options without a rule may still be given files
+			err = p.checkValue(value, scope)
+		}
+		if err != nil {
+			return fmt.Errorf("-%s: %w", opt.name, err)
+		}
+	}
+	return nil
+}
+
+// Restrict adds -protocol_whitelist before each -i, limiting inputs and the
+// files they refer to (such as playlist segments) to the policy's protocols.
+// Streamed inputs are left alone. Call it after Check.
+func (p *Policy) Restrict(args []string, scope Scope) []string {
+	whitelist := strings.Join(p.Protocols, ",")
+	out := make([]string, 0, len(args))
+	next := 0
+	for _, opt := range p.scan(args) {
+		if opt.name != "i" || (!opt.fromFile && slices.Contains(scope.Streams, opt.value)) {
+			continue
+		}
+		out = append(out, args[next:opt.index]...)
+		out = append(out, "-protocol_whitelist", whitelist)
+		next = opt.index
+	}
+	return append(out, args[next:]...)
+}
+
+// LimitThreads caps the threads ffmpeg uses: -threads is added before each
+// input and output, for decoders and encoders, and the filter thread options
+// before each output. They follow any the command sets, so they take effect.
+func (p *Policy) LimitThreads(args []string, threads int) []string {
+	n := strconv.Itoa(threads)
+	out := make([]string, 0, len(args))
+	next := 0
+	for _, opt := range p.scan(args) {
+		var limit []string
+		switch opt.name {
+		case "i":
+			limit = []string{"-threads", n}
+		case "":
+			limit = []string{"-threads", n, "-filter_threads", n, "-filter_complex_threads", n}
+		default:
+			continue
+		}
+		out = append(out, args[next:opt.index]...)
+		out = append(out, limit...)
+		next = opt.index
+	}
+	return append(out, args[next:]...)
+}
+
+func (p *Policy) checkFormat(format string) error {
+	if slices.Contains(p.DeniedFormats, format) {
+		return fmt.Errorf("%w: format %s", ErrDenied, format)
+	}
+	return nil
+}
+
+// checkURL checks an input or output: a file in the scope, a streamed input,
+// or a URL with an allowed protocol. "-" is standard input or output.
+func (p *Policy) checkURL(s string, scope Scope, input bool) error {
+	if s == "-" {
+		return nil
+	}
+	if input && slices.Contains(scope.Streams, s) {
+		return nil
+	}
+	proto, rest, ok := protocol(s)
+	if !ok {
+		return scope.checkPath(s)
+	}
+	name, _, nested := strings.Cut(proto, "+")
+	if !slices.Contains(p.Protocols, name) {
+		return fmt.Errorf("%w: protocol %s", ErrDenied, name)
+	}
+	switch {
+	case nested: // e.g. crypto+file:path
+		return p.checkURL(s[len(name)+1:], scope, input)
+	case name == "file":
+		return scope.checkPath(rest)
+	case name == "crypto" || name == "cache" || name == "async":
+		return p.checkURL(rest, scope, input)
+	case name == "concat":
+		for _, part := range strings.Split(rest, "|") {
+			if err := p.checkURL(part, scope, input); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return nil // data: or another protocol the operator allowed
+}
+
+// protocol splits a URL into its protocol and the rest the way ffmpeg does:
+// anything before a colon that could be a protocol name is taken as one.
+func protocol(s string) (string, string, bool) {
+	n := strings.IndexFunc(s, func(r rune) bool { return !strings.ContainsRune(schemeChars, r) })
+	switch {
+	case n > 0 && s[n] == ':':
+		return strings.ToLower(s[:n]), s[n+1:], true
+	case strings.HasPrefix(s, "subfile,") && strings.Contains(s, ":"):
+		return "subfile", s[strings.Index(s, ":")+1:], true
+	}
+	return "", "", false
+}
+
+const schemeChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789+-."
+
+// looksLikeFile reports whether an option value is probably a file or URL,
+// which is all that can be told about options the policy doesn't know
+func (p *Policy) looksLikeFile(s string) bool {
+	if strings.HasPrefix(s, "/") {
+		return true
+	}
+	proto, _, ok := protocol(s)
+	name, _, _ := strings.Cut(proto, "+")
+	return ok && (knownProtocols[name] || slices.Contains(p.Protocols, name))
+}
+
+// checkValue checks the value of an option without a rule. It may name a
+// file, so URLs must use allowed protocols, absolute paths must be in the
+// scope, and relative paths may not leave it.
+func (p *Policy) checkValue(s string, scope Scope) error {
+	if p.looksLikeFile(s) {
+		return p.checkURL(s, scope, true)
+	}
+	if slices.Contains(strings.Split(filepath.ToSlash(s), "/"), "..") {
+		return fmt.Errorf("%w: %s is outside the job directory", ErrDenied, s)
+	}
+	return nil
+}
+
+// checkScript checks a filter script file, and the filtergraph in it once it can be read
+func (p *Policy) checkScript(path string, scope Scope) error {
+	if err := scope.checkPath(path); err != nil {
+		return err
+	}
+	if scope.Dir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(scope.resolve(path))
+	if err != nil {
+		return err
+	}
+	return p.checkGraph(string(data), scope)
+}
+
+// checkPath requires a file to be inside the scope's directory. Before
+// placeholders are expanded, a leading placeholder stands for a file in it.
+func (s Scope) checkPath(path string) error {
+	rel := path
+	if s.Dir == "" && strings.HasPrefix(path, "{{") {
+		if end := strings.Index(path, "}}"); end > 0 {
+			rel = "." + path[end+2:]
+		}
+	}
+	if filepath.IsAbs(rel) {
+		if s.Dir == "" {
+			return fmt.Errorf("%w: %s is an absolute path, use a placeholder", ErrDenied, path)
+		}
+		var err error
+		if rel, err = filepath.Rel(s.Dir, rel); err != nil {
+			return fmt.Errorf("%w: %s is outside the job directory", ErrDenied, path)
+		}
+	}
+	if !filepath.IsLocal(rel) {
+		return fmt.Errorf("%w: %s is outside the job directory", ErrDenied, path)
+	}
+	return nil
+}
+
+// resolve returns the file a path refers to when ffmpeg runs in the scope's directory
+func (s Scope) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.Dir, path)
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -1,10 +1,18 @@
 package config
 
 import (
+	"cmp"
+	"maps"
+	"math"
 	"os"
+	"runtime"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	"ffmpeg-worker/argpolicy"
+	"ffmpeg-worker/egress"
 	"ffmpeg-worker/system"
 )
 
@@ -22,6 +30,18 @@ type Config struct {
 	// Webhook configuration
 	Webhook WebhookConfig
 
+	// Input download configuration
+	Download DownloadConfig
+
+	// Redaction of the original request in webhook payloads
+	Redact RedactConfig
+
+	// Restrictions on ffmpeg arguments
+	FFmpeg FFmpegConfig
+
+	// Directory of secret files referenced by input auth profiles
+	SecretsDir string
+
 	// Storage configuration (handled by adapter package)
 	StorageAdapter string
 }
@@ -42,18 +62,107 @@ type WorkerConfig struct {
 	TaskRetentionHours int
 }
 
-// ResourceConfig holds resource monitoring thresholds
+// ResourceConfig holds resource monitoring thresholds and the limits for each ffmpeg run
 type ResourceConfig struct {
 	Enabled          bool
 	MaxMemoryPercent float64
 	CheckInterval    time.Duration
+
+	// Applied to each ffmpeg run on Linux, those marked cgroups only by the sandbox with a cgroup (0 = unlimited)
+	JobMemoryBytes int64   // cgroup memory.max, or an address space limit without cgroups
+	JobCPUSeconds  int64   // CPU time before ffmpeg is killed
+	JobFileBytes   int64   // Largest file ffmpeg may write
+	JobOpenFiles   int64   // Open file descriptors
+	JobProcesses   int64   // Processes and threads (cgroups only)
+	JobCPUs        float64 // CPU cores (cgroups only)
+
+	// Requests choose a resource class, or get one estimated from their
+	// inputs, or DefaultClass
+	DefaultClass string
+	Classes      map[string]ResourceClass
+
+	// Weighted budget jobs are admitted against, by their class's weight
+	// (0 = unlimited). Defaults to the CPUs and MaxMemoryPercent of memory.
+	BudgetCPU      int64
+	BudgetMemoryMB int64
+}
+
+// ResourceClass holds what ffmpeg gets for requests of one resource class (0 = unlimited)
+type ResourceClass struct {
+	Threads     int     // Threads for decoders, encoders and filters, passed as -threads
+	MemoryBytes int64   // Lowers JobMemoryBytes
+	CPUs        float64 // Lowers JobCPUs
+	Nice        int     // CPU scheduling niceness, 1 to 19
+	IOPriority  int     // Best-effort I/O priority, 1 (high) to 7 (low); 0 follows Nice
+
+	// What a job of this class counts against the budget
+	WeightCPU      int64
+	WeightMemoryMB int64
+
+	// Requests without a class get the one with the lowest MaxWork above their
+	// estimated work, in input minutes times pixels relative to 1080p (0 = no limit)
+	MaxWork float64
 }
 
 // WebhookConfig holds webhook delivery settings
 type WebhookConfig struct {
-	MaxRetry       int
-	RetentionHours int
-	TimeoutSeconds int
+	RetentionHours      int
+	TimeoutSeconds      int
+	OmitOriginalRequest bool // Leave original_request out of webhook payloads
+
+	// Destination policy, shared with the webhooks service (see egress.NewPolicy)
+	AllowedSchemes string
+	AllowedHosts   string
+	AllowedPorts   string
+	BlockedCIDRs   string
+	MaxRedirects   int
+}
+
+// DownloadConfig holds the egress policy for input downloads (see egress.NewPolicy)
+type DownloadConfig struct {
+	AllowedSchemes   string
+	AllowedHosts     string
+	AllowedPorts     string
+	BlockedCIDRs     string
+	MaxRedirects     int
+	MaxBytes         int64         // Largest input accepted (0 = unlimited)
+	Concurrency      int           // Inputs downloaded at once per command
+	Retries          int           // Extra attempts per input after a transient failure
+	RetryBackoff     time.Duration // Delay before the first retry, doubling after each
+	ConnectTimeout   time.Duration // TCP connect timeout
+	IdleTimeout      time.Duration // Abort a transfer after this long without data (0 = no limit)
+	AuthProfilesFile string        // JSON file of named credentials inputs can reference (see inputs.AuthProfile)
+	CacheDir         string        // Directory for the shared input cache (empty = disabled)
+	CacheMaxBytes    int64         // Disk budget for the input cache
+	StreamProtocols  string        // URL schemes inputs may be streamed from (empty = always download)
+	StreamReconnect  time.Duration // Longest ffmpeg waits between reconnects to a streamed input
+	ArchiveMaxFiles  int           // Most entries an archive input may unpack to
+	ArchiveMaxBytes  int64         // Largest total size an archive input may unpack to
+}
+
+// RedactConfig controls what is removed from the original request before it is shared (see redact.New)
+type RedactConfig struct {
+	StripQuery   string // Field paths whose URLs lose their query string and credentials
+	DropFields   string // Field paths removed entirely
+	MaskPatterns string // Regular expressions masked in every string
+}
+
+// FFmpegConfig holds the policy commands' arguments are checked against (see argpolicy.New)
+type FFmpegConfig struct {
+	DeniedOptions    string // Options commands may not use
+	DeniedFilters    string // Filters commands may not use
+	DeniedFormats    string // Input and output formats commands may not use
+	AllowedProtocols string // Protocols ffmpeg may open files with
+
+	Executor     string        // How ffmpeg runs: "plain" or "sandbox" (see system.NewExecutor)
+	Env          string        // Environment variables passed to ffmpeg and ffprobe
+	ProbeTimeout time.Duration // Longest an ffprobe run may take
+
+	// Sandbox executor settings (see system.SandboxOptions)
+	SandboxUID          int
+	SandboxGID          int
+	SandboxHidePaths    string // Paths hidden besides the secrets, cache and auth profiles
+	SandboxCgroupParent string // Delegated cgroup v2 directory (empty = rlimits only)
 }
 
 // Load loads configuration from environment variables with sensible defaults
@@ -65,7 +174,7 @@ func Load() *Config {
 			DB:       getEnvInt("REDIS_DB", 0),
 		},
 		Worker: WorkerConfig{
-			Concurrency:        getEnvInt("CONCURRENCY", 2),
+			Concurrency:        getEnvInt("CONCURRENCY", 0),
 			WorkDir:            getEnv("WORK_DIR", "/tmp/ffmpeg-jobs"),
 			TaskMaxRetry:       getEnvInt("TASK_MAX_RETRY", 2),
 			TaskTimeoutMinutes: getEnvInt("TASK_TIMEOUT_MINUTES", 30),
@@ -75,12 +184,70 @@ func Load() *Config {
 			Enabled:          getEnvBool("RESOURCE_CHECK_ENABLED", true),
 			MaxMemoryPercent: getEnvFloat("MAX_MEMORY_PERCENT", 85.0),
 			CheckInterval:    time.Duration(getEnvInt("RESOURCE_CHECK_INTERVAL_SEC", 5)) * time.Second,
+			JobMemoryBytes:   getEnvInt64("JOB_MAX_MEMORY_BYTES", 0),
+			JobCPUSeconds:    getEnvInt64("JOB_MAX_CPU_SECONDS", 0),
+			JobFileBytes:     getEnvInt64("JOB_MAX_FILE_BYTES", 0),
+			JobOpenFiles:     getEnvInt64("JOB_MAX_OPEN_FILES", 4096),
+			JobProcesses:     getEnvInt64("JOB_MAX_PROCESSES", 0),
+			JobCPUs:          getEnvFloat("JOB_MAX_CPUS", 0),
+			DefaultClass:     getEnv("JOB_DEFAULT_CLASS", "medium"),
+			Classes: map[string]ResourceClass{
+				"small":  loadResourceClass("SMALL", ResourceClass{Threads: 2, Nice: 10, IOPriority: 7, WeightCPU: 1, WeightMemoryMB: 256, MaxWork: 1}),
+				"medium": loadResourceClass("MEDIUM", ResourceClass{Nice: 5, WeightCPU: 4, WeightMemoryMB: 1024, MaxWork: 30}),
+				"large":  loadResourceClass("LARGE", ResourceClass{WeightCPU: 16, WeightMemoryMB: 4096}),
+			},
+			BudgetCPU:      getEnvInt64("JOB_BUDGET_CPU", int64(runtime.NumCPU())),
+			BudgetMemoryMB: getEnvInt64("JOB_BUDGET_MEMORY_MB", defaultBudgetMemoryMB()),
 		},
 		Webhook: WebhookConfig{
-			MaxRetry:       getEnvInt("WEBHOOK_MAX_RETRY", 5),
-			RetentionHours: getEnvInt("WEBHOOK_RETENTION_HOURS", 72),
-			TimeoutSeconds: getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10),
+			RetentionHours:      getEnvInt("WEBHOOK_RETENTION_HOURS", 72),
+			TimeoutSeconds:      getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10),
+			OmitOriginalRequest: getEnvBool("WEBHOOK_OMIT_ORIGINAL_REQUEST", false),
+			AllowedSchemes:      getEnv("WEBHOOK_ALLOWED_SCHEMES", "http,https"),
+			AllowedHosts:        getEnv("WEBHOOK_ALLOWED_HOSTS", ""),
+			AllowedPorts:        getEnv("WEBHOOK_ALLOWED_PORTS", ""),
+			BlockedCIDRs:        getEnv("WEBHOOK_BLOCKED_CIDRS", egress.DefaultBlockedCIDRs),
+			MaxRedirects:        getEnvInt("WEBHOOK_MAX_REDIRECTS", 0),
 		},
+		Download: DownloadConfig{
+			AllowedSchemes:   getEnv("DOWNLOAD_ALLOWED_SCHEMES", "http,https"),
+			AllowedHosts:     getEnv("DOWNLOAD_ALLOWED_HOSTS", ""),
+			AllowedPorts:     getEnv("DOWNLOAD_ALLOWED_PORTS", ""),
+			BlockedCIDRs:     getEnv("DOWNLOAD_BLOCKED_CIDRS", egress.DefaultBlockedCIDRs),
+			MaxRedirects:     getEnvInt("DOWNLOAD_MAX_REDIRECTS", 5),
+			MaxBytes:         getEnvInt64("DOWNLOAD_MAX_BYTES", 20<<30),
+			Concurrency:      getEnvInt("DOWNLOAD_CONCURRENCY", 4),
+			Retries:          getEnvInt("DOWNLOAD_RETRIES", 3),
+			RetryBackoff:     time.Duration(getEnvInt("DOWNLOAD_RETRY_BACKOFF_SECONDS", 1)) * time.Second,
+			ConnectTimeout:   time.Duration(getEnvInt("DOWNLOAD_CONNECT_TIMEOUT_SECONDS", 10)) * time.Second,
+			IdleTimeout:      time.Duration(getEnvInt("DOWNLOAD_IDLE_TIMEOUT_SECONDS", 60)) * time.Second,
+			AuthProfilesFile: getEnv("INPUT_AUTH_PROFILES_FILE", ""),
+			CacheDir:         getEnv("INPUT_CACHE_DIR", ""),
+			CacheMaxBytes:    getEnvInt64("INPUT_CACHE_MAX_BYTES", 50<<30),
+			StreamProtocols:  getEnv("STREAM_ALLOWED_PROTOCOLS", "http,https"),
+			StreamReconnect:  time.Duration(getEnvInt("STREAM_RECONNECT_DELAY_MAX_SECONDS", 10)) * time.Second,
+			ArchiveMaxFiles:  getEnvInt("ARCHIVE_MAX_FILES", 10000),
+			ArchiveMaxBytes:  getEnvInt64("ARCHIVE_MAX_BYTES", 20<<30),
+		},
+		Redact: RedactConfig{
+			StripQuery:   getEnv("REDACT_STRIP_QUERY", "input_files.*,input_files.*.url"),
+			DropFields:   getEnv("REDACT_DROP_FIELDS", ""),
+			MaskPatterns: getEnv("REDACT_MASK_PATTERNS", ""),
+		},
+		FFmpeg: FFmpegConfig{
+			DeniedOptions:       getEnv("FFMPEG_DENIED_OPTIONS", argpolicy.DefaultDeniedOptions),
+			DeniedFilters:       getEnv("FFMPEG_DENIED_FILTERS", argpolicy.DefaultDeniedFilters),
+			DeniedFormats:       getEnv("FFMPEG_DENIED_FORMATS", argpolicy.DefaultDeniedFormats),
+			AllowedProtocols:    getEnv("FFMPEG_ALLOWED_PROTOCOLS", argpolicy.DefaultProtocols),
+			Executor:            getEnv("FFMPEG_EXECUTOR", "plain"),
+			Env:                 getEnv("FFMPEG_ENV", system.DefaultExecEnv),
+			ProbeTimeout:        time.Duration(getEnvInt("FFPROBE_TIMEOUT_SECONDS", 60)) * time.Second,
+			SandboxUID:          getEnvInt("SANDBOX_UID", 65534),
+			SandboxGID:          getEnvInt("SANDBOX_GID", 65534),
+			SandboxHidePaths:    getEnv("SANDBOX_HIDE_PATHS", ""),
+			SandboxCgroupParent: getEnv("SANDBOX_CGROUP_PARENT", ""),
+		},
+		SecretsDir:     getEnv("SECRETS_DIR", "/run/secrets"),
 		StorageAdapter: getEnv("STORAGE_ADAPTER", "file"),
 	}
 }
@@ -92,12 +259,130 @@ func (c *Config) GetResourceLimits() system.ResourceLimits {
 	}
 }
 
+// GetJobLimits converts config to the system.Limits for an ffmpeg run of the
+// given resource class. The class can only lower the JOB_MAX_* limits.
+func (c *Config) GetJobLimits(class ResourceClass) system.Limits {
+	return system.Limits{
+		MemoryBytes: lowerLimit(c.Resources.JobMemoryBytes, class.MemoryBytes),
+		CPUSeconds:  c.Resources.JobCPUSeconds,
+		FileBytes:   c.Resources.JobFileBytes,
+		OpenFiles:   c.Resources.JobOpenFiles,
+		Processes:   c.Resources.JobProcesses,
+		CPUs:        lowerLimit(c.Resources.JobCPUs, class.CPUs),
+		Nice:        class.Nice,
+		IOPriority:  class.IOPriority,
+	}
+}
+
+// GetJobBudget creates the budget jobs are admitted against
+func (c *Config) GetJobBudget() *system.Budget {
+	return system.NewBudget(system.Weight{CPU: c.Resources.BudgetCPU, MemoryMB: c.Resources.BudgetMemoryMB})
+}
+
+// GetConcurrency returns how many tasks the worker takes at once: CONCURRENCY
+// if set, or else as many commands of the lightest class as fit in the budget
+func (c *Config) GetConcurrency() int {
+	if c.Worker.Concurrency > 0 {
+		return c.Worker.Concurrency
+	}
+	concurrency := 0
+	for _, class := range c.Resources.Classes {
+		fits := math.MaxInt
+		if c.Resources.BudgetCPU > 0 && class.WeightCPU > 0 {
+			fits = min(fits, int(max(c.Resources.BudgetCPU/class.WeightCPU, 1)))
+		}
+		if c.Resources.BudgetMemoryMB > 0 && class.WeightMemoryMB > 0 {
+			fits = min(fits, int(max(c.Resources.BudgetMemoryMB/class.WeightMemoryMB, 1)))
+		}
+		concurrency = max(concurrency, fits)
+	}
+	if concurrency == 0 || concurrency == math.MaxInt {
+		return 2 // The budget doesn't limit commands
+	}
+	return concurrency
+}
+
+// EstimateClass returns the class for a command of the given estimated work
+// (see ResourceClass.MaxWork)
+func (c *Config) EstimateClass(work float64) string {
+	names := slices.Sorted(maps.Keys(c.Resources.Classes))
+	slices.SortStableFunc(names, func(a, b string) int {
+		return cmpMaxWork(c.Resources.Classes[a].MaxWork, c.Resources.Classes[b].MaxWork)
+	})
+	for _, name := range names {
+		if maxWork := c.Resources.Classes[name].MaxWork; maxWork <= 0 || work < maxWork {
+			return name
+		}
+	}
+	return c.Resources.DefaultClass
+}
+
+// cmpMaxWork orders MaxWork values, with 0 (no limit) last
+func cmpMaxWork(a, b float64) int {
+	switch {
+	case a == b:
+		return 0
+	case a <= 0:
+		return 1
+	case b <= 0:
+		return -1
+	}
+	return cmp.Compare(a, b)
+}
+
+// defaultBudgetMemoryMB is MAX_MEMORY_PERCENT of the system's memory
+func defaultBudgetMemoryMB() int64 {
+	total := system.GetResourceStatus().MemoryTotalMB
+	return int64(float64(total) * getEnvFloat("MAX_MEMORY_PERCENT", 85.0) / 100)
+}
+
+// GetSandboxOptions converts config to system.SandboxOptions. Besides the
+// configured paths, the secrets directory, input cache and auth profiles are
+// hidden from ffmpeg.
+func (c *Config) GetSandboxOptions() system.SandboxOptions {
+	hide := []string{c.SecretsDir, c.Download.CacheDir, c.Download.AuthProfilesFile}
+	for _, path := range strings.Split(c.FFmpeg.SandboxHidePaths, ",") {
+		hide = append(hide, strings.TrimSpace(path))
+	}
+	return system.SandboxOptions{
+		UID:          c.FFmpeg.SandboxUID,
+		GID:          c.FFmpeg.SandboxGID,
+		WorkDir:      c.Worker.WorkDir,
+		HidePaths:    slices.DeleteFunc(hide, func(path string) bool { return path == "" }),
+		CgroupParent: c.FFmpeg.SandboxCgroupParent,
+	}
+}
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
 	// Add validation logic if needed
 	return nil
 }
 
+// loadResourceClass reads a resource class from JOB_CLASS_<NAME>_* variables
+func loadResourceClass(name string, defaults ResourceClass) ResourceClass {
+	prefix := "JOB_CLASS_" + name + "_"
+	return ResourceClass{
+		Threads:     getEnvInt(prefix+"THREADS", defaults.Threads),
+		MemoryBytes: getEnvInt64(prefix+"MEMORY_BYTES", defaults.MemoryBytes),
+		CPUs:        getEnvFloat(prefix+"CPUS", defaults.CPUs),
+		Nice:        getEnvInt(prefix+"NICE", defaults.Nice),
+		IOPriority:  getEnvInt(prefix+"IO_PRIORITY", defaults.IOPriority),
+
+		WeightCPU:      getEnvInt64(prefix+"WEIGHT_CPU", defaults.WeightCPU),
+		WeightMemoryMB: getEnvInt64(prefix+"WEIGHT_MEMORY_MB", defaults.WeightMemoryMB),
+		MaxWork:        getEnvFloat(prefix+"MAX_WORK", defaults.MaxWork),
+	}
+}
+
+// lowerLimit returns the lower of two limits, where 0 is unlimited
+func lowerLimit[T int64 | float64](a, b T) T {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
 func getEnv(key, defaultVal string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
@@ -114,6 +399,15 @@ func getEnvInt(key string, defaultVal int) int {
 	return defaultVal
 }
 
+func getEnvInt64(key string, defaultVal int64) int64 {
+	if val := os.Getenv(key); val != "" {
+		if intVal, err := strconv.ParseInt(val, 10, 64); err == nil {
+			return intVal
+		}
+	}
+	return defaultVal
+}
+
 func getEnvFloat(key string, defaultVal float64) float64 {
 	if val := os.Getenv(key); val != "" {
 		if floatVal, err := strconv.ParseFloat(val, 64); err == nil {
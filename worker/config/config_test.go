@@ -0,0 +1,65 @@
+package config
+
+import "testing"
+
+func TestGetConcurrency(t *testing.T) {
+	classes := map[string]ResourceClass{
+		"small": {WeightCPU: 1, WeightMemoryMB: 256},
+		"large": {WeightCPU: 16, WeightMemoryMB: 4096},
+	}
+	tests := []struct {
+		name        string
+		concurrency int
+		cpu, memory int64
+		classes     map[string]ResourceClass
+		want        int
+	}{
+		{"set", 3, 8, 4096, classes, 3},
+		{"cpu bound", 0, 8, 16384, classes, 8},
+		{"memory bound", 0, 8, 1024, classes, 4},
+		{"heavier than the budget", 0, 8, 1024, map[string]ResourceClass{"large": classes["large"]}, 1},
+		{"unlimited budget", 0, 0, 0, classes, 2},
+		{"no weights", 0, 8, 1024, map[string]ResourceClass{"any": {}}, 2},
+	}
+	for _, tt := range tests {
+		c := &Config{}
+		c.Worker.Concurrency = tt.concurrency
+		c.Resources.BudgetCPU, c.Resources.BudgetMemoryMB = tt.cpu, tt.memory
+		c.Resources.Classes = tt.classes
+		if got := c.GetConcurrency(); got != tt.want {
+			t.Errorf("%s: GetConcurrency() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateClass(t *testing.T) {
+	c := &Config{}
+	c.Resources.DefaultClass = "medium"
+	c.Resources.Classes = map[string]ResourceClass{
+		"small":  {MaxWork: 1},
+		"medium": {MaxWork: 30},
+		"large":  {},
+	}
+	tests := []struct {
+		work float64
+		want string
+	}{
+		{0, "small"},
+		{0.5, "small"},
+		{1, "medium"},
+		{29.9, "medium"},
+		{30, "large"},
+		{1e9, "large"},
+	}
+	for _, tt := range tests {
+		if got := c.EstimateClass(tt.work); got != tt.want {
+			t.Errorf("EstimateClass(%v) = %q, want %q", tt.work, got, tt.want)
+		}
+	}
+
+	// Without an unlimited class, the heaviest work gets the default
+	delete(c.Resources.Classes, "large")
+	if got := c.EstimateClass(100); got != "medium" {
+		t.Errorf("EstimateClass(100) without large = %q, want medium", got)
+	}
+}
@@ -0,0 +1,248 @@
+package egress
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrDenied is wrapped by every error returned for a destination the policy rejects
+var ErrDenied = errors.New("egress denied")
+
+// DefaultBlockedCIDRs covers loopback, private, link-local (including cloud
+// metadata), carrier-grade NAT, multicast and other non-public ranges.
+const DefaultBlockedCIDRs = "0.0.0.0/8,10.0.0.0/8,100.64.0.0/10,127.0.0.0/8,169.254.0.0/16,172.16.0.0/12," +
+	"192.0.0.0/24,192.168.0.0/16,198.18.0.0/15,224.0.0.0/4,240.0.0.0/4," +
+	"::/128,::1/128,64:ff9b::/96,fc00::/7,fe80::/10,ff00::/8"
+
+// Policy restricts which destinations outbound requests may reach
+type Policy struct {
+	AllowedSchemes []string      // e.g. "https"; empty allows any
+	AllowedHosts   []string      // Exact hosts or "*.example.com" suffixes; empty allows any
+	AllowedPorts   []int         // Empty allows any port
+	BlockedNets    []*net.IPNet  // Checked against the resolved IP at dial time
+	MaxRedirects   int           // 0 rejects redirects
+	DialTimeout    time.Duration // Connect timeout for Client (0 = 30s)
+}
+
+// NewPolicy builds a policy from comma-separated settings. blockedCIDRs may be
+// "none" to allow every address.
+func NewPolicy(schemes, hosts, ports, blockedCIDRs string, maxRedirects int) (*Policy, error) {
+	p := &Policy{
+		AllowedSchemes: splitList(strings.ToLower(schemes)),
+		AllowedHosts:   splitList(strings.ToLower(hosts)),
+		MaxRedirects:   maxRedirects,
+	}
+
+	for _, s := range splitList(ports) {
+		port, err := strconv.Atoi(s)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid port: %q", s)
+		}
+		p.AllowedPorts = append(p.AllowedPorts, port)
+	}
+
+	if blockedCIDRs != "none" {
+		for _, s := range splitList(blockedCIDRs) {
+			_, ipNet, err := net.ParseCIDR(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR: %q", s)
+			}
+			p.BlockedNets = append(p.BlockedNets, ipNet)
+		}
+	}
+
+	return p, nil
+}
+
+// CheckScheme validates only a URL's scheme
+func (p *Policy) CheckScheme(u *url.URL) error {
+	if len(p.AllowedSchemes) > 0 && !slices.Contains(p.AllowedSchemes, strings.ToLower(u.Scheme)) {
+		return fmt.Errorf("%w: scheme %q not allowed", ErrDenied, u.Scheme)
+	}
+	return nil
+}
+
+// CheckURL validates a URL's scheme, host and port, and its address when the host is an IP literal
+func (p *Policy) CheckURL(u *url.URL) error {
+	if err := p.CheckScheme(u); err != nil {
+		return err
+	}
+	scheme := strings.ToLower(u.Scheme)
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrDenied)
+	}
+	if !p.hostAllowed(host) {
+		return fmt.Errorf("%w: host %q not allowed", ErrDenied, host)
+	}
+
+	port := u.Port()
+	if port == "" {
+		switch scheme {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		}
+	}
+	if err := p.checkPort(port); err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return p.CheckIP(ip)
+	}
+	return nil
+}
+
+// CheckResolved runs CheckURL and also resolves the host, rejecting it if any
+// of its addresses are blocked. Use it to fail early; connections made with
+// Client are still checked at dial time.
+func (p *Policy) CheckResolved(ctx context.Context, u *url.URL) error {
+	if err := p.CheckURL(u); err != nil {
+		return err
+	}
+	if len(p.BlockedNets) == 0 || net.ParseIP(u.Hostname()) != nil {
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if err := p.CheckIP(addr.IP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckIP rejects addresses inside a blocked network
+func (p *Policy) CheckIP(ip net.IP) error {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	for _, n := range p.BlockedNets {
+		if n.Contains(ip) {
+			return fmt.Errorf("%w: address %s is in blocked range %s", ErrDenied, ip, n)
+		}
+	}
+	return nil
+}
+
+// Client returns an HTTP client that enforces the policy on every connection
+// and redirect. Blocked ranges are checked against the resolved address when
+// dialing, so DNS rebinding can't slip past a check made earlier. Proxies from
+// the environment are ignored for the same reason.
+func (p *Policy) Client(timeout time.Duration) *http.Client {
+	return p.TLSClient(timeout, nil)
+}
+
+// TLSClient is like Client but uses tlsConfig for HTTPS connections, e.g. to
+// present a client certificate or trust a private CA.
+func (p *Policy) TLSClient(timeout time.Duration, tlsConfig *tls.Config) *http.Client {
+	dialTimeout := p.DialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = 30 * time.Second
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, port, err := net.SplitHostPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrDenied, err)
+			}
+			if err := p.checkPort(port); err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return fmt.Errorf("%w: unresolved address %q", ErrDenied, host)
+			}
+			return p.CheckIP(ip)
+		},
+	}
+
+	transport := &http.Transport{
+		Proxy: nil,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		},
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if p.MaxRedirects <= 0 {
+				return fmt.Errorf("%w: redirect to %s, redirects are disabled", ErrDenied, req.URL.Host)
+			}
+			if len(via) > p.MaxRedirects {
+				return fmt.Errorf("%w: stopped after %d redirects", ErrDenied, p.MaxRedirects)
+			}
+			return p.CheckURL(req.URL)
+		},
+	}
+}
+
+func (p *Policy) hostAllowed(host string) bool {
+	if len(p.AllowedHosts) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedHosts {
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) checkPort(port string) error {
+	if len(p.AllowedPorts) == 0 {
+		return nil
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		return fmt.Errorf("%w: invalid port %q", ErrDenied, port)
+	}
+	for _, allowed := range p.AllowedPorts {
+		if n == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: port %d not allowed", ErrDenied, n)
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
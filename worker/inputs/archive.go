@@ -0,0 +1,215 @@
+package inputs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ArchiveLimits bound what an archive input may unpack to
+type ArchiveLimits struct {
+	MaxFiles int   // Most files and directories per archive (0 = unlimited)
+	MaxBytes int64 // Largest total unpacked size per archive (0 = unlimited)
+}
+
+// unpack extracts the zip, tar or gzipped tar archive at archivePath into a
+// new directory at dirPath, then removes the archive. Only regular files and
+// directories are created, all within dirPath; archives with links, devices
+// or paths leading outside are rejected. It returns the number of entries
+// and bytes unpacked.
+func unpack(archivePath, dirPath string, limits ArchiveLimits) (int, int64, error) {
+	tmp := dirPath + ".partial"
+	if err := os.Mkdir(tmp, 0755); err != nil {
+		return 0, 0, err
+	}
+	u := &unpacker{root: tmp, limits: limits}
+	if err := u.archive(archivePath); err != nil {
+		os.RemoveAll(tmp)
+		return 0, 0, err
+	}
+	if err := os.Remove(archivePath); err != nil {
+		os.RemoveAll(tmp)
+		return 0, 0, err
+	}
+	return u.files, u.bytes, os.Rename(tmp, dirPath)
+}
+
+type unpacker struct {
+	root   string
+	limits ArchiveLimits
+	files  int
+	bytes  int64
+}
+
+// archive picks the format from the file's first bytes
+func (u *unpacker) archive(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: not an archive", ErrRejected)
+	}
+	head = head[:n]
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")) || bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		stat, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		return u.zip(f, stat.Size())
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(bufio.NewReader(f))
+		if err != nil {
+			return fmt.Errorf("%w: invalid gzip: %v", ErrRejected, err)
+		}
+		defer gz.Close()
+		return u.tar(gz)
+	case len(head) >= 262 && string(head[257:262]) == "ustar":
+		return u.tar(f)
+	default:
+		return fmt.Errorf("%w: not a zip or tar archive", ErrRejected)
+	}
+}
+
+func (u *unpacker) zip(r io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("%w: invalid zip: %v", ErrRejected, err)
+	}
+	for _, zf := range zr.File {
+		mode := zf.Mode()
+		switch {
+		case mode.IsDir():
+			if err := u.dir(zf.Name); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			rc, err := zf.Open()
+			if err != nil {
+				return fmt.Errorf("%w: %s: %v", ErrRejected, zf.Name, err)
+			}
+			err = u.file(zf.Name, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: %s is a %s, only files and directories are allowed", ErrRejected, zf.Name, fileKind(mode))
+		}
+	}
+	return nil
+}
+
+func (u *unpacker) tar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: invalid tar: %v", ErrRejected, err)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = u.dir(hdr.Name)
+		case tar.TypeReg:
+			err = u.file(hdr.Name, tr)
+		case tar.TypeXGlobalHeader:
+			continue
+		default:
+			err = fmt.Errorf("%w: %s is a %s, only files and directories are allowed", ErrRejected, hdr.Name, fileKind(hdr.FileInfo().Mode()))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// path resolves an entry name within the root, rejecting ones that would
+// leave it (zip slip) and counting it against the file limit
+func (u *unpacker) path(name string) (string, error) {
+	name = strings.TrimSuffix(strings.ReplaceAll(name, `\`, "/"), "/")
+	if name == "" || !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", fmt.Errorf("%w: unsafe path in archive: %q", ErrRejected, name)
+	}
+	u.files++
+	if u.limits.MaxFiles > 0 && u.files > u.limits.MaxFiles {
+		return "", fmt.Errorf("%w: archive has more than %d entries", ErrRejected, u.limits.MaxFiles)
+	}
+	return filepath.Join(u.root, filepath.FromSlash(name)), nil
+}
+
+func (u *unpacker) dir(name string) error {
+	if strings.Trim(name, `/\.`) == "" {
+		return nil // The archive's own top-level "./"
+	}
+	path, err := u.path(name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(path, 0755)
+}
+
+func (u *unpacker) file(name string, r io.Reader) error {
+	path, err := u.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// O_EXCL so a repeated name can't replace a file already unpacked
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("%w: %s appears twice in archive", ErrRejected, name)
+		}
+		return err
+	}
+	defer f.Close()
+
+	// Entry sizes in headers can't be trusted, so count what is written
+	if u.limits.MaxBytes > 0 {
+		r = io.LimitReader(r, u.limits.MaxBytes-u.bytes+1)
+	}
+	n, err := io.Copy(f, r)
+	u.bytes += n
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrRejected, name, err)
+	}
+	if u.limits.MaxBytes > 0 && u.bytes > u.limits.MaxBytes {
+		return fmt.Errorf("%w: archive unpacks to more than %d bytes", ErrRejected, u.limits.MaxBytes)
+	}
+	return f.Close()
+}
+
+func fileKind(mode fs.FileMode) string {
+	switch {
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	case mode&fs.ModeDevice != 0:
+		return "device"
+	case mode&fs.ModeNamedPipe != 0:
+		return "named pipe"
+	default:
+		return "link or special file"
+	}
+}
@@ -0,0 +1,195 @@
+package inputs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// entry is a file to put in a test archive. Empty content with a trailing
+// slash in the name makes a directory.
+type entry struct {
+	name    string
+	content string
+	mode    fs.FileMode // Type bits such as fs.ModeSymlink; 0 = regular file
+	link    string      // Hard link target (tar only)
+}
+
+func writeZip(t *testing.T, path string, entries []entry) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		hdr.SetMode(e.mode | 0644)
+		if strings.HasSuffix(e.name, "/") {
+			hdr.SetMode(fs.ModeDir | 0755)
+		}
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(e.content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func writeTar(t *testing.T, path string, gzipped bool, entries []entry) {
+	t.Helper()
+	var buf bytes.Buffer
+	var gz *gzip.Writer
+	tw := tar.NewWriter(&buf)
+	if gzipped {
+		gz = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gz)
+	}
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		switch {
+		case strings.HasSuffix(e.name, "/"):
+			hdr.Typeflag, hdr.Mode = tar.TypeDir, 0755
+		case e.mode&fs.ModeSymlink != 0:
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeSymlink, e.content, 0
+		case e.link != "":
+			hdr.Typeflag, hdr.Linkname = tar.TypeLink, e.link
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			tw.Write([]byte(e.content))
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if gz != nil {
+		gz.Close()
+	}
+	os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// unpackTest writes an archive in each format that can hold entries, unpacks
+// it and returns the error for each format
+func unpackTest(t *testing.T, entries []entry, limits ArchiveLimits, check func(t *testing.T, dir string)) map[string]error {
+	t.Helper()
+	tarOnly := false
+	for _, e := range entries {
+		tarOnly = tarOnly || e.link != ""
+	}
+	errs := make(map[string]error)
+	for _, format := range []string{"zip", "tar", "tar.gz"} {
+		if format == "zip" && tarOnly {
+			continue
+		}
+		dir := t.TempDir()
+		archive := filepath.Join(dir, "in")
+		switch format {
+		case "zip":
+			writeZip(t, archive, entries)
+		case "tar":
+			writeTar(t, archive, false, entries)
+		case "tar.gz":
+			writeTar(t, archive, true, entries)
+		}
+		out := filepath.Join(dir, "out")
+		_, _, err := unpack(archive, out, limits)
+		errs[format] = err
+		if err != nil {
+			// Nothing is left behind, not even the partial directory
+			for _, path := range []string{out, out + ".partial"} {
+				if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+					t.Errorf("%s: %s left after a rejected archive", format, filepath.Base(path))
+				}
+			}
+		} else if check != nil {
+			check(t, out)
+		}
+	}
+	return errs
+}
+
+func TestUnpack(t *testing.T) {
+	entries := []entry{
+		{name: "./"},
+		{name: "frames/"},
+		{name: "frames/001.png", content: "one"},
+		{name: "frames/002.png", content: "two"},
+		{name: "subs/en.srt", content: "subtitles"}, // Parent directory not listed
+	}
+	errs := unpackTest(t, entries, ArchiveLimits{}, func(t *testing.T, dir string) {
+		for name, want := range map[string]string{"frames/001.png": "one", "frames/002.png": "two", "subs/en.srt": "subtitles"} {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil || string(data) != want {
+				t.Errorf("%s = %q, %v; want %q", name, data, err, want)
+			}
+		}
+	})
+	for format, err := range errs {
+		if err != nil {
+			t.Errorf("%s: %v", format, err)
+		}
+	}
+}
+
+func TestUnpackRejects(t *testing.T) {
+	bomb := strings.Repeat("\x00", 4<<20)
+	tests := []struct {
+		name    string
+		entries []entry
+		limits  ArchiveLimits
+	}{
+		{"parent directory", []entry{{name: "../evil.sh", content: "x"}}, ArchiveLimits{}},
+		{"nested parent directory", []entry{{name: "a/../../evil.sh", content: "x"}}, ArchiveLimits{}},
+		{"absolute path", []entry{{name: "/etc/evil", content: "x"}}, ArchiveLimits{}},
+		{"backslash parent directory", []entry{{name: `..\evil.sh`, content: "x"}}, ArchiveLimits{}},
+		{"backslash nested parent directory", []entry{{name: `a\..\..\evil.sh`, content: "x"}}, ArchiveLimits{}},
+		{"symlink", []entry{{name: "link", content: "/etc/passwd", mode: fs.ModeSymlink}}, ArchiveLimits{}},
+		{"hard link", []entry{{name: "a", content: "x"}, {name: "b", link: "a"}}, ArchiveLimits{}},
+		{"hard link outside", []entry{{name: "b", link: "/etc/passwd"}}, ArchiveLimits{}},
+		{"duplicate name", []entry{{name: "a.png", content: "one"}, {name: "a.png", content: "two"}}, ArchiveLimits{}},
+		{"duplicate through dot segment", []entry{{name: "a.png", content: "one"}, {name: "./a.png", content: "two"}}, ArchiveLimits{}},
+		{"too many files", []entry{{name: "a", content: "x"}, {name: "b", content: "x"}, {name: "c", content: "x"}}, ArchiveLimits{MaxFiles: 2}},
+		{"too many directories", []entry{{name: "a/"}, {name: "b/"}, {name: "c/"}}, ArchiveLimits{MaxFiles: 2}},
+		{"too many bytes", []entry{{name: "a", content: "12345"}, {name: "b", content: "67890"}}, ArchiveLimits{MaxBytes: 8}},
+		{"zip bomb", []entry{{name: "zeros", content: bomb}}, ArchiveLimits{MaxBytes: 1 << 20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for format, err := range unpackTest(t, tt.entries, tt.limits, nil) {
+				if !errors.Is(err, ErrRejected) {
+					t.Errorf("%s: err = %v, want ErrRejected", format, err)
+				}
+			}
+		})
+	}
+}
+
+func TestUnpackWithinLimits(t *testing.T) {
+	entries := []entry{{name: "a/"}, {name: "a/b", content: "1234"}, {name: "c", content: "5678"}}
+	for format, err := range unpackTest(t, entries, ArchiveLimits{MaxFiles: 3, MaxBytes: 8}, nil) {
+		if err != nil {
+			t.Errorf("%s: %v", format, err)
+		}
+	}
+}
+
+func TestUnpackNotAnArchive(t *testing.T) {
+	for name, content := range map[string]string{"empty": "", "text": "hello", "bad gzip": "\x1f\x8bnot gzip"} {
+		path := filepath.Join(t.TempDir(), "in")
+		os.WriteFile(path, []byte(content), 0644)
+		if _, _, err := unpack(path, path+".out", ArchiveLimits{}); !errors.Is(err, ErrRejected) {
+			t.Errorf("%s: err = %v, want ErrRejected", name, err)
+		}
+	}
+}
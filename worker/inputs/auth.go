@@ -0,0 +1,173 @@
+package inputs
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"ffmpeg-worker/secrets"
+)
+
+// headerNamePattern matches a valid HTTP header name (RFC 9110 token)
+var headerNamePattern = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9A-Za-z]+$")
+
+// reservedHeaders are set by the downloader itself and can't be overridden
+var reservedHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+	"Upgrade":           true,
+	"Te":                true,
+	"Trailer":           true,
+	"Range":             true,
+	"If-Range":          true,
+}
+
+// AuthProfile is a named set of credentials for fetching inputs. Requests
+// reference it by name, so the secrets never travel through Redis.
+type AuthProfile struct {
+	Name           string            `json:"name"`
+	Hosts          []string          `json:"hosts"`                     // Hosts the credentials may be sent to, e.g. "media.example.com" or "*.example.com"
+	Type           string            `json:"type,omitempty"`            // "basic" or "bearer"
+	Username       string            `json:"username,omitempty"`        // For basic auth
+	PasswordSecret string            `json:"password_secret,omitempty"` // Secret holding the basic auth password
+	TokenSecret    string            `json:"token_secret,omitempty"`    // Secret holding the bearer token
+	Headers        map[string]string `json:"headers,omitempty"`         // Static headers
+	SecretHeaders  map[string]string `json:"secret_headers,omitempty"`  // Header name to secret name, e.g. signed cookies
+
+	header http.Header
+}
+
+// AuthProfiles maps profile names to their credentials
+type AuthProfiles map[string]*AuthProfile
+
+// LoadAuthProfiles reads profiles from a JSON file containing an array of
+// AuthProfile objects, resolving their secrets from store. An empty path
+// means no profiles.
+func LoadAuthProfiles(path string, store *secrets.Store) (AuthProfiles, error) {
+	profiles := make(AuthProfiles)
+	if path == "" {
+		return profiles, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read auth profiles: %w", err)
+	}
+	var list []*AuthProfile
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("parse auth profiles: %w", err)
+	}
+	for _, p := range list {
+		if p.Name == "" {
+			return nil, fmt.Errorf("auth profile without a name")
+		}
+		if _, dup := profiles[p.Name]; dup {
+			return nil, fmt.Errorf("duplicate auth profile: %s", p.Name)
+		}
+		if len(p.Hosts) == 0 {
+			return nil, fmt.Errorf("auth profile %s: hosts is required", p.Name)
+		}
+		if err := p.resolve(store); err != nil {
+			return nil, fmt.Errorf("auth profile %s: %w", p.Name, err)
+		}
+		profiles[p.Name] = p
+	}
+	return profiles, nil
+}
+
+// Header returns the headers for fetching u with the named profile. Profiles
+// only apply to their own hosts, so a request can't send the credentials
+// elsewhere.
+func (p AuthProfiles) Header(name string, u *url.URL) (http.Header, error) {
+	profile, ok := p[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown auth profile %q", ErrRejected, name)
+	}
+	if !profile.allows(strings.ToLower(u.Hostname())) {
+		return nil, fmt.Errorf("%w: auth profile %s can't be used for host %s", ErrRejected, name, u.Hostname())
+	}
+	return profile.header.Clone(), nil
+}
+
+func (p *AuthProfile) allows(host string) bool {
+	for _, allowed := range p.Hosts {
+		allowed = strings.ToLower(allowed)
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve builds the profile's headers, reading secrets from store
+func (p *AuthProfile) resolve(store *secrets.Store) error {
+	p.header = make(http.Header)
+	for name, value := range p.Headers {
+		p.header.Set(name, value)
+	}
+	for name, secret := range p.SecretHeaders {
+		value, err := store.Get(secret)
+		if err != nil {
+			return fmt.Errorf("header %s: %w", name, err)
+		}
+		p.header.Set(name, value)
+	}
+	if err := checkHeader(p.header); err != nil {
+		return err
+	}
+
+	switch strings.ToLower(p.Type) {
+	case "":
+	case "basic":
+		if p.Username == "" || p.PasswordSecret == "" {
+			return fmt.Errorf("basic auth requires username and password_secret")
+		}
+		password, err := store.Get(p.PasswordSecret)
+		if err != nil {
+			return fmt.Errorf("basic auth: %w", err)
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(p.Username + ":" + password))
+		p.header.Set("Authorization", "Basic "+credentials)
+	case "bearer":
+		if p.TokenSecret == "" {
+			return fmt.Errorf("bearer auth requires token_secret")
+		}
+		token, err := store.Get(p.TokenSecret)
+		if err != nil {
+			return fmt.Errorf("bearer auth: %w", err)
+		}
+		p.header.Set("Authorization", "Bearer "+token)
+	default:
+		return fmt.Errorf("unknown auth type: %q", p.Type)
+	}
+	return nil
+}
+
+// checkHeader rejects malformed headers and ones the downloader manages itself
+func checkHeader(h http.Header) error {
+	for name, values := range h {
+		if !headerNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if reservedHeaders[name] {
+			return fmt.Errorf("header %s can't be set", name)
+		}
+		for _, v := range values {
+			if strings.ContainsAny(v, "\r\n\x00") {
+				return fmt.Errorf("invalid value for header %s", name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package inputs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache keeps downloaded inputs on local disk so jobs over the same source
+// don't fetch it again. Entries are named by a hash of the input and its
+// version, and the least recently used are evicted once the cache is over
+// its size budget. Each entry keeps the extension detected when it was
+// downloaded, so later jobs name the file the same way. Files are reflinked
+// or copied into job directories, or hardlinked if ffmpeg can't write to them
+// (see NewCache), so the cache should be on the same filesystem as WORK_DIR.
+type Cache struct {
+	dir      string
+	maxBytes int64
+	hardlink bool
+
+	mu       sync.Mutex
+	entries  map[string]*cacheEntry
+	size     int64
+	inflight map[string]*cacheFill
+}
+
+type cacheEntry struct {
+	ext  string // Detected file extension, part of the file name
+	size int64
+	used time.Time
+	pins int // Links in progress; pinned entries aren't evicted
+}
+
+// cacheFill is a download in progress that other jobs can wait for
+type cacheFill struct {
+	done chan struct{}
+	err  error
+}
+
+// NewCache opens the cache in dir, indexing entries left by earlier runs.
+// hardlink shares entries with jobs instead of copying them, which is only
+// safe when ffmpeg runs as a user that can't change the read-only files, as
+// in the sandbox: otherwise a command could rewrite what later jobs read.
+func NewCache(dir string, maxBytes int64, hardlink bool) (*Cache, error) {
+	c := &Cache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		hardlink: hardlink,
+		entries:  make(map[string]*cacheEntry),
+		inflight: make(map[string]*cacheFill),
+	}
+
+	// Partial downloads from an earlier run can't be finished
+	if err := os.RemoveAll(c.tmpDir()); err != nil {
+		return nil, fmt.Errorf("clear cache tmp: %w", err)
+	}
+	for _, d := range []string{c.objectsDir(), c.tmpDir()} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return nil, fmt.Errorf("create cache dir: %w", err)
+		}
+	}
+
+	files, err := os.ReadDir(c.objectsDir())
+	if err != nil {
+		return nil, fmt.Errorf("read cache dir: %w", err)
+	}
+	for _, f := range files {
+		info, err := f.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		key, ext, _ := strings.Cut(f.Name(), ".")
+		if ext != "" {
+			ext = "." + ext
+		}
+		c.entries[key] = &cacheEntry{ext: ext, size: info.Size(), used: info.ModTime()}
+		c.size += info.Size()
+	}
+
+	c.mu.Lock()
+	c.evictLocked()
+	c.mu.Unlock()
+	log.Printf("Input cache: %d entries, %d bytes in %s", len(c.entries), c.size, dir)
+	return c, nil
+}
+
+// CacheKey derives an entry name from the values identifying an input version
+func CacheKey(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Fetch links the entry for key into localPath, calling fill to download it
+// to a temporary path first if it isn't cached. fill returns the file's
+// extension, which Fetch returns for hits too. Concurrent fetches of the same
+// key share one download. It reports whether the file came from the cache.
+func (c *Cache) Fetch(ctx context.Context, key, localPath string, fill func(tmpPath string) (string, error)) (string, bool, error) {
+	for {
+		c.mu.Lock()
+		if entry, ok := c.entries[key]; ok {
+			entry.pins++
+			entry.used = time.Now()
+			c.mu.Unlock()
+			return entry.ext, true, c.link(key, entry.ext, localPath)
+		}
+
+		if f, ok := c.inflight[key]; ok {
+			c.mu.Unlock()
+			select {
+			case <-f.done:
+			case <-ctx.Done():
+				return "", false, ctx.Err()
+			}
+			// Either it's cached now, or the other job failed (perhaps
+			// cancelled) and this one tries for itself
+			continue
+		}
+
+		f := &cacheFill{done: make(chan struct{})}
+		c.inflight[key] = f
+		c.mu.Unlock()
+
+		ext, err := c.fill(key, fill)
+		f.err = err
+
+		c.mu.Lock()
+		delete(c.inflight, key)
+		close(f.done)
+		c.mu.Unlock()
+		if f.err != nil {
+			return "", false, f.err
+		}
+		return ext, false, c.link(key, ext, localPath)
+	}
+}
+
+// fill downloads an entry and adds it to the index, pinned so it can't be
+// evicted before the caller links it
+func (c *Cache) fill(key string, fill func(tmpPath string) (string, error)) (string, error) {
+	tmp, err := os.MkdirTemp(c.tmpDir(), key[:16]+"-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	tmpPath := filepath.Join(tmp, "data")
+	ext, err := fill(tmpPath)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(tmpPath)
+	if err != nil {
+		return "", err
+	}
+	// Read-only, since every job linking it shares the same file
+	if err := os.Chmod(tmpPath, 0444); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpPath, c.objectPath(key, ext)); err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = &cacheEntry{ext: ext, size: info.Size(), used: time.Now(), pins: 1}
+	c.size += info.Size()
+	c.mu.Unlock()
+	return ext, nil
+}
+
+// link places a pinned entry at localPath, then unpins it. Without hardlinks
+// the file is reflinked, which shares blocks but not the file, or copied.
+func (c *Cache) link(key, ext, localPath string) error {
+	defer func() {
+		c.mu.Lock()
+		if entry, ok := c.entries[key]; ok {
+			entry.pins--
+		}
+		c.evictLocked()
+		c.mu.Unlock()
+	}()
+
+	src := c.objectPath(key, ext)
+	now := time.Now()
+	os.Chtimes(src, now, now)
+
+	if c.hardlink {
+		if err := os.Link(src, localPath); err == nil {
+			return nil
+		}
+	}
+	if runtime.GOOS == "linux" {
+		if err := exec.Command("cp", "--reflink=always", src, localPath).Run(); err == nil {
+			return nil
+		}
+	}
+	return copyFile(src, localPath)
+}
+
+// evictLocked removes the least recently used unpinned entries until the cache fits its budget
+func (c *Cache) evictLocked() {
+	if c.maxBytes <= 0 || c.size <= c.maxBytes {
+		return
+	}
+
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].used.Before(c.entries[keys[j]].used)
+	})
+
+	for _, key := range keys {
+		if c.size <= c.maxBytes {
+			return
+		}
+		entry := c.entries[key]
+		if entry.pins > 0 {
+			continue
+		}
+		// Jobs still holding a hardlink keep their copy until they finish
+		if err := os.Remove(c.objectPath(key, entry.ext)); err != nil && !os.IsNotExist(err) {
+			log.Printf("Input cache: failed to evict %s: %v", key, err)
+			continue
+		}
+		delete(c.entries, key)
+		c.size -= entry.size
+	}
+}
+
+func (c *Cache) objectsDir() string {
+	return filepath.Join(c.dir, "objects")
+}
+
+func (c *Cache) tmpDir() string {
+	return filepath.Join(c.dir, "tmp")
+}
+
+func (c *Cache) objectPath(key, ext string) string {
+	return filepath.Join(c.objectsDir(), key+ext)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// headerKey returns a stable representation of request headers for cache keys
+func headerKey(in Input) string {
+	h := in.header()
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\n", name, strings.Join(h[name], ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,259 @@
+package inputs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fillWith returns a fill function that writes content and reports ext
+func fillWith(content, ext string, calls *atomic.Int32) func(string) (string, error) {
+	return func(tmpPath string) (string, error) {
+		if calls != nil {
+			calls.Add(1)
+		}
+		return ext, os.WriteFile(tmpPath, []byte(content), 0644)
+	}
+}
+
+// fetch fetches the entry for name, returning its content and extension. It
+// can be called from other goroutines.
+func fetch(t *testing.T, c *Cache, name string, fill func(string) (string, error)) (string, bool) {
+	t.Helper()
+	localPath := filepath.Join(t.TempDir(), "in")
+	ext, hit, err := c.Fetch(context.Background(), CacheKey(name), localPath, fill)
+	if err != nil {
+		t.Errorf("fetch %s: %v", name, err)
+		return "", hit
+	}
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		t.Errorf("read %s: %v", name, err)
+	}
+	return string(data) + ext, hit
+}
+
+func TestCacheHit(t *testing.T) {
+	c, err := NewCache(t.TempDir(), 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls atomic.Int32
+	if got, hit := fetch(t, c, "a", fillWith("data", ".mp4", &calls)); got != "data.mp4" || hit {
+		t.Errorf("first fetch = %q, hit %v; want data.mp4, miss", got, hit)
+	}
+	if got, hit := fetch(t, c, "a", fillWith("other", ".mkv", &calls)); got != "data.mp4" || !hit {
+		t.Errorf("second fetch = %q, hit %v; want data.mp4, hit", got, hit)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fill called %d times, want 1", n)
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c, err := NewCache(t.TempDir(), 10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetch(t, c, "a", fillWith("aaaa", "", nil))
+	fetch(t, c, "b", fillWith("bbbb", "", nil))
+	time.Sleep(time.Millisecond)
+	fetch(t, c, "a", fillWith("", "", nil)) // a is now more recently used than b
+	fetch(t, c, "c", fillWith("cccc", "", nil))
+
+	for name, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		c.mu.Lock()
+		_, ok := c.entries[CacheKey(name)]
+		c.mu.Unlock()
+		if ok != want {
+			t.Errorf("entry %s cached = %v, want %v", name, ok, want)
+		}
+		if _, err := os.Stat(c.objectPath(CacheKey(name), "")); (err == nil) != want {
+			t.Errorf("file for %s exists = %v, want %v", name, err == nil, want)
+		}
+	}
+	if c.size != 8 {
+		t.Errorf("size = %d, want 8", c.size)
+	}
+}
+
+func TestCacheSharesInflightFill(t *testing.T) {
+	c, err := NewCache(t.TempDir(), 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	fill := func(tmpPath string) (string, error) {
+		calls.Add(1)
+		close(started)
+		<-release
+		return ".ts", os.WriteFile(tmpPath, []byte("data"), 0644)
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0], _ = fetch(t, c, "a", fill)
+	}()
+	<-started
+	for i := 1; i < len(results); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = fetch(t, c, "a", fill)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond) // Let the others wait on the fill
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fill called %d times, want 1", n)
+	}
+	for i, got := range results {
+		if got != "data.ts" {
+			t.Errorf("fetch %d = %q, want data.ts", i, got)
+		}
+	}
+}
+
+func TestCacheRetriesAfterFailedFill(t *testing.T) {
+	c, err := NewCache(t.TempDir(), 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errFill := errors.New("download failed")
+	started, release := make(chan struct{}), make(chan struct{})
+	failing := func(tmpPath string) (string, error) {
+		close(started)
+		<-release
+		return "", errFill
+	}
+
+	failed := make(chan error)
+	go func() {
+		_, _, err := c.Fetch(context.Background(), CacheKey("a"), filepath.Join(t.TempDir(), "in"), failing)
+		failed <- err
+	}()
+	<-started
+
+	var calls atomic.Int32
+	var wg sync.WaitGroup
+	results := make([]string, 3)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = fetch(t, c, "a", fillWith("data", "", &calls))
+		}()
+	}
+	time.Sleep(10 * time.Millisecond) // Let the others wait on the failing fill
+	close(release)
+
+	if err := <-failed; !errors.Is(err, errFill) {
+		t.Errorf("failed fetch err = %v, want %v", err, errFill)
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fill called %d times after the failure, want 1", n)
+	}
+	for i, got := range results {
+		if got != "data" {
+			t.Errorf("fetch %d = %q, want data", i, got)
+		}
+	}
+}
+
+func TestCacheWaitCancelled(t *testing.T) {
+	c, err := NewCache(t.TempDir(), 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	go c.Fetch(context.Background(), CacheKey("a"), filepath.Join(t.TempDir(), "in"), func(tmpPath string) (string, error) {
+		close(started)
+		<-release
+		return "", errors.New("cancelled")
+	})
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := c.Fetch(ctx, CacheKey("a"), filepath.Join(t.TempDir(), "in"), fillWith("data", "", nil)); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+// A new entry must stay pinned until it's linked, even when it's larger than
+// the whole cache and other fetches are evicting at the same time
+func TestCacheFetchWhileEvicting(t *testing.T) {
+	c, err := NewCache(t.TempDir(), 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 25 {
+				name := fmt.Sprintf("%d-%d", i, j)
+				if got, _ := fetch(t, c, name, fillWith(name, "", nil)); got != name {
+					t.Errorf("fetch %s = %q", name, got)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(c.entries) != 0 || c.size != 0 {
+		t.Errorf("after all links: %d entries, %d bytes; want all evicted", len(c.entries), c.size)
+	}
+}
+
+func TestCacheReindexesOnOpen(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewCache(dir, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetch(t, c, "a", fillWith("aaaa", ".mp4", nil))
+	fetch(t, c, "b", fillWith("bb", "", nil))
+	// A partial download from a crashed run
+	partial := filepath.Join(c.tmpDir(), "x-1", "data")
+	os.MkdirAll(filepath.Dir(partial), 0755)
+	os.WriteFile(partial, []byte("partial"), 0644)
+
+	c, err = NewCache(dir, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.size != 6 || len(c.entries) != 2 {
+		t.Errorf("reopened: %d entries, %d bytes; want 2, 6", len(c.entries), c.size)
+	}
+	if got, hit := fetch(t, c, "a", fillWith("other", "", nil)); got != "aaaa.mp4" || !hit {
+		t.Errorf("fetch after reopen = %q, hit %v; want aaaa.mp4, hit", got, hit)
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Errorf("partial download not removed: %v", err)
+	}
+
+	// Reopening with a smaller budget evicts down to it
+	c, err = NewCache(dir, 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.size > 4 {
+		t.Errorf("reopened with budget 4: %d bytes", c.size)
+	}
+}
@@ -0,0 +1,295 @@
+package inputs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"ffmpeg-worker/adapters"
+	"ffmpeg-worker/egress"
+	"ffmpeg-worker/system"
+)
+
+// Downloader fetches a command's inputs in parallel
+type Downloader struct {
+	Adapters    adapters.InputAdapters
+	Auth        AuthProfiles   // Credentials inputs can reference by name
+	Cache       *Cache         // Shared copies of earlier downloads (nil = no caching)
+	Streams     *StreamProxy   // Serves inputs with mode "stream" (nil = download them)
+	Probe       *system.Prober // Runs ffprobe on inputs to name and check them
+	Concurrency int            // Inputs fetched at once (minimum 1)
+	Retries     int            // Extra attempts per input after a retryable failure
+	Backoff     time.Duration  // Delay before the first retry, doubling after each
+	Archives    ArchiveLimits  // Applied to inputs with extract set
+}
+
+// Download describes one fetched input
+type Download struct {
+	Path     string
+	Bytes    int64
+	Duration time.Duration
+	Attempts int
+	Cached   bool // Reused from the cache rather than downloaded by this job
+}
+
+// Stats summarizes a command's downloads
+type Stats struct {
+	Bytes    int64
+	Duration time.Duration // Wall-clock time for all inputs
+}
+
+// MBytesPerSecond returns the overall download throughput
+func (s Stats) MBytesPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / 1024 / 1024 / s.Duration.Seconds()
+}
+
+// DownloadAll fetches every input into dir and checks it, returning the local
+// paths by key. Archives to extract are unpacked into a directory named after
+// the key, which is the path returned. The first input to fail cancels the rest.
+func (d *Downloader) DownloadAll(ctx context.Context, commandID, dir string, inputs map[string]Input) (map[string]string, Stats, error) {
+	for key, in := range inputs {
+		if err := ValidateKey(key); err != nil {
+			return nil, Stats{}, fmt.Errorf("input %s: %w", key, err)
+		}
+		if err := in.Validate(); err != nil {
+			return nil, Stats{}, fmt.Errorf("input %s: %w", key, err)
+		}
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	concurrency := max(d.Concurrency, 1)
+	slots := make(chan struct{}, concurrency)
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		paths    = make(map[string]string, len(inputs))
+		stats    Stats
+	)
+
+	for key, in := range inputs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return
+			}
+
+			dl, err := d.download(ctx, commandID, key, in, filepath.Join(dir, key))
+			if err == nil {
+				err = d.verify(ctx, in, dl.Path)
+			}
+			if err == nil && in.Extract {
+				var files int
+				var size int64
+				files, size, err = unpack(dl.Path, filepath.Join(dir, key), d.Archives)
+				if err == nil {
+					dl.Path = filepath.Join(dir, key)
+					log.Printf("[%s] Unpacked %s: %d entries, %d bytes", commandID, key, files, size)
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("download %s: %w", key, err)
+					cancel()
+				}
+				return
+			}
+			paths[key] = dl.Path
+			if dl.Cached {
+				log.Printf("[%s] Using cached %s: %s (%d bytes)", commandID, key, in.logURL(), dl.Bytes)
+				return
+			}
+			stats.Bytes += dl.Bytes
+			log.Printf("[%s] Downloaded %s: %s (%d bytes in %s, %d attempts)",
+				commandID, key, in.logURL(), dl.Bytes, dl.Duration.Round(time.Millisecond), dl.Attempts)
+		}()
+	}
+	wg.Wait()
+
+	stats.Duration = time.Since(start)
+	if firstErr != nil {
+		return nil, stats, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, stats, err
+	}
+	return paths, stats, nil
+}
+
+// download fetches one input, through the cache if there is one. The file is
+// saved at localPath plus the extension detected for it.
+func (d *Downloader) download(ctx context.Context, commandID, key string, in Input, localPath string) (*Download, error) {
+	u, err := in.parseURL()
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid url: %v", egress.ErrDenied, err)
+	}
+	adapter, err := d.Adapters.For(u)
+	if err != nil {
+		return nil, err
+	}
+	header, err := d.header(in, u)
+	if err != nil {
+		return nil, err
+	}
+	opts := adapters.FetchOptions{MaxBytes: in.MaxBytes, Header: header}
+
+	start := time.Now()
+	dl := &Download{}
+	var ext string
+	if cacheKey := d.cacheKey(ctx, commandID, key, adapter, u, in, opts); cacheKey != "" {
+		ext, dl.Cached, err = d.Cache.Fetch(ctx, cacheKey, localPath, func(tmpPath string) (string, error) {
+			var info adapters.ObjectInfo
+			var err error
+			dl.Attempts, info, err = d.fetch(ctx, commandID, key, adapter, u, tmpPath, opts)
+			if err == nil {
+				// Checked before caching, so an entry never has the wrong digest
+				err = checkDigest(in, tmpPath)
+			}
+			if err != nil {
+				return "", err
+			}
+			return d.detectExtension(ctx, in, info, tmpPath), nil
+		})
+		// Entries keep the extension of the request that filled them; this
+		// one's own URL or override still decides its file name
+		if pathExt := in.pathExtension(); pathExt != "" {
+			ext = pathExt
+		}
+	} else {
+		var info adapters.ObjectInfo
+		dl.Attempts, info, err = d.fetch(ctx, commandID, key, adapter, u, localPath, opts)
+		if err == nil {
+			err = checkDigest(in, localPath)
+		}
+		if err == nil {
+			ext = d.detectExtension(ctx, in, info, localPath)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dl.Path = localPath + ext
+	if err := os.Rename(localPath, dl.Path); err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(dl.Path)
+	if err != nil {
+		return nil, err
+	}
+	dl.Bytes = stat.Size()
+	dl.Duration = time.Since(start)
+	// Cached files may have been fetched under a higher limit
+	if in.MaxBytes > 0 && dl.Bytes > in.MaxBytes {
+		return nil, fmt.Errorf("%w: input is %d bytes, limit is %d", egress.ErrDenied, dl.Bytes, in.MaxBytes)
+	}
+	return dl, nil
+}
+
+// fetch downloads an input to localPath, retrying with backoff. Partial files
+// are kept between attempts so adapters that support it can resume. It
+// returns the number of attempts made and what the store reported about the object.
+func (d *Downloader) fetch(ctx context.Context, commandID, key string, adapter adapters.InputAdapter, u *url.URL, localPath string, opts adapters.FetchOptions) (int, adapters.ObjectInfo, error) {
+	backoff := d.Backoff
+	for attempt := 1; ; attempt++ {
+		info, err := adapter.Fetch(ctx, u, localPath, opts)
+		if err == nil {
+			return attempt, info, nil
+		}
+		if attempt > d.Retries || !adapters.Retryable(err) || ctx.Err() != nil {
+			return attempt, info, err
+		}
+
+		log.Printf("[%s] Download of %s failed (attempt %d), retrying in %s: %v", commandID, key, attempt, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return attempt, adapters.ObjectInfo{}, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// cacheKey returns the cache entry for an input, or "" if it isn't cached.
+// Inputs with a checksum are keyed by it; others need an adapter that can tell
+// the object's current version. The URL and credentials are part of the key,
+// so a file is only reused by requests that could fetch it themselves: knowing
+// a checksum isn't enough to read someone else's file.
+func (d *Downloader) cacheKey(ctx context.Context, commandID, key string, adapter adapters.InputAdapter, u *url.URL, in Input, opts adapters.FetchOptions) string {
+	if d.Cache == nil {
+		return ""
+	}
+	if in.SHA256 != "" {
+		return CacheKey("sha256", strings.ToLower(in.SHA256), normalizeURL(u), in.Auth, headerKey(in))
+	}
+
+	versioner, ok := adapter.(adapters.InputVersioner)
+	if !ok {
+		return ""
+	}
+	version, err := versioner.Version(ctx, u, opts)
+	if err != nil {
+		log.Printf("[%s] Not caching %s: %v", commandID, key, err)
+		return ""
+	}
+	if version == "" {
+		return ""
+	}
+	return CacheKey("url", normalizeURL(u), version, in.Auth, headerKey(in))
+}
+
+// normalizeURL returns u with the scheme and host lowercased, without a
+// default port or fragment, so spellings of the same URL share cache entries
+func normalizeURL(u *url.URL) string {
+	n := *u
+	n.Scheme = strings.ToLower(n.Scheme)
+	n.Host = strings.ToLower(n.Host)
+	if port := n.Port(); (n.Scheme == "http" && port == "80") || (n.Scheme == "https" && port == "443") {
+		n.Host = n.Hostname()
+		if strings.Contains(n.Host, ":") {
+			n.Host = "[" + n.Host + "]"
+		}
+	}
+	n.Fragment, n.RawFragment = "", ""
+	return n.String()
+}
+
+// header builds the request headers for an input: its own, overridden by its auth profile's
+func (d *Downloader) header(in Input, u *url.URL) (http.Header, error) {
+	header := in.header()
+	if in.Auth != "" {
+		auth, err := d.Auth.Header(in.Auth, u)
+		if err != nil {
+			return nil, err
+		}
+		for name, values := range auth {
+			header[name] = values
+		}
+	}
+	if len(header) > 0 && u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: headers and auth only apply to http(s) inputs", ErrRejected)
+	}
+	return header, nil
+}
@@ -0,0 +1,141 @@
+package inputs
+
+import (
+	"context"
+	"mime"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"ffmpeg-worker/adapters"
+)
+
+var extensionPattern = regexp.MustCompile(`^\.?[A-Za-z0-9]{1,10}$`)
+
+// contentTypeExtensions maps the media types stores commonly report to file
+// extensions. Generic types such as application/octet-stream are left out so
+// detection falls through to probing the file.
+var contentTypeExtensions = map[string]string{
+	"video/mp4":                     ".mp4",
+	"video/quicktime":               ".mov",
+	"video/webm":                    ".webm",
+	"video/x-matroska":              ".mkv",
+	"video/x-msvideo":               ".avi",
+	"video/x-flv":                   ".flv",
+	"video/mp2t":                    ".ts",
+	"video/mpeg":                    ".mpg",
+	"video/3gpp":                    ".3gp",
+	"video/ogg":                     ".ogv",
+	"audio/mpeg":                    ".mp3",
+	"audio/mp4":                     ".m4a",
+	"audio/aac":                     ".aac",
+	"audio/wav":                     ".wav",
+	"audio/x-wav":                   ".wav",
+	"audio/wave":                    ".wav",
+	"audio/flac":                    ".flac",
+	"audio/x-flac":                  ".flac",
+	"audio/ogg":                     ".ogg",
+	"audio/opus":                    ".opus",
+	"audio/webm":                    ".weba",
+	"image/jpeg":                    ".jpg",
+	"image/png":                     ".png",
+	"image/gif":                     ".gif",
+	"image/webp":                    ".webp",
+	"image/bmp":                     ".bmp",
+	"image/tiff":                    ".tiff",
+	"image/avif":                    ".avif",
+	"text/vtt":                      ".vtt",
+	"application/x-subrip":          ".srt",
+	"text/x-ssa":                    ".ass",
+	"application/vnd.apple.mpegurl": ".m3u8",
+	"application/x-mpegurl":         ".m3u8",
+	"application/dash+xml":          ".mpd",
+}
+
+// formatExtensions maps ffprobe demuxer names to file extensions
+var formatExtensions = map[string]string{
+	"mov":       ".mp4",
+	"matroska":  ".mkv",
+	"avi":       ".avi",
+	"flv":       ".flv",
+	"mpegts":    ".ts",
+	"mpeg":      ".mpg",
+	"ogg":       ".ogg",
+	"mp3":       ".mp3",
+	"aac":       ".aac",
+	"wav":       ".wav",
+	"flac":      ".flac",
+	"gif":       ".gif",
+	"jpeg_pipe": ".jpg",
+	"png_pipe":  ".png",
+	"webp_pipe": ".webp",
+	"bmp_pipe":  ".bmp",
+	"tiff_pipe": ".tiff",
+	"srt":       ".srt",
+	"webvtt":    ".vtt",
+	"ass":       ".ass",
+	"hls":       ".m3u8",
+	"dash":      ".mpd",
+}
+
+// pathExtension returns the extension the caller gave for the input, or the
+// one in its URL path or inline file name if it looks like one. It returns ""
+// if none is known.
+func (in *Input) pathExtension() string {
+	if in.Extension != "" {
+		return normalizeExtension(in.Extension)
+	}
+	if in.Content != "" {
+		if ext := path.Ext(in.Filename); extensionPattern.MatchString(ext) {
+			return normalizeExtension(ext)
+		}
+		return ""
+	}
+	u, err := url.Parse(in.URL)
+	if err != nil {
+		return ""
+	}
+	if ext := path.Ext(u.Path); extensionPattern.MatchString(ext) {
+		return normalizeExtension(ext)
+	}
+	return ""
+}
+
+// detectExtension works out an input's extension once it has been fetched:
+// from the request, then the name or type the store reported, then by asking
+// ffprobe what it is. It returns "" if none of them tell.
+func (d *Downloader) detectExtension(ctx context.Context, in Input, info adapters.ObjectInfo, path string) string {
+	if ext := in.pathExtension(); ext != "" {
+		return ext
+	}
+	if ext := infoExtension(info); ext != "" {
+		return ext
+	}
+	if in.Extract {
+		return "" // Archives are recognized when they are unpacked
+	}
+	media, err := d.Probe.Media(ctx, filepath.Dir(path), path)
+	if err != nil {
+		return ""
+	}
+	name, _, _ := strings.Cut(media.FormatName, ",")
+	return formatExtensions[name]
+}
+
+// infoExtension returns the extension suggested by the store's file name or media type
+func infoExtension(info adapters.ObjectInfo) string {
+	if ext := path.Ext(info.Filename); extensionPattern.MatchString(ext) {
+		return normalizeExtension(ext)
+	}
+	mediaType, _, err := mime.ParseMediaType(info.ContentType)
+	if err != nil {
+		return ""
+	}
+	return contentTypeExtensions[mediaType]
+}
+
+func normalizeExtension(ext string) string {
+	return "." + strings.ToLower(strings.TrimPrefix(ext, "."))
+}
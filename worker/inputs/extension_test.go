@@ -0,0 +1,113 @@
+package inputs
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"ffmpeg-worker/adapters"
+	"ffmpeg-worker/system"
+)
+
+func TestPathExtension(t *testing.T) {
+	tests := []struct {
+		in   Input
+		want string
+	}{
+		{Input{URL: "https://example.com/a.mp4"}, ".mp4"},
+		{Input{URL: "https://example.com/a.JPEG?X-Amz-Signature=abc.mp4"}, ".jpeg"}, // Query ignored
+		{Input{URL: "https://example.com/a.webm#t=10"}, ".webm"},
+		{Input{URL: "https://example.com/dir.v2/video"}, ""}, // No extension in the file name
+		{Input{URL: "https://example.com/api/files/123"}, ""},
+		{Input{URL: "https://example.com/a.verylongextension"}, ""},
+		{Input{URL: "https://example.com/a.mp4%20x"}, ""},
+		{Input{URL: "https://example.com/a.bin", Extension: "MKV"}, ".mkv"}, // Override wins
+		{Input{URL: "https://example.com/a", Extension: ".srt"}, ".srt"},
+		{Input{Content: "file 'a.mp4'", Filename: "list.txt"}, ".txt"},
+		{Input{Content: "WEBVTT", Filename: "subs"}, ""},
+		{Input{Content: "WEBVTT", Extension: "vtt"}, ".vtt"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.pathExtension(); got != tt.want {
+			t.Errorf("pathExtension(%+v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestInfoExtension(t *testing.T) {
+	tests := []struct {
+		info adapters.ObjectInfo
+		want string
+	}{
+		{adapters.ObjectInfo{Filename: "clip.MOV"}, ".mov"},
+		{adapters.ObjectInfo{Filename: "clip.mov", ContentType: "video/mp4"}, ".mov"}, // Name before type
+		{adapters.ObjectInfo{Filename: "clip", ContentType: "video/mp4"}, ".mp4"},
+		{adapters.ObjectInfo{ContentType: "image/jpeg"}, ".jpg"},
+		{adapters.ObjectInfo{ContentType: "Video/WebM; codecs=vp9"}, ".webm"},
+		{adapters.ObjectInfo{ContentType: "text/vtt; charset=utf-8"}, ".vtt"},
+		{adapters.ObjectInfo{ContentType: "application/octet-stream"}, ""}, // Left to probing
+		{adapters.ObjectInfo{ContentType: "not a media type;;"}, ""},
+		{adapters.ObjectInfo{}, ""},
+	}
+	for _, tt := range tests {
+		if got := infoExtension(tt.info); got != tt.want {
+			t.Errorf("infoExtension(%+v) = %q, want %q", tt.info, got, tt.want)
+		}
+	}
+}
+
+// Cases that are decided before probing, so no ffprobe is needed
+func TestDetectExtensionOrder(t *testing.T) {
+	d := &Downloader{}
+	tests := []struct {
+		name string
+		in   Input
+		info adapters.ObjectInfo
+		want string
+	}{
+		{"URL before store", Input{URL: "https://example.com/a.mkv"}, adapters.ObjectInfo{ContentType: "video/mp4"}, ".mkv"},
+		{"override before store", Input{URL: "https://example.com/a", Extension: "ts"}, adapters.ObjectInfo{Filename: "a.mp4"}, ".ts"},
+		{"store when URL has none", Input{URL: "https://example.com/files/1"}, adapters.ObjectInfo{ContentType: "audio/mpeg"}, ".mp3"},
+		{"archives aren't probed", Input{URL: "https://example.com/files/1", Extract: true}, adapters.ObjectInfo{}, ""},
+	}
+	for _, tt := range tests {
+		if got := d.detectExtension(context.Background(), tt.in, tt.info, "/nonexistent"); got != tt.want {
+			t.Errorf("%s: detectExtension = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// printExecutor stands in for ffprobe by printing a fixed output
+type printExecutor struct {
+	output string
+}
+
+func (e printExecutor) Name() string { return "print" }
+
+func (e printExecutor) Command(ctx context.Context, spec system.ExecSpec) (*exec.Cmd, func(), error) {
+	return exec.CommandContext(ctx, "printf", "%s", e.output), func() {}, nil
+}
+
+func (e printExecutor) Unenforced(limits system.Limits) []string { return nil }
+
+func TestDetectExtensionProbes(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{`{"format": {"format_name": "mov,mp4,m4a,3gp,3g2,mj2"}, "streams": [{"codec_type": "video"}]}`, ".mp4"},
+		{`{"format": {"format_name": "matroska,webm"}, "streams": [{"codec_type": "video"}]}`, ".mkv"},
+		{`{"format": {"format_name": "png_pipe"}, "streams": [{"codec_type": "video"}]}`, ".png"},
+		{`{"format": {"format_name": "srt"}, "streams": [{"codec_type": "subtitle"}]}`, ".srt"},
+		{`{"format": {"format_name": "unknown_demuxer"}, "streams": []}`, ""},
+		{`not json`, ""},
+	}
+	for _, tt := range tests {
+		d := &Downloader{Probe: &system.Prober{Executor: printExecutor{tt.output}}}
+		in := Input{URL: "https://example.com/files/1"}
+		if got := d.detectExtension(context.Background(), in, adapters.ObjectInfo{}, filepath.Join(t.TempDir(), "in")); got != tt.want {
+			t.Errorf("probe output %s: detectExtension = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+}
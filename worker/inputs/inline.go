@@ -0,0 +1,59 @@
+package inputs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteInline writes the inputs given as inline content into dir and checks
+// them, returning their paths by key. paths holds the other inputs' locations:
+// placeholders for inputs in the content, such as "file '{{in_1}}'" in a
+// concat list, are replaced with their file names, which resolve relative to
+// the inline file. Streamed inputs are replaced with their URL.
+func (d *Downloader) WriteInline(ctx context.Context, commandID, dir string, inputs map[string]Input, paths map[string]string) (map[string]string, error) {
+	written := make(map[string]string, len(inputs))
+	for key, in := range inputs {
+		if err := ValidateKey(key); err != nil {
+			return nil, fmt.Errorf("input %s: %w", key, err)
+		}
+		if err := in.Validate(); err != nil {
+			return nil, fmt.Errorf("input %s: %w", key, err)
+		}
+		written[key] = filepath.Join(dir, key+in.pathExtension())
+	}
+	all := make(map[string]string, len(paths)+len(written))
+	maps.Copy(all, paths)
+	maps.Copy(all, written)
+
+	for key, in := range inputs {
+		localPath := written[key]
+		content := expandInline(in.Content, dir, all)
+		if err := os.WriteFile(localPath, []byte(content), 0644); err != nil {
+			return nil, fmt.Errorf("write %s: %w", key, err)
+		}
+		if err := checkDigest(in, localPath); err != nil {
+			return nil, fmt.Errorf("input %s: %w", key, err)
+		}
+		if err := d.verify(ctx, in, localPath); err != nil {
+			return nil, fmt.Errorf("input %s: %w", key, err)
+		}
+		log.Printf("[%s] Wrote inline %s (%d bytes)", commandID, key, len(content))
+	}
+	return written, nil
+}
+
+// expandInline replaces input placeholders in inline content
+func expandInline(content, dir string, paths map[string]string) string {
+	for key, p := range paths {
+		if filepath.Dir(p) == dir {
+			p = filepath.Base(p)
+		}
+		content = strings.ReplaceAll(content, "{{"+key+"}}", p)
+	}
+	return content
+}
@@ -0,0 +1,71 @@
+package inputs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteInline(t *testing.T) {
+	dir := t.TempDir()
+	d := &Downloader{}
+	inline := map[string]Input{
+		"list": {Content: "file '{{in_1}}'\nfile '{{in_2}}'\n", Filename: "list.txt"},
+		"subs": {Content: "WEBVTT\n", Extension: "vtt"},
+		"note": {Content: "see {{subs}}"},
+	}
+	paths := map[string]string{
+		"in_1": filepath.Join(dir, "in_1.mp4"),
+		"in_2": "http://127.0.0.1:8123/token/b.mp4", // Streamed
+	}
+
+	written, err := d.WriteInline(context.Background(), "cmd", dir, inline, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]struct{ file, content string }{
+		"list": {"list.txt", "file 'in_1.mp4'\nfile 'http://127.0.0.1:8123/token/b.mp4'\n"},
+		"subs": {"subs.vtt", "WEBVTT\n"},
+		"note": {"note", "see subs.vtt"}, // Inline inputs can refer to each other
+	}
+	for key, w := range want {
+		if written[key] != filepath.Join(dir, w.file) {
+			t.Errorf("%s written to %s, want %s", key, written[key], w.file)
+			continue
+		}
+		data, err := os.ReadFile(written[key])
+		if err != nil || string(data) != w.content {
+			t.Errorf("%s = %q, %v; want %q", key, data, err, w.content)
+		}
+	}
+}
+
+func TestWriteInlineRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		in   Input
+	}{
+		{"sha256 mismatch", "a", Input{Content: "hello", SHA256: "0000000000000000000000000000000000000000000000000000000000000000"}},
+		{"path in filename", "a", Input{Content: "hello", Filename: "../a.txt"}},
+		{"invalid key", "../a", Input{Content: "hello"}},
+		{"stream mode", "a", Input{Content: "hello", Mode: ModeStream}},
+	}
+	for _, tt := range tests {
+		d := &Downloader{}
+		_, err := d.WriteInline(context.Background(), "cmd", t.TempDir(), map[string]Input{tt.key: tt.in}, nil)
+		if !errors.Is(err, ErrRejected) {
+			t.Errorf("%s: err = %v, want ErrRejected", tt.name, err)
+		}
+	}
+}
+
+func TestWriteInlineDigest(t *testing.T) {
+	// sha256("hello")
+	in := Input{Content: "hello", SHA256: "2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824"}
+	if _, err := (&Downloader{}).WriteInline(context.Background(), "cmd", t.TempDir(), map[string]Input{"a": in}, nil); err != nil {
+		t.Errorf("matching digest: %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package inputs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"ffmpeg-worker/redact"
+)
+
+// ErrRejected is returned when a downloaded input fails its integrity checks.
+// Fetching it again won't help, so the command should fail without retrying.
+var ErrRejected = errors.New("input rejected")
+
+// Expected media kinds for Input.Expect
+const (
+	ExpectVideo = "video"
+	ExpectAudio = "audio"
+	ExpectImage = "image"
+)
+
+var (
+	sha256Pattern   = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+	filenamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	keyPattern      = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+)
+
+// ValidateKey checks an input_files key, which names the input's file or
+// directory in the job directory
+func ValidateKey(key string) error {
+	if !keyPattern.MatchString(key) {
+		return fmt.Errorf("%w: invalid key %q: only letters, digits, _ and - are allowed", ErrRejected, key)
+	}
+	return nil
+}
+
+// Input is one entry of a command's input_files. It is given either as a URL
+// string or as an object with checks and request options. Small text files
+// can be given inline with Content instead of a URL.
+type Input struct {
+	URL      string `json:"url,omitempty"`
+	SHA256   string `json:"sha256,omitempty"`    // Expected hex digest of the downloaded file
+	MaxBytes int64  `json:"max_bytes,omitempty"` // Lower size limit than the worker's own
+	Expect   string `json:"expect,omitempty"`    // "video", "audio" or "image", checked with ffprobe
+
+	Headers map[string]string `json:"headers,omitempty"` // Extra request headers for HTTP(S) inputs
+	Auth    string            `json:"auth,omitempty"`    // Name of a worker auth profile to fetch with
+	Mode    string            `json:"mode,omitempty"`    // "download" (default) or "stream"
+
+	Extension string `json:"extension,omitempty"` // File extension to save the input with, e.g. ".vtt", instead of detecting it
+
+	Content  string `json:"content,omitempty"`  // Inline file content, written to the job directory
+	Filename string `json:"filename,omitempty"` // Name of the inline file; its extension is kept
+
+	Extract bool `json:"extract,omitempty"` // Unpack a zip or tar(.gz) archive into a directory
+}
+
+// UnmarshalJSON accepts a URL string or an object
+func (in *Input) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*in = Input{URL: s}
+		return nil
+	}
+	type plain Input
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return fmt.Errorf("input must be a URL or an object: %w", err)
+	}
+	*in = Input(p)
+	return nil
+}
+
+// MarshalJSON writes inputs without options as plain URL strings, so requests
+// are echoed back the way they were sent
+func (in Input) MarshalJSON() ([]byte, error) {
+	if in.isPlain() {
+		return json.Marshal(in.URL)
+	}
+	type plain Input
+	return json.Marshal(plain(in))
+}
+
+// Validate checks the input's options
+func (in *Input) Validate() error {
+	switch {
+	case in.URL == "" && in.Content == "":
+		return fmt.Errorf("%w: url or content is required", ErrRejected)
+	case in.URL != "" && in.Content != "":
+		return fmt.Errorf("%w: url and content can't both be given", ErrRejected)
+	case in.Content != "" && (len(in.Headers) > 0 || in.Auth != "" || in.Mode == ModeStream):
+		return fmt.Errorf("%w: headers, auth and stream mode don't apply to inline content", ErrRejected)
+	case in.Filename != "" && in.Content == "":
+		return fmt.Errorf("%w: filename only applies to inline content", ErrRejected)
+	case in.Filename != "" && !filenamePattern.MatchString(in.Filename):
+		return fmt.Errorf("%w: filename must be a plain file name", ErrRejected)
+	case in.Extract && (in.Content != "" || in.Mode == ModeStream || in.Expect != ""):
+		return fmt.Errorf("%w: archives can't be inline, streamed or checked with expect", ErrRejected)
+	}
+	if in.SHA256 != "" && !sha256Pattern.MatchString(in.SHA256) {
+		return fmt.Errorf("%w: sha256 must be 64 hex characters", ErrRejected)
+	}
+	if in.MaxBytes < 0 {
+		return fmt.Errorf("%w: max_bytes must be positive", ErrRejected)
+	}
+	switch strings.ToLower(in.Expect) {
+	case "", ExpectVideo, ExpectAudio, ExpectImage:
+	default:
+		return fmt.Errorf("%w: unknown expect value %q", ErrRejected, in.Expect)
+	}
+	switch in.Mode {
+	case "", ModeDownload:
+	case ModeStream:
+		if in.SHA256 != "" {
+			return fmt.Errorf("%w: sha256 can't be checked on streamed inputs", ErrRejected)
+		}
+	default:
+		return fmt.Errorf("%w: unknown mode %q", ErrRejected, in.Mode)
+	}
+	if in.Extension != "" && !extensionPattern.MatchString(in.Extension) {
+		return fmt.Errorf("%w: extension must be up to 10 letters or digits", ErrRejected)
+	}
+	if err := checkHeader(in.header()); err != nil {
+		return fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	return nil
+}
+
+// isPlain reports whether the input is just a URL
+func (in *Input) isPlain() bool {
+	return in.SHA256 == "" && in.MaxBytes == 0 && in.Expect == "" && len(in.Headers) == 0 && in.Auth == "" && in.Mode == "" && in.Extension == "" &&
+		in.Content == "" && in.Filename == "" && !in.Extract
+}
+
+// parseURL parses the input's URL. Errors leave the URL out, since data: URIs
+// can be long.
+func (in *Input) parseURL() (*url.URL, error) {
+	u, err := url.Parse(in.URL)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	return u, err
+}
+
+// logURL returns the input's URL for logs, with data: URIs cut to their media
+// type and the query, fragment and credentials removed from other URLs, since
+// they often carry signatures or tokens
+func (in *Input) logURL() string {
+	if len(in.URL) > 5 && strings.EqualFold(in.URL[:5], "data:") {
+		meta, _, _ := strings.Cut(in.URL, ",")
+		return meta + ",..."
+	}
+	// Cut unparseable URLs at the first query or fragment delimiter
+	u, _, _ := strings.Cut(redact.StripURL(in.URL), "?")
+	u, _, _ = strings.Cut(u, "#")
+	return u
+}
+
+// header returns the request headers given with the input
+func (in *Input) header() http.Header {
+	h := make(http.Header, len(in.Headers))
+	for name, value := range in.Headers {
+		h.Set(name, value)
+	}
+	return h
+}
@@ -0,0 +1,366 @@
+package inputs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ffmpeg-worker/adapters"
+	"ffmpeg-worker/redact"
+	"ffmpeg-worker/system"
+)
+
+// Input modes
+const (
+	ModeDownload = "download" // Fetch the whole input before running ffmpeg (default)
+	ModeStream   = "stream"   // Let ffmpeg read the input as it needs it
+)
+
+// streamHeaders are passed from the source to ffmpeg
+var streamHeaders = []string{
+	"Accept-Ranges",
+	"Content-Length",
+	"Content-Range",
+	"Content-Type",
+	"ETag",
+	"Last-Modified",
+}
+
+// StreamProxy lets ffmpeg read inputs without downloading them first, e.g. to
+// grab one frame from a large video. Each input is served on a loopback URL
+// and ffmpeg's range requests are forwarded through the input adapter, so the
+// egress policy, headers and auth profiles apply as they do to downloads. The
+// proxy is also served on a unix socket, which sandboxes relay the loopback
+// address to.
+type StreamProxy struct {
+	protocols         []string      // URL schemes that may be streamed
+	reconnectDelayMax time.Duration // Longest ffmpeg waits between reconnects
+	maxBytes          int64         // Largest input accepted (0 = unlimited)
+
+	addr    string
+	socket  string
+	mu      sync.Mutex
+	streams map[string]*stream
+}
+
+type stream struct {
+	url      *url.URL
+	streamer adapters.InputStreamer
+	opts     adapters.FetchOptions
+	maxBytes int64
+	served   atomic.Int64 // Body bytes sent so far, over all responses
+}
+
+// streamBody counts what's read from a response body against its stream's
+// limit, so ffmpeg can't read more than maxBytes in total by making many
+// range requests. Each read reserves its buffer's size first, so concurrent
+// responses can't overshoot the limit between them.
+type streamBody struct {
+	body     io.Reader
+	s        *stream
+	exceeded bool // A read was cut short by the limit
+}
+
+func (b *streamBody) Read(p []byte) (int, error) {
+	n := int64(len(p))
+	if n == 0 {
+		return 0, nil
+	}
+	over := b.s.served.Add(n) - b.s.maxBytes
+	if over >= n {
+		b.s.served.Add(-n)
+		b.exceeded = true
+		return 0, io.EOF
+	}
+	if over > 0 {
+		p = p[:n-over]
+	}
+	read, err := b.body.Read(p)
+	b.s.served.Add(int64(read) - n)
+	return read, err
+}
+
+// NewStreamProxy starts a proxy on a loopback port and a unix socket in a
+// new temporary directory. protocols lists the URL schemes inputs may be
+// streamed from (e.g. "http,https").
+func NewStreamProxy(protocols string, reconnectDelayMax time.Duration, maxBytes int64) (*StreamProxy, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	unixLn, err := listenUnix()
+	if err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+
+	p := &StreamProxy{
+		reconnectDelayMax: reconnectDelayMax,
+		maxBytes:          maxBytes,
+		addr:              ln.Addr().String(),
+		socket:            unixLn.Addr().String(),
+		streams:           make(map[string]*stream),
+	}
+	for _, proto := range strings.Split(protocols, ",") {
+		if proto = strings.ToLower(strings.TrimSpace(proto)); proto != "" {
+			p.protocols = append(p.protocols, proto)
+		}
+	}
+
+	for _, l := range []net.Listener{ln, unixLn} {
+		go func() {
+			if err := http.Serve(l, p); err != nil {
+				log.Printf("Stream proxy stopped: %v", err)
+			}
+		}()
+	}
+	log.Printf("Stream proxy listening on %s and %s for %s", p.addr, p.socket, strings.Join(p.protocols, ", "))
+	return p, nil
+}
+
+// listenUnix listens on a unix socket in a directory only the worker can
+// open. Anyone may connect to the socket itself, so a sandbox given a
+// descriptor for it can.
+func listenUnix() (net.Listener, error) {
+	dir, err := os.MkdirTemp("", "stream-proxy-")
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", filepath.Join(dir, "proxy.sock"))
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(filepath.Join(dir, "proxy.sock"), 0666); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// Relay returns how a sandbox reaches the proxy at its loopback address
+func (p *StreamProxy) Relay() *system.Relay {
+	return &system.Relay{Addr: p.addr, Socket: p.socket}
+}
+
+// Allows reports whether inputs with u's scheme may be streamed
+func (p *StreamProxy) Allows(u *url.URL) bool {
+	return slices.Contains(p.protocols, strings.ToLower(u.Scheme))
+}
+
+// register serves an input under name until the returned function is called
+func (p *StreamProxy) register(u *url.URL, name string, streamer adapters.InputStreamer, opts adapters.FetchOptions) (string, func(), error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", nil, err
+	}
+	token := hex.EncodeToString(b[:])
+
+	maxBytes := p.maxBytes
+	if opts.MaxBytes > 0 && (maxBytes <= 0 || opts.MaxBytes < maxBytes) {
+		maxBytes = opts.MaxBytes
+	}
+
+	p.mu.Lock()
+	p.streams[token] = &stream{url: u, streamer: streamer, opts: opts, maxBytes: maxBytes}
+	p.mu.Unlock()
+
+	release := func() {
+		p.mu.Lock()
+		delete(p.streams, token)
+		p.mu.Unlock()
+	}
+	return fmt.Sprintf("http://%s/%s/%s", p.addr, token, url.PathEscape(name)), release, nil
+}
+
+// ServeHTTP serves registered inputs. Requests ffmpeg makes through the
+// proxy for other URLs, including CONNECT for HTTPS, are refused.
+func (p *StreamProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Host != "" && r.URL.Host != p.addr {
+		http.Error(w, "only registered inputs can be read", http.StatusForbidden)
+		return
+	}
+	token, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	p.mu.Lock()
+	s, ok := p.streams[token]
+	p.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp, err := s.streamer.Open(r.Context(), s.url, s.opts, r.Header)
+	if err != nil {
+		log.Printf("Stream of %s failed: %v", redact.StripURL(s.url.String()), err)
+		http.Error(w, "upstream request failed", http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	if size := objectSize(resp); s.maxBytes > 0 && size > s.maxBytes {
+		log.Printf("Stream of %s refused: %d bytes, limit is %d", redact.StripURL(s.url.String()), size, s.maxBytes)
+		http.Error(w, "input too large", http.StatusForbidden)
+		return
+	}
+	if s.maxBytes > 0 && r.Method == http.MethodGet && s.served.Load() >= s.maxBytes {
+		log.Printf("Stream of %s refused: %d bytes already read, limit is %d", redact.StripURL(s.url.String()), s.served.Load(), s.maxBytes)
+		http.Error(w, "input read limit reached", http.StatusForbidden)
+		return
+	}
+
+	for _, name := range streamHeaders {
+		if v := resp.Header.Get(name); v != "" {
+			w.Header().Set(name, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	if r.Method == http.MethodGet {
+		if s.maxBytes <= 0 {
+			io.Copy(w, resp.Body)
+			return
+		}
+		body := &streamBody{body: resp.Body, s: s}
+		io.Copy(w, body)
+		if body.exceeded {
+			log.Printf("Stream of %s cut off: read limit of %d bytes reached", redact.StripURL(s.url.String()), s.maxBytes)
+		}
+	}
+}
+
+// InputArgs adds reconnect options and a protocol allowlist before each -i
+// that reads a streamed input. ffmpeg is also told to use the proxy as its
+// HTTP proxy, so references within an input (e.g. playlist segments) can't
+// reach anything but the registered inputs.
+func (p *StreamProxy) InputArgs(args []string, urls map[string]string) []string {
+	streamed := make(map[string]bool, len(urls))
+	for _, u := range urls {
+		streamed[u] = true
+	}
+
+	out := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "-i" && i+1 < len(args) && streamed[args[i+1]] {
+			out = append(out,
+				"-protocol_whitelist", "http,tcp",
+				"-http_proxy", "http://"+p.addr,
+				"-reconnect", "1",
+				"-reconnect_on_network_error", "1",
+				"-reconnect_delay_max", strconv.Itoa(int(p.reconnectDelayMax.Seconds())),
+			)
+		}
+		out = append(out, arg)
+	}
+	return out
+}
+
+// StreamAll starts serving the inputs with mode "stream" and returns their
+// loopback URLs by key, along with a function that stops serving them. Inputs
+// that can't be streamed, or whose expected media kind can't be confirmed from
+// the job directory dir, are left out so they are downloaded instead.
+func (d *Downloader) StreamAll(ctx context.Context, commandID, dir string, inputs map[string]Input) (map[string]string, func(), error) {
+	urls := make(map[string]string)
+	var releases []func()
+	releaseAll := func() {
+		for _, release := range releases {
+			release()
+		}
+	}
+
+	for key, in := range inputs {
+		if in.Mode != ModeStream {
+			continue
+		}
+		if err := ValidateKey(key); err != nil {
+			releaseAll()
+			return nil, nil, fmt.Errorf("input %s: %w", key, err)
+		}
+		if err := in.Validate(); err != nil {
+			releaseAll()
+			return nil, nil, fmt.Errorf("input %s: %w", key, err)
+		}
+
+		localURL, release, err := d.stream(commandID, key, in)
+		if err != nil {
+			releaseAll()
+			return nil, nil, fmt.Errorf("stream %s: %w", key, err)
+		}
+		if localURL == "" {
+			continue
+		}
+		if err := d.checkMedia(ctx, in, dir, localURL); err != nil {
+			log.Printf("[%s] Downloading %s instead of streaming: %v", commandID, key, err)
+			release()
+			continue
+		}
+		releases = append(releases, release)
+		urls[key] = localURL
+		log.Printf("[%s] Streaming %s: %s", commandID, key, in.logURL())
+	}
+	return urls, releaseAll, nil
+}
+
+// stream registers one input with the proxy. It returns "" if the input should
+// be downloaded instead.
+func (d *Downloader) stream(commandID, key string, in Input) (string, func(), error) {
+	u, err := in.parseURL()
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: invalid url: %v", ErrRejected, err)
+	}
+	if d.Streams == nil || !d.Streams.Allows(u) {
+		log.Printf("[%s] Streaming isn't enabled for %s inputs, downloading %s", commandID, u.Scheme, key)
+		return "", nil, nil
+	}
+	adapter, err := d.Adapters.For(u)
+	if err != nil {
+		return "", nil, err
+	}
+	streamer, ok := adapter.(adapters.InputStreamer)
+	if !ok {
+		log.Printf("[%s] The %s adapter can't stream, downloading %s", commandID, adapter.Name(), key)
+		return "", nil, nil
+	}
+	header, err := d.header(in, u)
+	if err != nil {
+		return "", nil, err
+	}
+	// Keep the source's file name, which helps ffmpeg pick a demuxer
+	name := u.Path[strings.LastIndex(u.Path, "/")+1:]
+	if in.Extension != "" {
+		name = strings.TrimSuffix(name, path.Ext(name)) + in.pathExtension()
+	}
+	return d.Streams.register(u, name, streamer, adapters.FetchOptions{MaxBytes: in.MaxBytes, Header: header})
+}
+
+// objectSize returns the full size of the object in a response, or -1 if unknown
+func objectSize(resp *http.Response) int64 {
+	if cr := resp.Header.Get("Content-Range"); cr != "" {
+		if _, total, ok := strings.Cut(cr, "/"); ok {
+			if n, err := strconv.ParseInt(total, 10, 64); err == nil {
+				return n
+			}
+		}
+		return -1
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp.ContentLength
+	}
+	return -1
+}
@@ -0,0 +1,76 @@
+package inputs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"ffmpeg-worker/adapters"
+)
+
+// rangeStreamer serves content, honouring Range like an HTTP server would
+type rangeStreamer struct {
+	content string
+}
+
+func (s rangeStreamer) Open(ctx context.Context, u *url.URL, opts adapters.FetchOptions, ranges http.Header) (*http.Response, error) {
+	req := httptest.NewRequest("GET", u.String(), nil)
+	req.Header = ranges.Clone()
+	rec := httptest.NewRecorder()
+	http.ServeContent(rec, req, "", time.Time{}, strings.NewReader(s.content))
+	return rec.Result(), nil
+}
+
+func TestStreamProxyLimitsTotalRead(t *testing.T) {
+	p := &StreamProxy{addr: "127.0.0.1:1", maxBytes: 10, streams: make(map[string]*stream)}
+	u, _ := url.Parse("https://example.com/in.mp4")
+	localURL, release, err := p.register(u, "in.mp4", rangeStreamer{"0123456789"}, adapters.FetchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	tests := []struct {
+		rangeHeader string
+		wantStatus  int
+		wantBody    string
+	}{
+		{"bytes=0-3", http.StatusPartialContent, "0123"},
+		{"bytes=0-3", http.StatusPartialContent, "0123"}, // Seeking back is counted again
+		{"bytes=2-", http.StatusPartialContent, "23"},    // Cut off at 10 bytes in total
+		{"bytes=0-0", http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", localURL, nil)
+		req.Header.Set("Range", tt.rangeHeader)
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("Range %s: status %d, want %d", tt.rangeHeader, rec.Code, tt.wantStatus)
+			continue
+		}
+		if tt.wantStatus < 300 && rec.Body.String() != tt.wantBody {
+			t.Errorf("Range %s: body %q, want %q", tt.rangeHeader, rec.Body.String(), tt.wantBody)
+		}
+	}
+}
+
+func TestStreamProxyRefusesLargeInput(t *testing.T) {
+	p := &StreamProxy{addr: "127.0.0.1:1", maxBytes: 100, streams: make(map[string]*stream)}
+	u, _ := url.Parse("https://example.com/in.mp4")
+	localURL, release, err := p.register(u, "in.mp4", rangeStreamer{"0123456789"}, adapters.FetchOptions{MaxBytes: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest("GET", localURL, nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,74 @@
+package inputs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// verify checks that a downloaded input is the kind of media requested. Files
+// that fail are removed so they can't be used by mistake.
+func (d *Downloader) verify(ctx context.Context, in Input, localPath string) error {
+	err := d.checkMedia(ctx, in, filepath.Dir(localPath), localPath)
+	if err != nil {
+		os.Remove(localPath)
+	}
+	return err
+}
+
+// checkDigest compares the file's SHA-256 with the one requested
+func checkDigest(in Input, localPath string) error {
+	if in.SHA256 == "" {
+		return nil
+	}
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(sum, in.SHA256) {
+		return fmt.Errorf("%w: sha256 is %s, expected %s", ErrRejected, sum, strings.ToLower(in.SHA256))
+	}
+	return nil
+}
+
+// checkMedia probes a file or streamed input in dir and compares it with the
+// expected kind of media
+func (d *Downloader) checkMedia(ctx context.Context, in Input, dir, target string) error {
+	expect := strings.ToLower(in.Expect)
+	if expect == "" {
+		return nil
+	}
+	info, err := d.Probe.Media(ctx, dir, target)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w: not a media file: %v", ErrRejected, err)
+	}
+
+	var ok bool
+	switch expect {
+	case ExpectVideo:
+		ok = info.HasVideo()
+	case ExpectAudio:
+		ok = info.HasAudio()
+	case ExpectImage:
+		ok = info.IsImage()
+	}
+	if !ok {
+		return fmt.Errorf("%w: expected %s, found %s with streams [%s]",
+			ErrRejected, expect, info.FormatName, strings.Join(info.Streams, ", "))
+	}
+	return nil
+}
@@ -3,22 +3,30 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
+	"maps"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strconv"
+	"slices"
 	"strings"
 	"time"
 
 	"ffmpeg-worker/adapters"
+	"ffmpeg-worker/argpolicy"
 	"ffmpeg-worker/config"
+	"ffmpeg-worker/egress"
+	"ffmpeg-worker/inputs"
+	"ffmpeg-worker/redact"
+	"ffmpeg-worker/secrets"
+	"ffmpeg-worker/shellwords"
 	"ffmpeg-worker/system"
 
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
 )
 
 const (
@@ -26,13 +34,23 @@ const (
 	TypeWebhookDeliver = "webhook:deliver"
 )
 
+// webhookMaxRetryCeiling is the asynq retry limit for webhook tasks. The webhooks
+// service applies its own retry schedule and archives deliveries when it's exhausted,
+// so this only needs to be higher than any schedule it will be configured with.
+const webhookMaxRetryCeiling = 100
+
+// webhookSequenceKeyPrefix is the Redis key prefix for each command's webhook event counter
+const webhookSequenceKeyPrefix = "webhook:sequence:"
+
 type CommandRequest struct {
-	InputFiles     map[string]string `json:"input_files"`
-	OutputFiles    map[string]string `json:"output_files"`
-	FFmpegCommand  string            `json:"ffmpeg_command,omitempty"`
-	FFmpegCommands []string          `json:"ffmpeg_commands,omitempty"`
-	Webhook        string            `json:"webhook,omitempty"`
-	ReferenceID    string            `json:"reference_id,omitempty"`
+	InputFiles     map[string]inputs.Input `json:"input_files"`
+	OutputFiles    map[string]string       `json:"output_files"`
+	FFmpegCommand  string                  `json:"ffmpeg_command,omitempty"`
+	FFmpegCommands []string                `json:"ffmpeg_commands,omitempty"`
+	FFmpegArgs     [][]string              `json:"ffmpeg_args,omitempty"`
+	Webhook        string                  `json:"webhook,omitempty"`
+	ReferenceID    string                  `json:"reference_id,omitempty"`
+	ResourceClass  string                  `json:"resource_class,omitempty"`
 }
 
 type OutputFileInfo struct {
@@ -49,25 +67,45 @@ type CommandResult struct {
 	OutputFiles             map[string]OutputFileInfo `json:"output_files"`
 	FFmpegCommandRunSeconds float64                   `json:"ffmpeg_command_run_seconds"`
 	TotalProcessingSeconds  float64                   `json:"total_processing_seconds"`
+	DownloadSeconds         float64                   `json:"download_seconds"`
+	DownloadBytes           int64                     `json:"download_bytes"`
+	DownloadMBytesPerSecond float64                   `json:"download_mbytes_per_second"`
 	CompletedAt             time.Time                 `json:"completed_at"`
 	HardwareAcceleration    string                    `json:"hardware_acceleration,omitempty"`
 }
 
 type WebhookPayload struct {
-	URL       string         `json:"url"`
-	CommandID string         `json:"command_id"`
-	Status    string         `json:"status"`
-	Body      map[string]any `json:"body"`
+	URL         string         `json:"url"`
+	CommandID   string         `json:"command_id"`
+	Status      string         `json:"status"`
+	Sequence    int64          `json:"sequence,omitempty"`
+	ReferenceID string         `json:"reference_id,omitempty"`
+	Body        map[string]any `json:"body"`
+	EnqueuedAt  time.Time      `json:"enqueued_at"`
 }
 
 var (
-	cfg            *config.Config
-	storageAdapter adapters.OutputAdapter
-	webhookClient  *asynq.Client
-	hwCapabilities system.HardwareCapabilities
+	cfg             *config.Config
+	storageAdapter  adapters.OutputAdapter
+	inputDownloader *inputs.Downloader
+	webhookClient   *asynq.Client
+	redisClient     *redis.Client
+	webhookPolicy   *egress.Policy
+	redactor        *redact.Redactor
+	downloadPolicy  *egress.Policy
+	argPolicy       *argpolicy.Policy
+	executor        system.Executor
+	prober          *system.Prober
+	jobBudget       *system.Budget
+	hwCapabilities  system.HardwareCapabilities
 )
 
 func main() {
+	// The sandbox executor starts this binary to set up each ffmpeg run
+	if len(os.Args) > 1 && os.Args[1] == system.SandboxInitArg {
+		system.SandboxInit(os.Args[2:])
+	}
+
 	// Load configuration
 	cfg = config.Load()
 	os.MkdirAll(cfg.Worker.WorkDir, 0755)
@@ -97,14 +135,143 @@ func main() {
 	}
 	log.Printf("Storage adapter: %s", storageAdapter.Name())
 
+	// Webhook destinations are checked before enqueueing, and again by the webhooks service
+	webhookPolicy, err = egress.NewPolicy(
+		cfg.Webhook.AllowedSchemes,
+		cfg.Webhook.AllowedHosts,
+		cfg.Webhook.AllowedPorts,
+		cfg.Webhook.BlockedCIDRs,
+		cfg.Webhook.MaxRedirects,
+	)
+	if err != nil {
+		log.Fatalf("Invalid webhook destination policy: %v", err)
+	}
+
+	// The original request is redacted before it's included in webhooks
+	redactor, err = redact.New(cfg.Redact.StripQuery, cfg.Redact.DropFields, cfg.Redact.MaskPatterns)
+	if err != nil {
+		log.Fatalf("Invalid redaction settings: %v", err)
+	}
+	// Forwarded input headers may carry credentials
+	redactor.MaskFields("input_files.*.headers.*")
+
+	// Commands may only use files in their job directory
+	argPolicy, err = argpolicy.New(
+		cfg.FFmpeg.DeniedOptions,
+		cfg.FFmpeg.DeniedFilters,
+		cfg.FFmpeg.DeniedFormats,
+		cfg.FFmpeg.AllowedProtocols,
+	)
+	if err != nil {
+		log.Fatalf("Invalid ffmpeg argument policy: %v", err)
+	}
+
+	// ffmpeg and ffprobe only get the environment they need, not the worker's credentials
+	system.SetExecEnv(cfg.FFmpeg.Env)
+	executor, err = system.NewExecutor(cfg.FFmpeg.Executor, cfg.GetSandboxOptions())
+	if err != nil {
+		log.Fatalf("Failed to initialize ffmpeg executor: %v", err)
+	}
+	log.Printf("FFmpeg executor: %s", executor.Name())
+	// Limits the executor can't apply are logged rather than refused, so
+	// the defaults work everywhere
+	var unenforced []string
+	for _, class := range cfg.Resources.Classes {
+		unenforced = append(unenforced, executor.Unenforced(cfg.GetJobLimits(class))...)
+	}
+	slices.Sort(unenforced)
+	if len(unenforced) > 0 {
+		log.Printf("The %s executor can't enforce these job limits: %s (they need FFMPEG_EXECUTOR=sandbox with SANDBOX_CGROUP_PARENT on Linux)",
+			executor.Name(), strings.Join(slices.Compact(unenforced), ", "))
+	}
+
+	// Options ffmpeg doesn't know are rejected, and which are flags decides
+	// how the rest of a command is read
+	help := exec.Command("ffmpeg", "-hide_banner", "-h", "full")
+	help.Env = system.ExecEnv()
+	helpOutput, err := help.Output()
+	if err != nil {
+		log.Fatalf("Failed to list ffmpeg options: %v", err)
+	}
+	argPolicy.Options = argpolicy.ParseHelp(string(helpOutput))
+	log.Printf("FFmpeg options known to the argument policy: %d", len(argPolicy.Options))
+	defaultClass, ok := cfg.Resources.Classes[cfg.Resources.DefaultClass]
+	if !ok {
+		log.Fatalf("Unknown default resource class: %s", cfg.Resources.DefaultClass)
+	}
+	// Inputs and outputs are probed before a job's class is known
+	prober = &system.Prober{Executor: executor, Limits: cfg.GetJobLimits(defaultClass), Timeout: cfg.FFmpeg.ProbeTimeout}
+	jobBudget = cfg.GetJobBudget()
+	log.Printf("Job budget: cpu %d, memory %d MB (0 = unlimited)", cfg.Resources.BudgetCPU, cfg.Resources.BudgetMemoryMB)
+	concurrency := cfg.GetConcurrency()
+
+	// Input downloads are restricted so callers can't reach internal services
+	downloadPolicy, err = egress.NewPolicy(
+		cfg.Download.AllowedSchemes,
+		cfg.Download.AllowedHosts,
+		cfg.Download.AllowedPorts,
+		cfg.Download.BlockedCIDRs,
+		cfg.Download.MaxRedirects,
+	)
+	if err != nil {
+		log.Fatalf("Invalid download egress policy: %v", err)
+	}
+	downloadPolicy.DialTimeout = cfg.Download.ConnectTimeout
+	// Inputs are fetched in parallel by the adapter for their URL scheme
+	inputAdapters, err := adapters.NewInputAdapters(downloadPolicy.Client(0), downloadPolicy, adapters.InputLimits{
+		MaxBytes:    cfg.Download.MaxBytes,
+		IdleTimeout: cfg.Download.IdleTimeout,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize input adapters: %v", err)
+	}
+	authProfiles, err := inputs.LoadAuthProfiles(cfg.Download.AuthProfilesFile, &secrets.Store{Dir: cfg.SecretsDir})
+	if err != nil {
+		log.Fatalf("Failed to load input auth profiles: %v", err)
+	}
+	var inputCache *inputs.Cache
+	if cfg.Download.CacheDir != "" {
+		// Sandboxed ffmpeg runs as another user, so it can't change linked entries
+		inputCache, err = inputs.NewCache(cfg.Download.CacheDir, cfg.Download.CacheMaxBytes, executor.Name() == "sandbox")
+		if err != nil {
+			log.Fatalf("Failed to open input cache: %v", err)
+		}
+	}
+	var streamProxy *inputs.StreamProxy
+	if cfg.Download.StreamProtocols != "" {
+		streamProxy, err = inputs.NewStreamProxy(cfg.Download.StreamProtocols, cfg.Download.StreamReconnect, cfg.Download.MaxBytes)
+		if err != nil {
+			log.Fatalf("Failed to start stream proxy: %v", err)
+		}
+		prober.Relay = streamProxy.Relay()
+	}
+	inputDownloader = &inputs.Downloader{
+		Adapters:    inputAdapters,
+		Auth:        authProfiles,
+		Cache:       inputCache,
+		Streams:     streamProxy,
+		Probe:       prober,
+		Concurrency: cfg.Download.Concurrency,
+		Retries:     cfg.Download.Retries,
+		Backoff:     cfg.Download.RetryBackoff,
+		Archives: inputs.ArchiveLimits{
+			MaxFiles: cfg.Download.ArchiveMaxFiles,
+			MaxBytes: cfg.Download.ArchiveMaxBytes,
+		},
+	}
+
 	// Create asynq client for enqueueing webhook tasks
 	webhookClient = asynq.NewClient(asynq.RedisClientOpt{Addr: cfg.Redis.Addr})
 	defer webhookClient.Close()
 
+	// Used to number each command's webhook events
+	redisClient = redis.NewClient(&redis.Options{Addr: cfg.Redis.Addr})
+	defer redisClient.Close()
+
 	srv := asynq.NewServer(
 		asynq.RedisClientOpt{Addr: cfg.Redis.Addr},
 		asynq.Config{
-			Concurrency: cfg.Worker.Concurrency,
+			Concurrency: concurrency,
 			Queues:      map[string]int{"ffmpeg": 1},
 			// Add resource check before processing each task
 			IsFailure: func(err error) bool {
@@ -120,13 +287,13 @@ func main() {
 	mux := asynq.NewServeMux()
 	mux.HandleFunc(TypeFFmpegCommand, handleFFmpegCommand)
 
-	log.Printf("FFmpeg Command Worker started (concurrency=%d)", cfg.Worker.Concurrency)
+	log.Printf("FFmpeg Command Worker started (concurrency=%d)", concurrency)
 	if err := srv.Run(mux); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func handleFFmpegCommand(ctx context.Context, t *asynq.Task) error {
+func handleFFmpegCommand(ctx context.Context, t *asynq.Task) (err error) {
 	// Check resource availability before processing
 	if cfg.Resources.Enabled {
 		if ok, reason := system.CheckResourcesAvailable(cfg.GetResourceLimits()); !ok {
@@ -146,24 +313,88 @@ func handleFFmpegCommand(ctx context.Context, t *asynq.Task) error {
 	os.MkdirAll(jobDir, 0755)
 	defer os.RemoveAll(jobDir)
 
+	// Webhook events: PROCESSING when the first attempt starts, then SUCCESS
+	// below or FAILED once no attempt is left
+	if req.Webhook != "" {
+		if retried, _ := asynq.GetRetryCount(ctx); retried == 0 {
+			enqueueWebhook(ctx, commandID, "PROCESSING", &req, map[string]any{})
+		}
+		defer func() {
+			if err != nil && finalFailure(ctx, err) {
+				// The task's context may have timed out, which is why it failed
+				ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+				defer cancel()
+				enqueueWebhook(ctx, commandID, "FAILED", &req, map[string]any{"error": err.Error()})
+			}
+		}()
+	}
+
 	log.Printf("[%s] Starting command with %d inputs, %d outputs", commandID, len(req.InputFiles), len(req.OutputFiles))
 	startTime := time.Now()
 
-	// Download input files
-	inputPaths := make(map[string]string)
-	for key, url := range req.InputFiles {
-		ext := filepath.Ext(url)
-		if ext == "" || len(ext) > 5 {
-			ext = ".mp4"
+	if _, ok := cfg.Resources.Classes[req.ResourceClass]; req.ResourceClass != "" && !ok {
+		return fmt.Errorf("unknown resource class: %s: %w", req.ResourceClass, asynq.SkipRetry)
+	}
+	// Input keys become file names in the job directory
+	for key := range req.InputFiles {
+		if err := inputs.ValidateKey(key); err != nil {
+			return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+		}
+	}
+
+	// Get commands to run; one that can't be parsed or isn't allowed won't be on retry either
+	commands, err := commandArgs(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+	}
+	// Check what can be checked before downloading; runCommands checks again once paths are known
+	for i, args := range commands {
+		if err := argPolicy.Check(args, argpolicy.Scope{}); err != nil {
+			return fmt.Errorf("command %d: %w: %w", i+1, err, asynq.SkipRetry)
 		}
-		localPath := filepath.Join(jobDir, key+ext)
+	}
+
+	// Wait until the command's weight fits in the worker's budget before
+	// fetching its inputs, which the budget also covers
+	className := req.ResourceClass
+	if className == "" {
+		className = cfg.Resources.DefaultClass
+	}
+	releaseBudget, err := acquireBudget(ctx, commandID, className)
+	if err != nil {
+		return err
+	}
+	defer func() { releaseBudget() }()
 
-		if err := downloadFile(ctx, url, localPath); err != nil {
-			return fmt.Errorf("download %s: %w", key, err)
+	// Streamed inputs are read by ffmpeg as it runs; the rest are downloaded
+	// first, then inline content is written since it may refer to them
+	streamURLs, releaseStreams, err := inputDownloader.StreamAll(ctx, commandID, jobDir, req.InputFiles)
+	if err != nil {
+		return inputError(err)
+	}
+	defer releaseStreams()
+
+	downloads := make(map[string]inputs.Input, len(req.InputFiles))
+	inline := make(map[string]inputs.Input)
+	for key, in := range req.InputFiles {
+		if in.Content != "" {
+			inline[key] = in
+		} else if _, ok := streamURLs[key]; !ok {
+			downloads[key] = in
 		}
-		inputPaths[key] = localPath
-		log.Printf("[%s] Downloaded %s: %s", commandID, key, url)
 	}
+	inputPaths, downloadStats, err := inputDownloader.DownloadAll(ctx, commandID, jobDir, downloads)
+	if err != nil {
+		return inputError(err)
+	}
+	maps.Copy(inputPaths, streamURLs)
+	inlinePaths, err := inputDownloader.WriteInline(ctx, commandID, jobDir, inline, inputPaths)
+	if err != nil {
+		return inputError(err)
+	}
+	maps.Copy(inputPaths, inlinePaths)
+	log.Printf("[%s] Downloaded %d inputs: %d bytes in %s (%.2f MB/s)", commandID, len(inputPaths),
+		downloadStats.Bytes, downloadStats.Duration.Round(time.Millisecond), downloadStats.MBytesPerSecond())
 
 	if ctx.Err() != nil {
 		return fmt.Errorf("command cancelled")
@@ -175,63 +406,64 @@ func handleFFmpegCommand(ctx context.Context, t *asynq.Task) error {
 		outputPaths[key] = filepath.Join(jobDir, filename)
 	}
 
-	// Get commands to run
-	var commands []string
-	if len(req.FFmpegCommands) > 0 {
-		commands = req.FFmpegCommands
-	} else if req.FFmpegCommand != "" {
-		commands = []string{req.FFmpegCommand}
-	}
-
 	// Try to get duration of first input for progress tracking
 	var inputDurationMS int64
 	for _, path := range inputPaths {
-		if dur, err := system.GetMediaDuration(path); err == nil && dur > 0 {
+		if dur, err := prober.Duration(ctx, jobDir, path); err == nil && dur > 0 {
 			inputDurationMS = dur
 			break
 		}
 	}
 
-	// Execute each command
-	ffmpegStart := time.Now()
-	for i, cmd := range commands {
-		// Replace placeholders with actual paths
-		expandedCmd := expandPlaceholders(cmd, inputPaths, outputPaths)
-
-		log.Printf("[%s] Running command %d/%d: ffmpeg %s", commandID, i+1, len(commands), expandedCmd)
-
-		// Parse command into args (respecting quotes)
-		args := parseCommandArgs(expandedCmd)
-		args = append([]string{"-y"}, args...) // Always overwrite
-
-		// Execute with progress tracking if we have duration info
-		var output []byte
-		var err error
-
-		if inputDurationMS > 0 {
-			runner := &system.FFmpegRunner{
-				DurationMS: inputDurationMS,
-				OnProgress: func(p system.FFmpegProgress) {
-					if p.PercentDone > 0 {
-						log.Printf("[%s] Progress: %.1f%% (speed: %s)", commandID, p.PercentDone, p.Speed)
-					}
-				},
+	// A command admitted with the default class is readmitted with the
+	// weight of the class estimated from its inputs. Nothing is held while it
+	// waits, so commands readmitted at once can't block each other.
+	if req.ResourceClass == "" {
+		estimated := estimateResourceClass(ctx, jobDir, inputPaths, inputDurationMS)
+		if classWeight(estimated) != classWeight(className) {
+			releaseBudget()
+			release, err := acquireBudget(ctx, commandID, estimated)
+			if err != nil {
+				return err
 			}
-			output, err = runner.Run(ctx, args)
-		} else {
-			// Fallback to standard execution
-			execCmd := exec.CommandContext(ctx, "ffmpeg", args...)
-			output, err = execCmd.CombinedOutput()
+			releaseBudget = release
 		}
+		className = estimated
+	}
+	class := cfg.Resources.Classes[className]
+	log.Printf("[%s] Running as %s", commandID, className)
 
-		if err != nil {
-			if ctx.Err() != nil {
-				return fmt.Errorf("command cancelled during encoding")
-			}
-			return fmt.Errorf("ffmpeg failed (command %d): %w\n%s", i+1, err, string(output))
+	// Execute each command
+	ffmpegStart := time.Now()
+	err = runCommands(ctx, commandID, jobDir, commands, inputPaths, outputPaths, streamURLs, inputDurationMS, class)
+	if err != nil && len(streamURLs) > 0 && ctx.Err() == nil && !errors.Is(err, argpolicy.ErrDenied) {
+		// The source may not support streaming well; fall back to downloading it
+		log.Printf("[%s] Command failed with streamed inputs, downloading them and retrying: %v", commandID, err)
+		streamed := make(map[string]inputs.Input, len(streamURLs))
+		for key := range streamURLs {
+			streamed[key] = req.InputFiles[key]
 		}
+		paths, stats, downloadErr := inputDownloader.DownloadAll(ctx, commandID, jobDir, streamed)
+		if downloadErr != nil {
+			return inputError(downloadErr)
+		}
+		maps.Copy(inputPaths, paths)
+		downloadStats.Bytes += stats.Bytes
+		downloadStats.Duration += stats.Duration
+		// Rewrite inline content that referred to the stream URLs
+		if inlinePaths, downloadErr = inputDownloader.WriteInline(ctx, commandID, jobDir, inline, inputPaths); downloadErr != nil {
+			return inputError(downloadErr)
+		}
+		maps.Copy(inputPaths, inlinePaths)
+
+		ffmpegStart = time.Now()
+		err = runCommands(ctx, commandID, jobDir, commands, inputPaths, outputPaths, nil, inputDurationMS, class)
+	}
+	if err != nil {
+		return err
 	}
 	ffmpegDuration := time.Since(ffmpegStart).Seconds()
+	releaseBudget() // Uploads don't need it
 
 	if ctx.Err() != nil {
 		return fmt.Errorf("command cancelled")
@@ -267,9 +499,10 @@ func handleFFmpegCommand(ctx context.Context, t *asynq.Task) error {
 
 		// Get dimensions for video/image (from local file before cleanup)
 		if fileType == "video" || fileType == "image" {
-			w, h := getMediaDimensions(localPath)
-			info.Width = w
-			info.Height = h
+			if w, h, err := prober.Dimensions(ctx, jobDir, localPath); err == nil {
+				info.Width = w
+				info.Height = h
+			}
 		}
 
 		outputFiles[key] = info
@@ -282,12 +515,23 @@ func handleFFmpegCommand(ctx context.Context, t *asynq.Task) error {
 		OutputFiles:             outputFiles,
 		FFmpegCommandRunSeconds: ffmpegDuration,
 		TotalProcessingSeconds:  totalDuration,
+		DownloadSeconds:         downloadStats.Duration.Seconds(),
+		DownloadBytes:           downloadStats.Bytes,
+		DownloadMBytesPerSecond: downloadStats.MBytesPerSecond(),
 		CompletedAt:             time.Now(),
 		HardwareAcceleration:    string(hwCapabilities.AccelType),
 	}
 
 	if req.Webhook != "" {
-		enqueueWebhook(req.Webhook, commandID, &result, &req)
+		enqueueWebhook(ctx, commandID, "SUCCESS", &req, map[string]any{
+			"output_files":               result.OutputFiles,
+			"ffmpeg_command_run_seconds": result.FFmpegCommandRunSeconds,
+			"total_processing_seconds":   result.TotalProcessingSeconds,
+			"download_seconds":           result.DownloadSeconds,
+			"download_bytes":             result.DownloadBytes,
+			"download_mbytes_per_second": result.DownloadMBytesPerSecond,
+			"hardware_acceleration":      result.HardwareAcceleration,
+		})
 	}
 
 	resultBytes, _ := json.Marshal(result)
@@ -297,22 +541,52 @@ func handleFFmpegCommand(ctx context.Context, t *asynq.Task) error {
 	return nil
 }
 
-func enqueueWebhook(url, commandID string, result *CommandResult, req *CommandRequest) {
-	webhookBody := map[string]any{
-		"command_id":                 commandID,
-		"status":                     "SUCCESS",
-		"output_files":               result.OutputFiles,
-		"original_request":           req,
-		"ffmpeg_command_run_seconds": result.FFmpegCommandRunSeconds,
-		"total_processing_seconds":   result.TotalProcessingSeconds,
-		"hardware_acceleration":      result.HardwareAcceleration,
+// enqueueWebhook queues a webhook event for the command. body is sent along
+// with the command ID, status and, unless disabled, the original request.
+func enqueueWebhook(ctx context.Context, commandID, status string, req *CommandRequest, body map[string]any) {
+	webhookURL := req.Webhook
+	// Non-HTTP sinks (e.g. redis-stream://) are internal, so only their scheme is checked
+	dest, err := url.Parse(webhookURL)
+	if err == nil {
+		if dest.Scheme == "http" || dest.Scheme == "https" {
+			err = webhookPolicy.CheckResolved(ctx, dest)
+		} else {
+			err = webhookPolicy.CheckScheme(dest)
+		}
+	}
+	if err != nil {
+		log.Printf("[%s] Webhook not enqueued, destination rejected: %v", commandID, err)
+		return
+	}
+
+	body["command_id"] = commandID
+	body["status"] = status
+	if !cfg.Webhook.OmitOriginalRequest {
+		originalRequest, err := redactor.Object(req)
+		if err != nil {
+			log.Printf("[%s] Failed to redact original request: %v", commandID, err)
+			return
+		}
+		body["original_request"] = originalRequest
+	}
+
+	// Receivers use the sequence to discard stale events; the webhooks service delivers them in order
+	sequence, err := nextWebhookSequence(ctx, commandID)
+	if err != nil {
+		log.Printf("[%s] Failed to assign webhook sequence, sending unordered: %v", commandID, err)
+	}
+	if sequence > 0 {
+		body["sequence"] = sequence
 	}
 
 	payload := WebhookPayload{
-		URL:       url,
-		CommandID: commandID,
-		Status:    "SUCCESS",
-		Body:      webhookBody,
+		URL:         webhookURL,
+		CommandID:   commandID,
+		Status:      status,
+		Sequence:    sequence,
+		ReferenceID: req.ReferenceID,
+		Body:        body,
+		EnqueuedAt:  time.Now(),
 	}
 
 	payloadBytes, err := json.Marshal(payload)
@@ -323,7 +597,7 @@ func enqueueWebhook(url, commandID string, result *CommandResult, req *CommandRe
 
 	task := asynq.NewTask(TypeWebhookDeliver, payloadBytes)
 	info, err := webhookClient.Enqueue(task,
-		asynq.MaxRetry(cfg.Webhook.MaxRetry),
+		asynq.MaxRetry(webhookMaxRetryCeiling),
 		asynq.Queue("webhooks"),
 		asynq.Retention(time.Duration(cfg.Webhook.RetentionHours)*time.Hour),
 	)
@@ -332,50 +606,153 @@ func enqueueWebhook(url, commandID string, result *CommandResult, req *CommandRe
 		return
 	}
 
-	log.Printf("[%s] Webhook enqueued: %s", commandID, info.ID)
+	log.Printf("[%s] Webhook enqueued: %s (%s)", commandID, info.ID, status)
 }
 
-func expandPlaceholders(cmd string, inputs, outputs map[string]string) string {
-	result := cmd
-	for key, path := range inputs {
-		placeholder := "{{" + key + "}}"
-		result = strings.ReplaceAll(result, placeholder, path)
+// finalFailure reports whether err fails the command for good, so no attempt follows it
+func finalFailure(ctx context.Context, err error) bool {
+	if errors.Is(err, asynq.SkipRetry) {
+		return true
+	}
+	// Tasks interrupted by a shutdown are requeued
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return false
 	}
-	for key, path := range outputs {
-		placeholder := "{{" + key + "}}"
-		result = strings.ReplaceAll(result, placeholder, path)
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	return retried >= maxRetry
+}
+
+// nextWebhookSequence returns the next number in a command's webhook events, starting at 1
+func nextWebhookSequence(ctx context.Context, commandID string) (int64, error) {
+	key := webhookSequenceKeyPrefix + commandID
+	pipe := redisClient.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, time.Duration(cfg.Webhook.RetentionHours)*time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
 	}
-	return result
+	return incr.Val(), nil
 }
 
-func parseCommandArgs(cmd string) []string {
-	// Simple parsing that respects quoted strings
-	var args []string
-	var current strings.Builder
-	inQuote := false
-	quoteChar := rune(0)
-
-	for _, r := range cmd {
-		switch {
-		case (r == '"' || r == '\'') && !inQuote:
-			inQuote = true
-			quoteChar = r
-		case r == quoteChar && inQuote:
-			inQuote = false
-			quoteChar = 0
-		case r == ' ' && !inQuote:
-			if current.Len() > 0 {
-				args = append(args, current.String())
-				current.Reset()
+// commandArgs returns the request's ffmpeg commands as argument lists. Command
+// strings are split like a shell would; ffmpeg_args are used as given.
+func commandArgs(req CommandRequest) ([][]string, error) {
+	if len(req.FFmpegArgs) > 0 {
+		return req.FFmpegArgs, nil
+	}
+
+	lines := req.FFmpegCommands
+	if len(lines) == 0 && req.FFmpegCommand != "" {
+		lines = []string{req.FFmpegCommand}
+	}
+	commands := make([][]string, 0, len(lines))
+	for i, line := range lines {
+		args, err := shellwords.Split(line)
+		if err != nil {
+			return nil, fmt.Errorf("command %d: %w", i+1, err)
+		}
+		commands = append(commands, args)
+	}
+	return commands, nil
+}
+
+// runCommands runs the ffmpeg commands in order with placeholders expanded,
+// in jobDir. streamURLs are the inputs ffmpeg reads from the stream proxy.
+func runCommands(ctx context.Context, commandID, jobDir string, commands [][]string, inputPaths, outputPaths, streamURLs map[string]string, inputDurationMS int64, class config.ResourceClass) error {
+	scope := argpolicy.Scope{Dir: jobDir, Streams: slices.Collect(maps.Values(streamURLs))}
+	var relay *system.Relay
+	if len(streamURLs) > 0 {
+		relay = inputDownloader.Streams.Relay()
+	}
+	for i, cmd := range commands {
+		// Replace placeholders with actual paths, within each argument so
+		// paths can't split or merge arguments
+		args := expandPlaceholders(cmd, inputPaths, outputPaths)
+		if err := argPolicy.Check(args, scope); err != nil {
+			return fmt.Errorf("command %d: %w: %w", i+1, err, asynq.SkipRetry)
+		}
+
+		log.Printf("[%s] Running command %d/%d: ffmpeg %s", commandID, i+1, len(commands), shellwords.Join(args))
+
+		args = append([]string{"-y"}, args...) // Always overwrite
+		args = argPolicy.Restrict(args, scope)
+		if class.Threads > 0 {
+			args = argPolicy.LimitThreads(args, class.Threads)
+		}
+		if len(streamURLs) > 0 {
+			args = inputDownloader.Streams.InputArgs(args, streamURLs)
+		}
+
+		// Execute with progress tracking if we have duration info
+		var output []byte
+		var err error
+
+		if inputDurationMS > 0 {
+			runner := &system.FFmpegRunner{
+				Executor:   executor,
+				Dir:        jobDir,
+				Relay:      relay,
+				Limits:     cfg.GetJobLimits(class),
+				DurationMS: inputDurationMS,
+				OnProgress: func(p system.FFmpegProgress) {
+					if p.PercentDone > 0 {
+						log.Printf("[%s] Progress: %.1f%% (speed: %s)", commandID, p.PercentDone, p.Speed)
+					}
+				},
+			}
+			output, err = runner.Run(ctx, args)
+		} else {
+			// Fallback to standard execution
+			output, err = runFFmpeg(ctx, system.ExecSpec{
+				Args:   args,
+				Dir:    jobDir,
+				Relay:  relay,
+				Limits: cfg.GetJobLimits(class),
+			})
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("command cancelled during encoding")
 			}
-		default:
-			current.WriteRune(r)
+			return fmt.Errorf("ffmpeg failed (command %d): %w\n%s", i+1, err, string(output))
 		}
 	}
-	if current.Len() > 0 {
-		args = append(args, current.String())
+	return nil
+}
+
+// runFFmpeg runs ffmpeg with the executor and returns its combined output
+func runFFmpeg(ctx context.Context, spec system.ExecSpec) ([]byte, error) {
+	cmd, release, err := executor.Command(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return cmd.CombinedOutput()
+}
+
+// inputError marks input failures that won't change on retry, such as policy
+// and integrity rejections, so the task isn't retried
+func inputError(err error) error {
+	if errors.Is(err, egress.ErrDenied) || errors.Is(err, inputs.ErrRejected) {
+		return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
 	}
-	return args
+	return err
+}
+
+func expandPlaceholders(args []string, inputs, outputs map[string]string) []string {
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		for key, path := range inputs {
+			arg = strings.ReplaceAll(arg, "{{"+key+"}}", path)
+		}
+		for key, path := range outputs {
+			arg = strings.ReplaceAll(arg, "{{"+key+"}}", path)
+		}
+		expanded[i] = arg
+	}
+	return expanded
 }
 
 func getFileType(ext string) string {
@@ -394,51 +771,39 @@ func getFileType(ext string) string {
 	}
 }
 
-func getMediaDimensions(path string) (int, int) {
-	cmd := exec.Command("ffprobe",
-		"-v", "error",
-		"-select_streams", "v:0",
-		"-show_entries", "stream=width,height",
-		"-of", "csv=p=0:s=x",
-		path,
-	)
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, 0
+// estimateResourceClass picks a resource class for a request without one by
+// its work: minutes of input at the largest video input's resolution, counted
+// in 1080p. Inputs without a known duration get the default class.
+func estimateResourceClass(ctx context.Context, jobDir string, inputPaths map[string]string, inputDurationMS int64) string {
+	if inputDurationMS <= 0 {
+		return cfg.Resources.DefaultClass
 	}
-
-	parts := strings.Split(strings.TrimSpace(string(output)), "x")
-	if len(parts) != 2 {
-		return 0, 0
+	var pixels int
+	for _, path := range inputPaths {
+		if width, height, err := prober.Dimensions(ctx, jobDir, path); err == nil {
+			pixels = max(pixels, width*height)
+		}
 	}
-
-	width, _ := strconv.Atoi(parts[0])
-	height, _ := strconv.Atoi(parts[1])
-	return width, height
+	// Audio counts as a tenth of 1080p video
+	work := max(float64(pixels)/(1920*1080), 0.1) * float64(inputDurationMS) / 60000
+	return cfg.EstimateClass(work)
 }
 
-func downloadFile(ctx context.Context, url, destPath string) error {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// acquireBudget waits until a command of the class fits in the job budget
+func acquireBudget(ctx context.Context, commandID, className string) (func(), error) {
+	waitStart := time.Now()
+	release, err := jobBudget.Acquire(ctx, classWeight(className))
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("command cancelled waiting for resources")
 	}
+	used := jobBudget.Used()
+	log.Printf("[%s] Admitted as %s after waiting %s (budget used: cpu %d, memory %d MB)", commandID, className,
+		time.Since(waitStart).Round(time.Millisecond), used.CPU, used.MemoryMB)
+	return release, nil
+}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("status %d", resp.StatusCode)
-	}
-
-	f, err := os.Create(destPath)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	_, err = io.Copy(f, resp.Body)
-	return err
+// classWeight returns what a command of the class counts against the job budget
+func classWeight(className string) system.Weight {
+	class := cfg.Resources.Classes[className]
+	return system.Weight{CPU: class.WeightCPU, MemoryMB: class.WeightMemoryMB}
 }
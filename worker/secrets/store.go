@@ -0,0 +1,43 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Store resolves secrets by name so they stay out of task payloads and the
+// endpoints file. A secret is read from a file named after it in Dir (e.g.
+// Docker or Kubernetes secrets), falling back to the SECRET_<NAME> environment
+// variable with dashes and dots replaced by underscores.
+type Store struct {
+	Dir string
+}
+
+// Get returns a secret's value with surrounding whitespace removed
+func (s *Store) Get(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid secret name: %q", name)
+	}
+
+	if s.Dir != "" {
+		data, err := os.ReadFile(filepath.Join(s.Dir, name))
+		if err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("read secret %s: %w", name, err)
+		}
+	}
+
+	if val, ok := os.LookupEnv(envName(name)); ok {
+		return strings.TrimSpace(val), nil
+	}
+	return "", fmt.Errorf("secret %s not found", name)
+}
+
+func envName(name string) string {
+	return "SECRET_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
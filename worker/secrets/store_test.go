@@ -0,0 +1,37 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cdn-token"), []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SECRET_CDN_TOKEN", "from-env")
+	t.Setenv("SECRET_ARCHIVE_PASSWORD", " only-env ")
+
+	s := &Store{Dir: dir}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"cdn-token", "from-file"},
+		{"archive.password", "only-env"},
+	}
+	for _, tt := range tests {
+		got, err := s.Get(tt.name)
+		if err != nil || got != tt.want {
+			t.Errorf("Get(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	for _, name := range []string{"", ".", "..", "../cdn-token", "a/b", "missing"} {
+		if _, err := s.Get(name); err == nil {
+			t.Errorf("Get(%q) succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package shellwords
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError reports a command string that can't be split
+type SyntaxError struct {
+	Msg    string
+	Offset int // Byte offset in the command where the problem starts
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Offset)
+}
+
+// Split breaks a command line into arguments the way a POSIX shell does,
+// without running anything: words are separated by unquoted spaces, tabs and
+// newlines; single quotes keep everything literally; double quotes keep
+// everything except backslash escapes of $ ` " \ and newline; an unquoted
+// backslash escapes the next character, and a backslash before a newline
+// joins the lines. Quoted and unquoted parts next to each other form one word,
+// and empty quotes make an empty argument. There is no expansion, globbing or
+// redirection, so $, *, ; and | are ordinary characters.
+func Split(s string) ([]string, error) {
+	var (
+		args    []string
+		word    strings.Builder
+		inWord  bool // Set once a word has started, even if it's empty ("")
+		quote   byte // ' or " while inside quotes
+		quoteAt int
+	)
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch quote {
+		case '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				word.WriteByte(c)
+			}
+			continue
+		case '"':
+			switch {
+			case c == '"':
+				quote = 0
+			case c == '\\' && i+1 < len(s) && strings.IndexByte("$`\"\\\n", s[i+1]) >= 0:
+				i++
+				if s[i] != '\n' {
+					word.WriteByte(s[i])
+				}
+			default:
+				word.WriteByte(c)
+			}
+			continue
+		}
+
+		switch c {
+		case ' ', '\t', '\n':
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case '\'', '"':
+			quote, quoteAt = c, i
+			inWord = true
+		case '\\':
+			if i+1 == len(s) {
+				return nil, &SyntaxError{Msg: "backslash at end of command", Offset: i}
+			}
+			i++
+			if s[i] == '\n' {
+				continue // Line continuation
+			}
+			word.WriteByte(s[i])
+			inWord = true
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+
+	switch quote {
+	case '\'':
+		return nil, &SyntaxError{Msg: "unterminated single quote", Offset: quoteAt}
+	case '"':
+		return nil, &SyntaxError{Msg: "unterminated double quote", Offset: quoteAt}
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
+
+// Join quotes arguments so Split returns them unchanged, for logging commands
+func Join(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = Quote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Quote returns arg as a single shell word, quoting it only if needed
+func Quote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(arg, " \t\n'\"\\") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,68 @@
+package shellwords
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"  -i in.mp4  out.mp4 ", []string{"-i", "in.mp4", "out.mp4"}},
+		{"-vf 'scale=640:-1, fps=1'", []string{"-vf", "scale=640:-1, fps=1"}},
+		{`-metadata "title=A \"B\" \$C \x"`, []string{"-metadata", `title=A "B" $C \x`}},
+		{`a\ b c\\d`, []string{"a b", `c\d`}},
+		{`'it'\''s'`, []string{"it's"}},
+		{`-x "" ''`, []string{"-x", "", ""}},
+		{"-i in.mp4 \\\n out.mp4", []string{"-i", "in.mp4", "out.mp4"}},
+		{"\"a\\\nb\"", []string{"ab"}},
+		{"pre'quoted'post\"x\"", []string{"prequotedpostx"}},
+		{"$HOME *.mp4 ; rm | x", []string{"$HOME", "*.mp4", ";", "rm", "|", "x"}},
+		{"a\tb\nc", []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		got, err := Split(tt.in)
+		if err != nil {
+			t.Errorf("Split(%q) error: %v", tt.in, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Split(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	tests := []struct {
+		in     string
+		offset int
+	}{
+		{"-vf 'scale", 4},
+		{`-metadata "title`, 10},
+		{`out.mp4 \`, 8},
+	}
+	for _, tt := range tests {
+		_, err := Split(tt.in)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Split(%q) error = %v, want a SyntaxError", tt.in, err)
+		} else if syntaxErr.Offset != tt.offset {
+			t.Errorf("Split(%q) offset = %d, want %d", tt.in, syntaxErr.Offset, tt.offset)
+		}
+	}
+}
+
+func TestJoinRoundTrip(t *testing.T) {
+	args := []string{"-i", "my file.mp4", "", "it's", `back\slash`, "tab\there", `"quoted"`, "plain"}
+	got, err := Split(Join(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, args) {
+		t.Errorf("Split(Join(%q)) = %q", args, got)
+	}
+}
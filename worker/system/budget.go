@@ -0,0 +1,128 @@
+package system
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// Weight is what a job counts against a Budget
+type Weight struct {
+	CPU      int64 // CPU units
+	MemoryMB int64
+}
+
+// Budget admits jobs while their total weight fits, like a weighted semaphore
+// with two dimensions. Jobs are admitted in arrival order, so a heavy job
+// isn't starved by a stream of light ones.
+type Budget struct {
+	capacity Weight // 0 = that dimension is unlimited
+
+	mu      sync.Mutex
+	used    Weight
+	waiters list.List // *budgetWaiter, in arrival order
+}
+
+type budgetWaiter struct {
+	weight Weight
+	ready  chan struct{}
+}
+
+// NewBudget creates a budget with the given capacity
+func NewBudget(capacity Weight) *Budget {
+	return &Budget{capacity: capacity}
+}
+
+// Acquire waits until w fits in the budget, returning a function that gives
+// it back. Weights larger than the capacity are lowered to it, so such jobs
+// run alone instead of never.
+func (b *Budget) Acquire(ctx context.Context, w Weight) (release func(), err error) {
+	w = b.clamp(w)
+	release = sync.OnceFunc(func() { b.release(w) })
+
+	b.mu.Lock()
+	if b.waiters.Len() == 0 && b.fits(w) {
+		b.used.CPU += w.CPU
+		b.used.MemoryMB += w.MemoryMB
+		b.mu.Unlock()
+		return release, nil
+	}
+	waiter := &budgetWaiter{weight: w, ready: make(chan struct{})}
+	elem := b.waiters.PushBack(waiter)
+	b.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		return release, nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		select {
+		case <-waiter.ready:
+			// Admitted while being cancelled
+			b.mu.Unlock()
+			release()
+		default:
+			front := b.waiters.Front() == elem
+			b.waiters.Remove(elem)
+			if front {
+				// Jobs behind this one may fit now
+				b.admit()
+			}
+			b.mu.Unlock()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// Used returns the weight of the jobs admitted
+func (b *Budget) Used() Weight {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used
+}
+
+// Capacity returns the budget's capacity
+func (b *Budget) Capacity() Weight {
+	return b.capacity
+}
+
+func (b *Budget) release(w Weight) {
+	b.mu.Lock()
+	b.used.CPU -= w.CPU
+	b.used.MemoryMB -= w.MemoryMB
+	b.admit()
+	b.mu.Unlock()
+}
+
+// admit lets waiting jobs in, in order, while they fit. Called with mu held.
+func (b *Budget) admit() {
+	for {
+		front := b.waiters.Front()
+		if front == nil {
+			return
+		}
+		waiter := front.Value.(*budgetWaiter)
+		if !b.fits(waiter.weight) {
+			return
+		}
+		b.used.CPU += waiter.weight.CPU
+		b.used.MemoryMB += waiter.weight.MemoryMB
+		b.waiters.Remove(front)
+		close(waiter.ready)
+	}
+}
+
+func (b *Budget) fits(w Weight) bool {
+	return (b.capacity.CPU == 0 || b.used.CPU+w.CPU <= b.capacity.CPU) &&
+		(b.capacity.MemoryMB == 0 || b.used.MemoryMB+w.MemoryMB <= b.capacity.MemoryMB)
+}
+
+func (b *Budget) clamp(w Weight) Weight {
+	if b.capacity.CPU > 0 {
+		w.CPU = min(w.CPU, b.capacity.CPU)
+	}
+	if b.capacity.MemoryMB > 0 {
+		w.MemoryMB = min(w.MemoryMB, b.capacity.MemoryMB)
+	}
+	return w
+}
@@ -0,0 +1,123 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// acquireAsync starts an Acquire and returns a channel that receives its
+// release function once admitted
+func acquireAsync(ctx context.Context, b *Budget, w Weight) (<-chan func(), <-chan error) {
+	admitted := make(chan func(), 1)
+	failed := make(chan error, 1)
+	go func() {
+		release, err := b.Acquire(ctx, w)
+		if err != nil {
+			failed <- err
+			return
+		}
+		admitted <- release
+	}()
+	return admitted, failed
+}
+
+// waitQueued waits until n jobs are waiting on b
+func waitQueued(t *testing.T, b *Budget, n int) {
+	t.Helper()
+	for range 1000 {
+		b.mu.Lock()
+		queued := b.waiters.Len()
+		b.mu.Unlock()
+		if queued == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%d jobs never queued", n)
+}
+
+func TestBudgetBothDimensions(t *testing.T) {
+	b := NewBudget(Weight{CPU: 8, MemoryMB: 1024})
+	ctx := context.Background()
+
+	release, err := b.Acquire(ctx, Weight{CPU: 2, MemoryMB: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// CPU fits, memory doesn't
+	admitted, _ := acquireAsync(ctx, b, Weight{CPU: 2, MemoryMB: 100})
+	waitQueued(t, b, 1)
+	if got := b.Used(); got != (Weight{CPU: 2, MemoryMB: 1000}) {
+		t.Errorf("Used = %+v", got)
+	}
+
+	release()
+	release() // Releasing twice gives the weight back once
+	second := <-admitted
+	if got := b.Used(); got != (Weight{CPU: 2, MemoryMB: 100}) {
+		t.Errorf("Used after release = %+v", got)
+	}
+	second()
+	if got := b.Used(); got != (Weight{}) {
+		t.Errorf("Used when idle = %+v", got)
+	}
+}
+
+func TestBudgetClampsWeight(t *testing.T) {
+	b := NewBudget(Weight{CPU: 4, MemoryMB: 0})
+	release, err := b.Acquire(context.Background(), Weight{CPU: 16, MemoryMB: 1 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Lowered to the capacity; the unlimited dimension is left alone
+	if got := b.Used(); got != (Weight{CPU: 4, MemoryMB: 1 << 20}) {
+		t.Errorf("Used = %+v", got)
+	}
+	release()
+}
+
+func TestBudgetFIFO(t *testing.T) {
+	b := NewBudget(Weight{CPU: 4})
+	ctx := context.Background()
+
+	first, _ := b.Acquire(ctx, Weight{CPU: 3})
+	heavy, _ := acquireAsync(ctx, b, Weight{CPU: 4})
+	waitQueued(t, b, 1)
+	// Fits next to the first job, but must wait behind the heavy one
+	light, _ := acquireAsync(ctx, b, Weight{CPU: 1})
+	waitQueued(t, b, 2)
+
+	first()
+	releaseHeavy := <-heavy
+	select {
+	case <-light:
+		t.Fatal("light job admitted next to the heavy one")
+	default:
+	}
+	releaseHeavy()
+	(<-light)()
+}
+
+func TestBudgetCancel(t *testing.T) {
+	b := NewBudget(Weight{CPU: 4})
+	release, _ := b.Acquire(context.Background(), Weight{CPU: 3})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, failed := acquireAsync(ctx, b, Weight{CPU: 4})
+	waitQueued(t, b, 1)
+	light, _ := acquireAsync(context.Background(), b, Weight{CPU: 1})
+	waitQueued(t, b, 2)
+
+	// Cancelling the job at the front lets the one behind it in
+	cancel()
+	if err := <-failed; !errors.Is(err, context.Canceled) {
+		t.Errorf("Acquire = %v, want context.Canceled", err)
+	}
+	(<-light)()
+	release()
+	if got := b.Used(); got != (Weight{}) {
+		t.Errorf("Used = %+v, want none", got)
+	}
+}
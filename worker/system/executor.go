@@ -0,0 +1,176 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// DefaultExecEnv lists the environment variables ffmpeg and ffprobe get by
+// default: enough for locale, fonts and hardware acceleration, but none of the
+// worker's credentials
+const DefaultExecEnv = "PATH,TZ,LANG,LC_ALL,FONTCONFIG_FILE,FONTCONFIG_PATH,LIBVA_DRIVER_NAME,LIBVA_DRIVERS_PATH," +
+	"CUDA_VISIBLE_DEVICES,NVIDIA_VISIBLE_DEVICES,NVIDIA_DRIVER_CAPABILITIES"
+
+var execEnvNames = splitList(DefaultExecEnv)
+
+// SetExecEnv sets which of the worker's environment variables ffmpeg and
+// ffprobe processes are given
+func SetExecEnv(names string) {
+	execEnvNames = splitList(names)
+}
+
+// ExecEnv returns the environment for ffmpeg and ffprobe processes
+func ExecEnv() []string {
+	var env []string
+	for _, name := range execEnvNames {
+		if val, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+val)
+		}
+	}
+	return env
+}
+
+// Limits bound the resources of one ffmpeg run. Zero values are unlimited.
+type Limits struct {
+	MemoryBytes int64   // cgroup memory.max, or the address space limit without a cgroup
+	CPUSeconds  int64   // CPU time, after which ffmpeg is killed
+	FileBytes   int64   // Largest file ffmpeg may write
+	OpenFiles   int64   // Open file descriptors
+	Processes   int64   // cgroup pids.max: processes and threads
+	CPUs        float64 // cgroup cpu.max, in cores
+	Nice        int     // CPU scheduling niceness, 1 to 19
+	IOPriority  int     // Best-effort I/O priority, 1 (high) to 7 (low); 0 follows Nice
+}
+
+// ExecSpec describes one ffmpeg or ffprobe run
+type ExecSpec struct {
+	Program  string   // "ffmpeg" or "ffprobe"; empty is ffmpeg
+	Args     []string // Program arguments
+	Dir      string   // Job directory: the working directory, and the only place a sandbox can write to
+	ReadOnly bool     // Keep Dir read-only too, for runs that only read files, such as probes
+	Relay    *Relay   // Makes the stream proxy reachable from a sandbox, which has no other network
+	Limits   Limits
+}
+
+// Relay forwards connections to a loopback address to a unix socket. A
+// sandbox's network namespace has nothing but its own loopback interface, so
+// this is how ffmpeg reaches the stream proxy from inside one.
+type Relay struct {
+	Addr   string // Loopback address ffmpeg connects to, e.g. "127.0.0.1:8123"
+	Socket string // Unix socket the connections are forwarded to
+}
+
+// program returns the name of the program to run
+func (s ExecSpec) program() (string, error) {
+	switch s.Program {
+	case "", "ffmpeg":
+		return "ffmpeg", nil
+	case "ffprobe":
+		return "ffprobe", nil
+	default:
+		return "", fmt.Errorf("unknown program: %s", s.Program)
+	}
+}
+
+// Executor starts ffmpeg and ffprobe processes
+type Executor interface {
+	// Name returns the executor name for logging
+	Name() string
+	// Command returns an unstarted command for spec. Call release once it
+	// has finished, or if it isn't started.
+	Command(ctx context.Context, spec ExecSpec) (cmd *exec.Cmd, release func(), err error)
+	// Unenforced returns the names of the limits set in limits that this
+	// executor can't apply
+	Unenforced(limits Limits) []string
+}
+
+// NewExecutor creates the executor named by kind: "plain" runs ffmpeg as a
+// child of the worker, "sandbox" isolates it (see SandboxOptions)
+func NewExecutor(kind string, opts SandboxOptions) (Executor, error) {
+	switch kind {
+	case "", "plain":
+		return PlainExecutor{}, nil
+	case "sandbox":
+		return NewSandboxExecutor(opts)
+	default:
+		return nil, fmt.Errorf("unknown executor: %s", kind)
+	}
+}
+
+// PlainExecutor runs ffmpeg and ffprobe as ordinary child processes, with
+// only the environment from ExecEnv. On Linux, the rlimits and priority in
+// ExecSpec.Limits are applied by starting the worker binary, which sets them
+// and execs the program; limits that need a cgroup aren't applied.
+type PlainExecutor struct{}
+
+func (PlainExecutor) Name() string { return "plain" }
+
+func (PlainExecutor) Command(ctx context.Context, spec ExecSpec) (*exec.Cmd, func(), error) {
+	program, err := spec.program()
+	if err != nil {
+		return nil, nil, err
+	}
+	cmd := exec.CommandContext(ctx, program, spec.Args...)
+	if spec.Limits != (Limits{}) {
+		if cmd, err = limitedCommand(ctx, program, spec.Args, spec.Limits); err != nil {
+			return nil, nil, err
+		}
+	}
+	cmd.Dir = spec.Dir
+	cmd.Env = ExecEnv()
+	return cmd, func() {}, nil
+}
+
+// limitNames returns the names of the limits set, or with cgroupOnly only of
+// those that need a cgroup
+func limitNames(limits Limits, cgroupOnly bool) []string {
+	var names []string
+	add := func(set bool, name string, cgroup bool) {
+		if set && (cgroup || !cgroupOnly) {
+			names = append(names, name)
+		}
+	}
+	add(limits.MemoryBytes > 0, "memory", false)
+	add(limits.CPUSeconds > 0, "CPU time", false)
+	add(limits.FileBytes > 0, "file size", false)
+	add(limits.OpenFiles > 0, "open files", false)
+	add(limits.Processes > 0, "processes", true)
+	add(limits.CPUs > 0, "CPUs", true)
+	add(limits.Nice > 0, "nice", false)
+	add(limits.IOPriority > 0, "I/O priority", false)
+	return names
+}
+
+// SandboxInitArg is the first argument of the worker binary when it runs as
+// the sandbox's init: the worker's main must call SandboxInit in that case.
+const SandboxInitArg = "sandbox-init"
+
+// SandboxOptions configure the sandbox executor. Each run gets new mount, PID,
+// IPC, UTS and network namespaces; the network has only loopback, where
+// ExecSpec.Relay is served if set. Within them the filesystem is read-only except for
+// the job directory, other jobs and HidePaths are replaced with empty
+// directories, and ffmpeg runs as UID and GID with rlimits applied. It needs
+// the worker to run as root with CAP_SYS_ADMIN.
+type SandboxOptions struct {
+	UID, GID  int      // Unprivileged user ffmpeg runs as
+	WorkDir   string   // Parent of the job directories, hidden except for the job's own
+	HidePaths []string // Directories and files that are replaced with empty ones, e.g. secrets
+
+	// CgroupParent is a cgroup v2 directory delegated to the worker. Each
+	// run gets a cgroup below it with the memory, CPU and process limits.
+	// Empty disables cgroups; rlimits still apply.
+	CgroupParent string
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
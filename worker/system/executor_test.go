@@ -0,0 +1,38 @@
+package system
+
+import (
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestLimitNames(t *testing.T) {
+	all := Limits{MemoryBytes: 1 << 30, CPUSeconds: 60, FileBytes: 1 << 30, OpenFiles: 256, Processes: 64, CPUs: 2, Nice: 10, IOPriority: 4}
+	tests := []struct {
+		limits     Limits
+		cgroupOnly bool
+		want       []string
+	}{
+		{Limits{}, false, nil},
+		{all, false, []string{"memory", "CPU time", "file size", "open files", "processes", "CPUs", "nice", "I/O priority"}},
+		{all, true, []string{"processes", "CPUs"}},
+		{Limits{MemoryBytes: 1 << 30, Nice: 10}, true, nil},
+		{Limits{CPUs: 0.5}, true, []string{"CPUs"}},
+	}
+	for _, tt := range tests {
+		if got := limitNames(tt.limits, tt.cgroupOnly); !slices.Equal(got, tt.want) {
+			t.Errorf("limitNames(%+v, %v) = %q, want %q", tt.limits, tt.cgroupOnly, got, tt.want)
+		}
+	}
+}
+
+func TestPlainUnenforced(t *testing.T) {
+	limits := Limits{MemoryBytes: 1 << 30, Processes: 64, Nice: 10}
+	want := []string{"processes"}
+	if runtime.GOOS != "linux" {
+		want = []string{"memory", "processes", "nice"}
+	}
+	if got := (PlainExecutor{}).Unenforced(limits); !slices.Equal(got, want) {
+		t.Errorf("Unenforced = %q, want %q", got, want)
+	}
+}
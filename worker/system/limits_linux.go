@@ -0,0 +1,58 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"syscall"
+)
+
+// limitArg follows SandboxInitArg when the worker binary applies the plain executor's limits
+const limitArg = "limit"
+
+func (PlainExecutor) Unenforced(limits Limits) []string {
+	return limitNames(limits, true)
+}
+
+// limitedCommand returns a command that runs program through the worker
+// binary, which applies the rlimits and priority and then execs it. Without
+// a cgroup, the memory limit applies to the address space.
+func limitedCommand(ctx context.Context, program string, args []string, limits Limits) (*exec.Cmd, error) {
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(sandboxSpec{Program: path, Limits: limits, AddressSpace: true})
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, "/proc/self/exe", append([]string{SandboxInitArg, limitArg, string(data), "--"}, args...)...)
+	cmd.Args[0] = program
+	return cmd, nil
+}
+
+// runLimited applies the limits in its spec argument and execs the program
+// with the arguments after "--"
+func runLimited(args []string) error {
+	if len(args) < 2 || args[1] != "--" {
+		return fmt.Errorf("invalid arguments")
+	}
+	var spec sandboxSpec
+	if err := json.Unmarshal([]byte(args[0]), &spec); err != nil {
+		return err
+	}
+	// Nice and I/O priority are set per thread, so on the one that execs
+	runtime.LockOSThread()
+
+	if err := setLimits(spec.Limits, spec.AddressSpace); err != nil {
+		return err
+	}
+	if err := setPriority(spec.Limits); err != nil {
+		return err
+	}
+	return syscall.Exec(spec.Program, append([]string{filepath.Base(spec.Program)}, args[2:]...), os.Environ())
+}
@@ -0,0 +1,45 @@
+package system
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSandboxUnenforced(t *testing.T) {
+	limits := Limits{MemoryBytes: 1 << 30, Processes: 64, CPUs: 2}
+	if got := (&SandboxExecutor{}).Unenforced(limits); !slices.Equal(got, []string{"processes", "CPUs"}) {
+		t.Errorf("without a cgroup: Unenforced = %q", got)
+	}
+	s := &SandboxExecutor{opts: SandboxOptions{CgroupParent: "/sys/fs/cgroup/ffmpeg"}}
+	if got := s.Unenforced(limits); got != nil {
+		t.Errorf("with a cgroup: Unenforced = %q, want none", got)
+	}
+}
+
+// TestLimitedCommand runs a shell through the worker binary, here the test
+// binary (see TestMain), and checks the limits it got
+func TestLimitedCommand(t *testing.T) {
+	limits := Limits{
+		MemoryBytes: 1 << 30,
+		CPUSeconds:  60,
+		FileBytes:   1 << 20,
+		OpenFiles:   64,
+		Nice:        5,
+	}
+	// Sizes are in kilobytes for -v and 512-byte blocks for -f
+	script := "ulimit -v; ulimit -t; ulimit -f; ulimit -n; cut -d' ' -f19 /proc/self/stat"
+	cmd, err := limitedCommand(context.Background(), "sh", []string{"-c", script}, limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+	want := "1048576\n60\n2048\n64\n5"
+	if got := strings.TrimSpace(string(output)); got != want {
+		t.Errorf("limits = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,197 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MediaInfo is what ffprobe found in a file
+type MediaInfo struct {
+	FormatName string   // Demuxer name(s), e.g. "mov,mp4,m4a,3gp,3g2,mj2"
+	Streams    []string // Codec type of each stream, e.g. "video", "audio"
+	stills     int      // Video streams that are attached pictures (cover art)
+}
+
+// Prober runs ffprobe through an executor, so the untrusted files it parses
+// get the same isolation and limits as ffmpeg gives them
+type Prober struct {
+	Executor Executor
+	Limits   Limits
+	Relay    *Relay        // Reaches the stream proxy, for probing streamed inputs
+	Timeout  time.Duration // Longest a probe may run (0 = no limit)
+}
+
+// run runs ffprobe on target, a file or a streamed input's URL, with dir as
+// its working directory and returns what it printed. Only files in dir can be
+// read, and none can be changed. Files may only be opened with the file
+// protocol, and streamed inputs only through the stream proxy, so a playlist
+// can't make ffprobe fetch anything else.
+func (p *Prober) run(ctx context.Context, dir, target string, args ...string) ([]byte, error) {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	// Relative paths would resolve against the worker's directory, not dir
+	stream := strings.Contains(target, "://")
+	if !stream {
+		if target, err = filepath.Abs(target); err != nil {
+			return nil, err
+		}
+	}
+	restrict := []string{"-protocol_whitelist", "file"}
+	if stream {
+		if p.Relay == nil {
+			return nil, fmt.Errorf("ffprobe: no stream proxy for %s", target)
+		}
+		restrict = []string{"-protocol_whitelist", "http,tcp", "-http_proxy", "http://" + p.Relay.Addr}
+	}
+	spec := ExecSpec{
+		Program:  "ffprobe",
+		Args:     slices.Concat([]string{"-v", "error"}, restrict, args, []string{target}),
+		Dir:      dir,
+		ReadOnly: true,
+		Limits:   p.Limits,
+	}
+	if stream {
+		spec.Relay = p.Relay
+	}
+	cmd, release, err := p.Executor.Command(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	output, err := cmd.Output()
+	if err != nil {
+		if p.Timeout > 0 && ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("ffprobe: timed out after %s", p.Timeout)
+		}
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("ffprobe: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("ffprobe: %w", err)
+	}
+	return output, nil
+}
+
+// Media probes a file or streamed input in dir. Files ffprobe can't read at
+// all, such as HTML error pages, return an error.
+func (p *Prober) Media(ctx context.Context, dir, target string) (*MediaInfo, error) {
+	output, err := p.run(ctx, dir, target,
+		"-show_entries", "format=format_name:stream=codec_type:stream_disposition=attached_pic",
+		"-of", "json",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var probe struct {
+		Format struct {
+			FormatName string `json:"format_name"`
+		} `json:"format"`
+		Streams []struct {
+			CodecType   string `json:"codec_type"`
+			Disposition struct {
+				AttachedPic int `json:"attached_pic"`
+			} `json:"disposition"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("parse ffprobe output: %w", err)
+	}
+
+	info := &MediaInfo{FormatName: probe.Format.FormatName}
+	for _, s := range probe.Streams {
+		info.Streams = append(info.Streams, s.CodecType)
+		if s.CodecType == "video" && s.Disposition.AttachedPic == 1 {
+			info.stills++
+		}
+	}
+	return info, nil
+}
+
+// Duration returns the duration of a file or streamed input in dir, in milliseconds
+func (p *Prober) Duration(ctx context.Context, dir, target string) (int64, error) {
+	output, err := p.run(ctx, dir, target,
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	// Parse duration (in seconds with decimal)
+	duration, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(duration * 1000), nil
+}
+
+// Dimensions returns the width and height of the first video stream of a file in dir
+func (p *Prober) Dimensions(ctx context.Context, dir, target string) (int, int, error) {
+	output, err := p.run(ctx, dir, target,
+		"-select_streams", "v:0",
+		"-show_entries", "stream=width,height",
+		"-of", "csv=p=0:s=x",
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	parts := strings.Split(strings.TrimSpace(string(output)), "x")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("ffprobe: no video stream")
+	}
+	width, _ := strconv.Atoi(parts[0])
+	height, _ := strconv.Atoi(parts[1])
+	return width, height, nil
+}
+
+// IsImage reports whether the file is a still image
+func (m *MediaInfo) IsImage() bool {
+	return m.count("video") > 0 && isImageFormat(m.FormatName)
+}
+
+// HasVideo reports whether the file has moving video, not counting still images or cover art
+func (m *MediaInfo) HasVideo() bool {
+	return m.count("video") > m.stills && !isImageFormat(m.FormatName)
+}
+
+// HasAudio reports whether the file has an audio stream
+func (m *MediaInfo) HasAudio() bool {
+	return m.count("audio") > 0
+}
+
+func (m *MediaInfo) count(codecType string) int {
+	n := 0
+	for _, s := range m.Streams {
+		if s == codecType {
+			n++
+		}
+	}
+	return n
+}
+
+// isImageFormat reports whether a demuxer reads images (image2, gif, png_pipe, jpeg_pipe, ...)
+func isImageFormat(formatName string) bool {
+	for _, name := range strings.Split(formatName, ",") {
+		if name == "image2" || name == "gif" || strings.HasSuffix(name, "_pipe") {
+			return true
+		}
+	}
+	return false
+}
@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"context"
 	"io"
-	"os/exec"
 	"strconv"
 	"strings"
 	"time"
@@ -30,6 +29,10 @@ type ProgressCallback func(progress FFmpegProgress)
 
 // FFmpegRunner executes FFmpeg commands with progress tracking
 type FFmpegRunner struct {
+	Executor   Executor         // Starts ffmpeg
+	Dir        string           // Job directory ffmpeg runs in
+	Relay      *Relay           // Reaches the stream proxy from a sandbox (see ExecSpec)
+	Limits     Limits           // Resource limits, applied by the executor
 	DurationMS int64            // Total duration of input in milliseconds (for percentage calculation)
 	OnProgress ProgressCallback // Called with progress updates
 }
@@ -43,7 +46,11 @@ func (r *FFmpegRunner) Run(ctx context.Context, args []string) ([]byte, error) {
 	// Add progress flag to args
 	fullArgs := append([]string{"-y", "-progress", "pipe:1"}, args...)
 
-	cmd := exec.CommandContext(ctx, "ffmpeg", fullArgs...)
+	cmd, release, err := r.Executor.Command(ctx, ExecSpec{Args: fullArgs, Dir: r.Dir, Relay: r.Relay, Limits: r.Limits})
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 	cmd.Stdout = progressWriter
 
 	// Capture stderr for error messages
@@ -131,27 +138,3 @@ func (r *FFmpegRunner) parseProgress(reader io.Reader) {
 		}
 	}
 }
-
-// GetMediaDuration returns the duration of a media file in milliseconds
-func GetMediaDuration(path string) (int64, error) {
-	cmd := exec.Command("ffprobe",
-		"-v", "error",
-		"-show_entries", "format=duration",
-		"-of", "default=noprint_wrappers=1:nokey=1",
-		path,
-	)
-
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, err
-	}
-
-	// Parse duration (in seconds with decimal)
-	durationStr := strings.TrimSpace(string(output))
-	duration, err := strconv.ParseFloat(durationStr, 64)
-	if err != nil {
-		return 0, err
-	}
-
-	return int64(duration * 1000), nil
-}
@@ -0,0 +1,126 @@
+package system
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+const oPath = 0x200000 // O_PATH, missing from package syscall
+
+// relayArg follows SandboxInitArg when the worker binary runs as a sandbox's relay
+const relayArg = "relay"
+
+// relayFiles are handed to the relay process: a listener on the relay's
+// address in the sandbox's network namespace, and the unix socket to forward
+// connections to, opened by path only
+type relayFiles struct {
+	listener *os.File
+	socket   *os.File
+}
+
+// openRelay brings up the loopback interface, listens on the relay's address
+// and opens its socket. The init calls it before hiding paths.
+func openRelay(relay *Relay) (*relayFiles, error) {
+	if err := bringUpLoopback(); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", relay.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("relay: %w", err)
+	}
+	defer ln.Close()
+	listener, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		return nil, fmt.Errorf("relay: %w", err)
+	}
+	fd, err := syscall.Open(relay.Socket, oPath|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("open relay socket: %w", err)
+	}
+	return &relayFiles{listener: listener, socket: os.NewFile(uintptr(fd), relay.Socket)}, nil
+}
+
+// start runs the relay as the sandbox user. It is left running when the init
+// execs the program, and is killed with the rest of the sandbox when the
+// program exits.
+func (r *relayFiles) start(uid, gid int) error {
+	defer r.listener.Close()
+	defer r.socket.Close()
+
+	cmd := exec.Command("/proc/self/exe", SandboxInitArg, relayArg)
+	cmd.Args[0] = "relay"
+	cmd.Dir = "/"
+	cmd.ExtraFiles = []*os.File{r.listener, r.socket}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Credential: &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)},
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start relay: %w", err)
+	}
+	return nil
+}
+
+// runRelay forwards each connection accepted on descriptor 3 to the socket
+// open at descriptor 4
+func runRelay() error {
+	ln, err := net.FileListener(os.NewFile(3, "listener"))
+	if err != nil {
+		return err
+	}
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go relayConn(conn, "/proc/self/fd/4")
+	}
+}
+
+func relayConn(conn net.Conn, socket string) {
+	defer conn.Close()
+	upstream, err := net.Dial("unix", socket)
+	if err != nil {
+		return
+	}
+	defer upstream.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		io.Copy(upstream, conn)
+		upstream.(*net.UnixConn).CloseWrite()
+	}()
+	io.Copy(conn, upstream)
+	conn.(*net.TCPConn).CloseWrite()
+	<-done
+}
+
+// bringUpLoopback sets lo up, the only interface in a new network namespace
+func bringUpLoopback() error {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("bring up loopback: %w", err)
+	}
+	defer syscall.Close(fd)
+
+	// struct ifreq with ifr_flags
+	var req struct {
+		name  [syscall.IFNAMSIZ]byte
+		flags uint16
+		_     [22]byte
+	}
+	copy(req.name[:], "lo")
+	for _, op := range []uintptr{syscall.SIOCGIFFLAGS, syscall.SIOCSIFFLAGS} {
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), op, uintptr(unsafe.Pointer(&req))); errno != 0 {
+			return fmt.Errorf("bring up loopback: %w", errno)
+		}
+		req.flags |= syscall.IFF_UP | syscall.IFF_RUNNING
+	}
+	return nil
+}
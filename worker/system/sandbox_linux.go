@@ -0,0 +1,437 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const prSetNoNewPrivs = 38 // PR_SET_NO_NEW_PRIVS, missing from package syscall
+
+// ioprio_set arguments, missing from package syscall
+const (
+	ioprioWhoProcess = 1
+	ioprioClassBE    = 2
+	ioprioClassShift = 13
+)
+
+// SandboxExecutor runs ffmpeg and ffprobe in namespaces, through the worker
+// binary itself: it is started as the init of the new namespaces, sets up the
+// mounts, limits and user, then execs the program.
+type SandboxExecutor struct {
+	opts     SandboxOptions
+	programs map[string]string // Program name → path
+}
+
+// sandboxSpec is what the init needs to know, passed as its second argument
+type sandboxSpec struct {
+	Program      string
+	Dir          string
+	ReadOnly     bool
+	Relay        *Relay
+	WorkDir      string
+	HidePaths    []string
+	UID, GID     int
+	Limits       Limits
+	AddressSpace bool // Apply Limits.MemoryBytes as an address space limit, for lack of a cgroup
+}
+
+// NewSandboxExecutor creates a sandbox executor and checks that it can run ffmpeg
+func NewSandboxExecutor(opts SandboxOptions) (*SandboxExecutor, error) {
+	programs := make(map[string]string)
+	for _, name := range []string{"ffmpeg", "ffprobe"} {
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return nil, err
+		}
+		programs[name] = path
+	}
+	if opts.UID <= 0 || opts.GID <= 0 {
+		return nil, fmt.Errorf("sandbox user must not be root")
+	}
+	if opts.CgroupParent != "" {
+		if _, err := os.Stat(filepath.Join(opts.CgroupParent, "cgroup.controllers")); err != nil {
+			return nil, fmt.Errorf("sandbox cgroup parent is not a cgroup v2 directory: %w", err)
+		}
+		// Controllers may already be enabled, or not be possible to enable here
+		os.WriteFile(filepath.Join(opts.CgroupParent, "cgroup.subtree_control"), []byte("+cpu +memory +pids"), 0)
+	}
+	s := &SandboxExecutor{opts: opts, programs: programs}
+
+	dir, err := os.MkdirTemp(opts.WorkDir, "sandbox-check-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cmd, release, err := s.Command(ctx, ExecSpec{Args: []string{"-hide_banner", "-version"}, Dir: dir})
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("sandbox check failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return s, nil
+}
+
+func (s *SandboxExecutor) Name() string { return "sandbox" }
+
+func (s *SandboxExecutor) Unenforced(limits Limits) []string {
+	if s.opts.CgroupParent != "" {
+		return nil
+	}
+	return limitNames(limits, true)
+}
+
+func (s *SandboxExecutor) Command(ctx context.Context, spec ExecSpec) (*exec.Cmd, func(), error) {
+	program, err := spec.program()
+	if err != nil {
+		return nil, nil, err
+	}
+	init := sandboxSpec{
+		Program:   s.programs[program],
+		Dir:       spec.Dir,
+		ReadOnly:  spec.ReadOnly,
+		Relay:     spec.Relay,
+		WorkDir:   s.opts.WorkDir,
+		HidePaths: s.opts.HidePaths,
+		UID:       s.opts.UID,
+		GID:       s.opts.GID,
+		Limits:    spec.Limits,
+	}
+
+	attr := &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWNS | syscall.CLONE_NEWPID | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS | syscall.CLONE_NEWNET,
+		Pdeathsig:  syscall.SIGKILL,
+	}
+	release := func() {}
+	if s.opts.CgroupParent != "" {
+		dir, fd, err := s.cgroup(spec.Limits)
+		if err != nil {
+			return nil, nil, fmt.Errorf("create cgroup: %w", err)
+		}
+		attr.UseCgroupFD, attr.CgroupFD = true, fd
+		release = func() {
+			syscall.Close(fd)
+			removeCgroup(dir)
+		}
+	} else {
+		init.AddressSpace = true
+	}
+
+	data, err := json.Marshal(init)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	cmd := exec.CommandContext(ctx, "/proc/self/exe", append([]string{SandboxInitArg, string(data), "--"}, spec.Args...)...)
+	cmd.Args[0] = program
+	cmd.Dir = spec.Dir
+	cmd.Env = ExecEnv()
+	cmd.SysProcAttr = attr
+	return cmd, release, nil
+}
+
+// cgroup creates a cgroup for one run with the limits applied, returning its
+// directory and an open descriptor to start the process in it with
+func (s *SandboxExecutor) cgroup(limits Limits) (string, int, error) {
+	dir, err := os.MkdirTemp(s.opts.CgroupParent, "ffmpeg-")
+	if err != nil {
+		return "", 0, err
+	}
+	settings := map[string]string{}
+	if limits.MemoryBytes > 0 {
+		settings["memory.max"] = strconv.FormatInt(limits.MemoryBytes, 10)
+		settings["memory.swap.max"] = "0"
+	}
+	if limits.CPUs > 0 {
+		settings["cpu.max"] = fmt.Sprintf("%d 100000", int64(limits.CPUs*100000))
+	}
+	if limits.Processes > 0 {
+		settings["pids.max"] = strconv.FormatInt(limits.Processes, 10)
+	}
+	for name, value := range settings {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0); err != nil && name != "memory.swap.max" {
+			removeCgroup(dir)
+			return "", 0, fmt.Errorf("set %s: %w", name, err)
+		}
+	}
+	fd, err := syscall.Open(dir, syscall.O_RDONLY|syscall.O_DIRECTORY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		removeCgroup(dir)
+		return "", 0, err
+	}
+	return dir, fd, nil
+}
+
+// removeCgroup removes a run's cgroup, waiting briefly for the kernel to
+// notice its processes are gone
+func removeCgroup(dir string) {
+	var err error
+	for range 10 {
+		if err = syscall.Rmdir(dir); err == nil || errors.Is(err, syscall.ENOENT) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	log.Printf("Failed to remove cgroup %s: %v", dir, err)
+}
+
+// SandboxInit runs in the worker binary started by SandboxExecutor as pid 1
+// of the new namespaces, with the arguments after SandboxInitArg. It prepares
+// the sandbox and execs the program, or exits with status 126 if it can't.
+// The init also starts the binary as the sandbox's relay, with relayArg, and
+// the plain executor starts it to apply limits, with limitArg.
+func SandboxInit(args []string) {
+	var err error
+	switch {
+	case len(args) > 0 && args[0] == relayArg:
+		err = runRelay()
+	case len(args) > 0 && args[0] == limitArg:
+		err = runLimited(args[1:])
+	default:
+		err = sandboxInit(args)
+	}
+	fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+	os.Exit(126)
+}
+
+func sandboxInit(args []string) error {
+	if len(args) < 2 || args[1] != "--" {
+		return fmt.Errorf("invalid arguments")
+	}
+	var spec sandboxSpec
+	if err := json.Unmarshal([]byte(args[0]), &spec); err != nil {
+		return err
+	}
+	// Nice and I/O priority are set per thread, so on the one that execs
+	runtime.LockOSThread()
+
+	// Nothing mounted from here on may reach the worker's namespace
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mounts private: %w", err)
+	}
+	if err := chownDirs(spec.Dir, spec.UID, spec.GID); err != nil {
+		return err
+	}
+
+	// Hide every job directory and the other paths, then mount this job's
+	// directory back by descriptor, as its path is hidden by then
+	jobFD, err := syscall.Open(spec.Dir, syscall.O_RDONLY|syscall.O_DIRECTORY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("open job directory: %w", err)
+	}
+	// The relay's socket may be hidden too, so it's reached by descriptor
+	var relay *relayFiles
+	if spec.Relay != nil {
+		if relay, err = openRelay(spec.Relay); err != nil {
+			return err
+		}
+	}
+	for _, path := range append([]string{spec.WorkDir}, spec.HidePaths...) {
+		if err := hidePath(path); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(spec.Dir, 0755); err != nil {
+		return err
+	}
+	if err := syscall.Mount("/proc/self/fd/"+strconv.Itoa(jobFD), spec.Dir, "", syscall.MS_BIND, ""); err != nil {
+		return fmt.Errorf("mount job directory: %w", err)
+	}
+	if err := syscall.Mount("", spec.Dir, "", syscall.MS_REMOUNT|syscall.MS_BIND|syscall.MS_NOSUID|syscall.MS_NODEV, ""); err != nil {
+		return fmt.Errorf("mount job directory: %w", err)
+	}
+	syscall.Close(jobFD)
+
+	// A /proc for the new PID namespace, where the worker can't be seen
+	if err := syscall.Mount("proc", "/proc", "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, ""); err != nil {
+		return fmt.Errorf("mount /proc: %w", err)
+	}
+	writable := spec.Dir
+	if spec.ReadOnly {
+		writable = ""
+	}
+	if err := remountReadOnly(writable); err != nil {
+		return err
+	}
+	if relay != nil {
+		if err := relay.start(spec.UID, spec.GID); err != nil {
+			return err
+		}
+	}
+
+	if err := setLimits(spec.Limits, spec.AddressSpace); err != nil {
+		return err
+	}
+	if err := setPriority(spec.Limits); err != nil {
+		return err
+	}
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0); errno != 0 {
+		return fmt.Errorf("set no_new_privs: %w", errno)
+	}
+	if err := syscall.Setgroups(nil); err != nil {
+		return fmt.Errorf("drop groups: %w", err)
+	}
+	if err := syscall.Setgid(spec.GID); err != nil {
+		return fmt.Errorf("set gid: %w", err)
+	}
+	if err := syscall.Setuid(spec.UID); err != nil {
+		return fmt.Errorf("set uid: %w", err)
+	}
+	if err := syscall.Chdir(spec.Dir); err != nil {
+		return err
+	}
+	return syscall.Exec(spec.Program, append([]string{filepath.Base(spec.Program)}, args[2:]...), os.Environ())
+}
+
+// chownDirs gives the sandbox user the job directory and those below it, so
+// ffmpeg can read inputs and create outputs; with ExecSpec.ReadOnly the mount
+// still keeps it from changing them. Files are left alone: inputs may be hard links
+// into the input cache, which ffmpeg mustn't be able to change.
+func chownDirs(root string, uid, gid int) error {
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		return os.Lchown(path, uid, gid)
+	})
+}
+
+// hidePath mounts an empty directory over a directory, or /dev/null over a file
+func hidePath(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		err = syscall.Mount("tmpfs", path, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, "size=1m,mode=755")
+	} else {
+		err = syscall.Mount("/dev/null", path, "", syscall.MS_BIND, "")
+	}
+	if err != nil {
+		return fmt.Errorf("hide %s: %w", path, err)
+	}
+	return nil
+}
+
+// mountFlags are the per-mount options kept when remounting read-only
+var mountFlags = map[string]uintptr{
+	"nosuid":      syscall.MS_NOSUID,
+	"nodev":       syscall.MS_NODEV,
+	"noexec":      syscall.MS_NOEXEC,
+	"noatime":     syscall.MS_NOATIME,
+	"nodiratime":  syscall.MS_NODIRATIME,
+	"relatime":    syscall.MS_RELATIME,
+	"strictatime": syscall.MS_STRICTATIME,
+}
+
+// remountReadOnly makes every mount read-only except the job directory, if
+// given, and /proc
+func remountReadOnly(jobDir string) error {
+	data, err := os.ReadFile("/proc/self/mountinfo")
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			continue
+		}
+		target := unescapeMountPath(fields[4])
+		if target == jobDir || target == "/proc" || strings.HasPrefix(target, "/proc/") {
+			continue
+		}
+		opts := strings.Split(fields[5], ",")
+		if slices.Contains(opts, "ro") {
+			continue
+		}
+		flags := uintptr(syscall.MS_REMOUNT | syscall.MS_BIND | syscall.MS_RDONLY)
+		for _, opt := range opts {
+			flags |= mountFlags[opt]
+		}
+		if err := syscall.Mount("", target, "", flags, ""); err != nil {
+			// Mounts covered by others, such as those under hidden paths, can't be reached anyway
+			if errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.EINVAL) {
+				continue
+			}
+			return fmt.Errorf("remount %s read-only: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// unescapeMountPath decodes the octal escapes (e.g. \040 for a space) in mountinfo paths
+func unescapeMountPath(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+type rlimit struct {
+	resource int
+	value    int64
+	name     string
+}
+
+func setLimits(limits Limits, addressSpace bool) error {
+	rlimits := []rlimit{
+		{syscall.RLIMIT_CPU, limits.CPUSeconds, "CPU time"},
+		{syscall.RLIMIT_FSIZE, limits.FileBytes, "file size"},
+		{syscall.RLIMIT_NOFILE, limits.OpenFiles, "open files"},
+	}
+	if addressSpace {
+		rlimits = append(rlimits, rlimit{syscall.RLIMIT_AS, limits.MemoryBytes, "address space"})
+	}
+	for _, r := range rlimits {
+		if r.value <= 0 {
+			continue
+		}
+		if err := syscall.Setrlimit(r.resource, &syscall.Rlimit{Cur: uint64(r.value), Max: uint64(r.value)}); err != nil {
+			return fmt.Errorf("limit %s: %w", r.name, err)
+		}
+	}
+	return nil
+}
+
+// setPriority lowers the CPU and I/O priority. Both are inherited by ffmpeg
+// and its threads.
+func setPriority(limits Limits) error {
+	if limits.Nice > 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, 0, limits.Nice); err != nil {
+			return fmt.Errorf("set nice: %w", err)
+		}
+	}
+	if limits.IOPriority > 0 {
+		prio := ioprioClassBE<<ioprioClassShift | min(limits.IOPriority, 7)
+		if _, _, errno := syscall.RawSyscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, 0, uintptr(prio)); errno != 0 {
+			return fmt.Errorf("set I/O priority: %w", errno)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package system
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain lets the test binary stand in for the worker binary, which the
+// sandbox and the plain executor's limits start as /proc/self/exe
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == SandboxInitArg {
+		SandboxInit(os.Args[2:])
+	}
+	os.Exit(m.Run())
+}
+
+func TestUnescapeMountPath(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"/data/jobs", "/data/jobs"},
+		{`/mnt/my\040disk`, "/mnt/my disk"},
+		{`/a\011b\012c\134d`, "/a\tb\nc\\d"},
+		{`/a\\b`, `/a\\b`},
+		{`/a\999`, `/a\999`},
+		{`/a\04`, `/a\04`},
+		{`/a\`, `/a\`},
+	}
+	for _, tt := range tests {
+		if got := unescapeMountPath(tt.in); got != tt.want {
+			t.Errorf("unescapeMountPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestSandbox runs a shell in place of ffmpeg and checks what it can reach
+func TestSandbox(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("the sandbox needs root")
+	}
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no shell")
+	}
+
+	// Sandbox users must be able to get to the job directory
+	workDir, err := os.MkdirTemp("", "sandbox-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(workDir)
+	os.Chmod(workDir, 0755)
+	jobDir := filepath.Join(workDir, "job")
+	otherDir := filepath.Join(workDir, "other")
+	for _, dir := range []string{jobDir, otherDir} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(otherDir, "in.mp4"), []byte("other job"), 0644)
+	// Outside the work directory, which is hidden as a whole
+	secretFile, err := os.CreateTemp("", "sandbox-secret-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(secretFile.Name())
+	secretFile.WriteString("secret")
+	secretFile.Chmod(0644)
+	secretFile.Close()
+	secret := secretFile.Name()
+	os.WriteFile(filepath.Join(jobDir, "in.mp4"), []byte("input"), 0644)
+
+	s := &SandboxExecutor{
+		opts:     SandboxOptions{UID: 65534, GID: 65534, WorkDir: workDir, HidePaths: []string{secret}},
+		programs: map[string]string{"ffmpeg": sh, "ffprobe": sh},
+	}
+	run := func(spec ExecSpec, script string) (string, error) {
+		spec.Dir = jobDir
+		spec.Args = []string{"-c", script}
+		cmd, release, err := s.Command(context.Background(), spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer release()
+		output, err := cmd.CombinedOutput()
+		return strings.TrimSpace(string(output)), err
+	}
+
+	if output, err := run(ExecSpec{}, "true"); err != nil {
+		t.Skipf("namespaces unavailable: %v: %s", err, output)
+	}
+
+	tests := []struct {
+		name   string
+		spec   ExecSpec
+		script string
+		want   string
+	}{
+		{"user", ExecSpec{}, "id -u; id -g", "65534\n65534"},
+		{"pid namespace", ExecSpec{}, "echo $$", "1"},
+		{"working directory", ExecSpec{}, "pwd", jobDir},
+		{"job directory writable", ExecSpec{}, "echo out > out.mp4 && cat out.mp4", "out"},
+		{"read-only run", ExecSpec{ReadOnly: true}, "cat in.mp4; echo; (echo x > ro.mp4) 2>/dev/null || echo denied", "input\ndenied"},
+		{"root read-only", ExecSpec{}, "touch /tmp/sandbox-escape 2>/dev/null || echo denied", "denied"},
+		{"other jobs hidden", ExecSpec{}, "ls " + workDir, "job"},
+		{"paths hidden", ExecSpec{}, "wc -c < " + secret, "0"},
+		{"loopback only", ExecSpec{}, "tail -n +3 /proc/net/dev | cut -d: -f1 | tr -d ' '", "lo"},
+		{"no new privileges", ExecSpec{}, "grep NoNewPrivs /proc/self/status | cut -f2", "1"},
+	}
+	for _, tt := range tests {
+		output, err := run(tt.spec, tt.script)
+		if err != nil || output != tt.want {
+			t.Errorf("%s: output %q, err %v; want %q", tt.name, output, err, tt.want)
+		}
+	}
+	if _, err := os.Stat("/tmp/sandbox-escape"); err == nil {
+		os.Remove("/tmp/sandbox-escape")
+	}
+}
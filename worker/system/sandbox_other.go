@@ -0,0 +1,31 @@
+//go:build !linux
+
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// NewSandboxExecutor fails: the sandbox needs Linux namespaces
+func NewSandboxExecutor(opts SandboxOptions) (Executor, error) {
+	return nil, errors.New("the sandbox executor is only available on Linux")
+}
+
+func (PlainExecutor) Unenforced(limits Limits) []string {
+	return limitNames(limits, false)
+}
+
+// limitedCommand runs the program without limits, which are only applied on Linux
+func limitedCommand(ctx context.Context, program string, args []string, limits Limits) (*exec.Cmd, error) {
+	return exec.CommandContext(ctx, program, args...), nil
+}
+
+// SandboxInit exits, as there is no sandbox to set up
+func SandboxInit(args []string) {
+	fmt.Fprintln(os.Stderr, "sandbox: not supported on this platform")
+	os.Exit(126)
+}